@@ -0,0 +1,57 @@
+// Package bus connects a node.Node to message streams.
+//
+// A Source provides messages containing TLEs, and a Sink receives
+// encoded reports.  Implementations exist for files, FIFOs, and TCP
+// line streams (see OpenSource and OpenSink).  Message-bus adapters
+// (NATS, Kafka, etc.) can implement Broker, which gives a Source and
+// a Sink for topics.  MemBroker is an in-memory Broker.
+//
+// A Pipe runs the data flow with at-least-once semantics: a message
+// is acknowledged only after the Node has processed its TLEs and all
+// of the resulting reports have been sent to (and flushed by) the
+// Sink.
+package bus
+
+import (
+	"context"
+)
+
+// Message is a unit of input from a Source.
+type Message struct {
+	// Data is the message payload: TLE text (three lines per TLE).
+	Data []byte
+
+	// Ack, if not nil, acknowledges that the message has been
+	// handled completely.  A Source that supports redelivery will
+	// redeliver messages that are not acknowledged.
+	Ack func() error
+}
+
+// Source provides Messages.
+type Source interface {
+	// Recv blocks until a Message is available.  At the end of
+	// the stream, Recv returns io.EOF.
+	Recv(ctx context.Context) (*Message, error)
+
+	Close() error
+}
+
+// Sink receives (encoded) output.
+type Sink interface {
+	// Send writes one unit of output, which might be buffered.
+	Send(ctx context.Context, data []byte) error
+
+	// Flush returns when all previous output has been delivered.
+	Flush(ctx context.Context) error
+
+	Close() error
+}
+
+// Broker is an abstraction of a message bus.
+type Broker interface {
+	// Subscribe returns a Source for the given topic.
+	Subscribe(ctx context.Context, topic string) (Source, error)
+
+	// Publish returns a Sink for the given topic.
+	Publish(ctx context.Context, topic string) (Sink, error)
+}
@@ -0,0 +1,194 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+)
+
+// testTLE returns the text for a TLE for one of a set of objects that
+// share an orbit but are separated by small differences in mean
+// anomaly.
+func testTLE(i int) string {
+	cat := 50000 + i
+	return fmt.Sprintf("TEST %d\n", i) +
+		fmt.Sprintf("1 %05dU 20001A   20016.08333333  .00000000  00000+0  00000+0 0    07\n", cat) +
+		fmt.Sprintf("2 %05d 064.8760 163.6520 0036285 284.0373 %8.4f 15.07452065    00\n", cat, 175.5769+0.05*float64(i))
+}
+
+func TestLineSource(t *testing.T) {
+	var (
+		ctx = context.Background()
+		in  = "a\nb\n\nc\r\nd\ne\n\n\n"
+		s   = NewLineSource(strings.NewReader(in), 3)
+	)
+
+	m, err := s.Recv(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Data) != "a\nb\nc\n" {
+		t.Fatalf("%q", m.Data)
+	}
+
+	if _, err = s.Recv(ctx); err != io.ErrUnexpectedEOF {
+		t.Fatal(err)
+	}
+
+	s = NewLineSource(strings.NewReader(""), 3)
+	if _, err = s.Recv(ctx); err != io.EOF {
+		t.Fatal(err)
+	}
+}
+
+func TestMemBroker(t *testing.T) {
+	var (
+		ctx = context.Background()
+		b   = NewMemBroker()
+	)
+
+	b.Put("t", []byte("1"))
+	b.Put("t", []byte("2"))
+	b.Put("t", []byte("3"))
+
+	s, err := b.Subscribe(ctx, "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m1, _ := s.Recv(ctx)
+	m2, _ := s.Recv(ctx)
+	if err := m2.Ack(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := b.Pending("t"); n != 2 {
+		t.Fatalf("pending %d", n)
+	}
+
+	// Unacknowledged m1 should be redelivered first.
+	s.Close()
+	if s, err = b.Subscribe(ctx, "t"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := s.Recv(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Data) != string(m1.Data) {
+		t.Fatalf("redelivered %s", m.Data)
+	}
+	m.Ack()
+
+	if m, _ = s.Recv(ctx); string(m.Data) != "3" {
+		t.Fatalf("got %s", m.Data)
+	}
+	m.Ack()
+
+	b.CloseTopic("t")
+	if _, err = s.Recv(ctx); err != io.EOF {
+		t.Fatal(err)
+	}
+	if n := b.Pending("t"); n != 0 {
+		t.Fatalf("pending %d", n)
+	}
+}
+
+func TestPipe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	b := NewMemBroker()
+	for i := 0; i < 3; i++ {
+		b.Put("tles", []byte(testTLE(i)))
+	}
+
+	src, err := b.Subscribe(ctx, "tles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink, err := b.Publish(ctx, "reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := node.NewNode(nil)
+	n.Horizon = 5
+	n.SlowSample = 0
+
+	p := NewPipe(n, src, sink)
+	p.BatchWait = 10 * time.Millisecond
+	p.Publisher = "test"
+
+	go n.Run(ctx)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- p.Run(ctx)
+	}()
+
+	for 0 < b.Pending("tles") {
+		select {
+		case <-ctx.Done():
+			t.Fatal("messages not acknowledged")
+		case err := <-errs:
+			t.Fatal(err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// The batch's reports were flushed before the messages were
+	// acknowledged.
+	out := b.Messages("reports")
+	if len(out) == 0 {
+		t.Fatal("no reports")
+	}
+
+	var r node.Report
+	if err := json.Unmarshal(out[0], &r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Objs) != 2 || !strings.HasSuffix(r.Objs[0].Name, "/test") {
+		t.Fatalf("bad report %#v", r)
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLESource(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		tle  = testTLE(0)
+		name = tle[:strings.Index(tle, "\n")+1]
+		s    = NewTLESource(NewLineSource(strings.NewReader(tle+tle[len(name):]+tle), 3))
+	)
+
+	m, err := s.Recv(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Data) != tle {
+		t.Fatalf("%q", m.Data)
+	}
+
+	// Two-line TLEs throw off the framing.
+	if _, err = s.Recv(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if err := CheckTLEs([]byte(tle + tle)); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckTLEs([]byte(tle[len(name):])); err == nil {
+		t.Fatal("expected an error")
+	}
+}
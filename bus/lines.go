@@ -0,0 +1,255 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// LineSource reads line-oriented input.  Each Message consists of a
+// fixed number of (non-blank) lines.
+//
+// LineSource Messages have no Ack.
+type LineSource struct {
+	r     io.Reader
+	br    *bufio.Reader
+	lines int
+}
+
+// NewLineSource makes a LineSource that emits Messages with the given
+// number of lines (three for TLE text).
+func NewLineSource(r io.Reader, lines int) *LineSource {
+	if lines < 1 {
+		lines = 1
+	}
+	return &LineSource{
+		r:     r,
+		br:    bufio.NewReader(r),
+		lines: lines,
+	}
+}
+
+func (s *LineSource) Recv(ctx context.Context) (*Message, error) {
+	var (
+		acc strings.Builder
+		n   = 0
+	)
+	for n < s.lines {
+		line, err := s.br.ReadString('\n')
+		if strings.TrimSpace(line) != "" {
+			acc.WriteString(strings.TrimRight(line, "\r\n"))
+			acc.WriteString("\n")
+			n++
+		}
+		if err == io.EOF {
+			if n == 0 {
+				return nil, io.EOF
+			}
+			if n < s.lines {
+				return nil, io.ErrUnexpectedEOF
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Message{
+		Data: []byte(acc.String()),
+	}, nil
+}
+
+// Close closes the underlying Reader if it's an io.Closer.
+func (s *LineSource) Close() error {
+	if c, is := s.r.(io.Closer); is {
+		return c.Close()
+	}
+	return nil
+}
+
+// FIFOSource is a LineSource for a named pipe that reopens the pipe
+// when all of its writers have closed it.  Therefore Recv never
+// returns io.EOF.
+type FIFOSource struct {
+	Filename string
+
+	lines int
+	s     *LineSource
+}
+
+func NewFIFOSource(filename string, lines int) *FIFOSource {
+	return &FIFOSource{
+		Filename: filename,
+		lines:    lines,
+	}
+}
+
+func (s *FIFOSource) Recv(ctx context.Context) (*Message, error) {
+	for {
+		if s.s == nil {
+			// Blocks until there's a writer.
+			f, err := os.Open(s.Filename)
+			if err != nil {
+				return nil, err
+			}
+			s.s = NewLineSource(f, s.lines)
+		}
+		m, err := s.s.Recv(ctx)
+		if err != io.EOF {
+			return m, err
+		}
+		s.s.Close()
+		s.s = nil
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+	}
+}
+
+func (s *FIFOSource) Close() error {
+	if s.s == nil {
+		return nil
+	}
+	return s.s.Close()
+}
+
+// TLESource checks that each Message from another Source is framed
+// as three-line TLEs: a name line followed by lines 1 and 2.  Recv
+// returns an error for a Message that isn't, which stops a Pipe
+// rather than letting it parse (say) two-line TLEs out of step.
+type TLESource struct {
+	Source
+}
+
+func NewTLESource(s Source) *TLESource {
+	return &TLESource{
+		Source: s,
+	}
+}
+
+func (s *TLESource) Recv(ctx context.Context) (*Message, error) {
+	m, err := s.Source.Recv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckTLEs(m.Data); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CheckTLEs returns an error if the (non-blank) lines of the data
+// aren't three-line TLEs.
+func CheckTLEs(data []byte) error {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines)%3 != 0 {
+		return fmt.Errorf("message has %d lines, which isn't a multiple of three (name, line 1, and line 2 for each TLE)", len(lines))
+	}
+	for i := 0; i < len(lines); i += 3 {
+		if strings.HasPrefix(lines[i], "1 ") || !strings.HasPrefix(lines[i+1], "1 ") || !strings.HasPrefix(lines[i+2], "2 ") {
+			return fmt.Errorf("message line %d (%q) doesn't start a three-line TLE", i+1, strings.TrimSpace(lines[i]))
+		}
+	}
+	return nil
+}
+
+// LineSink writes each Send as a line.
+type LineSink struct {
+	w  io.Writer
+	bw *bufio.Writer
+}
+
+func NewLineSink(w io.Writer) *LineSink {
+	return &LineSink{
+		w:  w,
+		bw: bufio.NewWriter(w),
+	}
+}
+
+func (s *LineSink) Send(ctx context.Context, data []byte) error {
+	if _, err := s.bw.Write(data); err != nil {
+		return err
+	}
+	return s.bw.WriteByte('\n')
+}
+
+func (s *LineSink) Flush(ctx context.Context) error {
+	return s.bw.Flush()
+}
+
+// Close flushes and then closes the underlying Writer if it's an
+// io.Closer.
+func (s *LineSink) Close() error {
+	if err := s.bw.Flush(); err != nil {
+		return err
+	}
+	if c, is := s.w.(io.Closer); is {
+		return c.Close()
+	}
+	return nil
+}
+
+// OpenSource opens a Source given a spec:
+//
+//	-               stdin
+//	file:FILENAME   a file (also just FILENAME)
+//	fifo:FILENAME   a named pipe (reopened as needed)
+//	tcp:HOST:PORT   a TCP connection
+//
+// Each Message has the given number of lines.
+func OpenSource(spec string, lines int) (Source, error) {
+	switch {
+	case spec == "-":
+		return NewLineSource(os.Stdin, lines), nil
+	case strings.HasPrefix(spec, "fifo:"):
+		return NewFIFOSource(strings.TrimPrefix(spec, "fifo:"), lines), nil
+	case strings.HasPrefix(spec, "tcp:"):
+		c, err := net.Dial("tcp", strings.TrimPrefix(spec, "tcp:"))
+		if err != nil {
+			return nil, err
+		}
+		return NewLineSource(c, lines), nil
+	}
+	f, err := os.Open(strings.TrimPrefix(spec, "file:"))
+	if err != nil {
+		return nil, err
+	}
+	return NewLineSource(f, lines), nil
+}
+
+// OpenSink opens a Sink given a spec:
+//
+//	-               stdout
+//	file:FILENAME   a file, which is appended (also just FILENAME)
+//	tcp:HOST:PORT   a TCP connection
+//
+// A FIFO can be opened as a file.
+func OpenSink(spec string) (Sink, error) {
+	switch {
+	case spec == "-":
+		return NewLineSink(os.Stdout), nil
+	case strings.HasPrefix(spec, "tcp:"):
+		c, err := net.Dial("tcp", strings.TrimPrefix(spec, "tcp:"))
+		if err != nil {
+			return nil, err
+		}
+		return NewLineSink(c), nil
+	}
+	filename := strings.TrimPrefix(spec, "file:")
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewLineSink(f), nil
+}
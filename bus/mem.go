@@ -0,0 +1,202 @@
+package bus
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// MemBroker is an in-memory Broker, which is mostly useful for
+// testing.
+//
+// Messages received from a Source and not acknowledged are redelivered
+// (to the next Recv on that topic) when that Source is closed.
+type MemBroker struct {
+	sync.Mutex
+
+	topics map[string]*memTopic
+}
+
+type memTopic struct {
+	queue [][]byte
+
+	// inflight counts delivered but unacknowledged messages.
+	inflight int
+
+	closed bool
+
+	// ready is closed (and replaced) when the topic changes.
+	ready chan bool
+}
+
+func NewMemBroker() *MemBroker {
+	return &MemBroker{
+		topics: make(map[string]*memTopic),
+	}
+}
+
+// topic gets or creates a topic.  Assumes the lock.
+func (b *MemBroker) topic(name string) *memTopic {
+	t, have := b.topics[name]
+	if !have {
+		t = &memTopic{
+			ready: make(chan bool),
+		}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// changed wakes up waiters.  Assumes the lock.
+func (t *memTopic) changed() {
+	close(t.ready)
+	t.ready = make(chan bool)
+}
+
+// Put adds a message to the topic.
+func (b *MemBroker) Put(topic string, data []byte) {
+	b.Lock()
+	t := b.topic(topic)
+	t.queue = append(t.queue, data)
+	t.changed()
+	b.Unlock()
+}
+
+// CloseTopic causes Recv to return io.EOF when the topic has no more
+// queued messages.
+func (b *MemBroker) CloseTopic(topic string) {
+	b.Lock()
+	t := b.topic(topic)
+	t.closed = true
+	t.changed()
+	b.Unlock()
+}
+
+// Pending returns the number of queued and unacknowledged messages for
+// the topic.
+func (b *MemBroker) Pending(topic string) int {
+	b.Lock()
+	defer b.Unlock()
+	t := b.topic(topic)
+	return len(t.queue) + t.inflight
+}
+
+// Messages returns a copy of the topic's queued messages.
+func (b *MemBroker) Messages(topic string) [][]byte {
+	b.Lock()
+	defer b.Unlock()
+	t := b.topic(topic)
+	acc := make([][]byte, len(t.queue))
+	copy(acc, t.queue)
+	return acc
+}
+
+func (b *MemBroker) Subscribe(ctx context.Context, topic string) (Source, error) {
+	return &memSource{
+		b:        b,
+		topic:    topic,
+		inflight: make(map[int][]byte),
+	}, nil
+}
+
+func (b *MemBroker) Publish(ctx context.Context, topic string) (Sink, error) {
+	return &memSink{
+		b:     b,
+		topic: topic,
+	}, nil
+}
+
+type memSource struct {
+	b     *MemBroker
+	topic string
+
+	// inflight are this Source's unacknowledged messages by
+	// delivery number.
+	inflight map[int][]byte
+	n        int
+}
+
+func (s *memSource) Recv(ctx context.Context) (*Message, error) {
+	b := s.b
+	for {
+		b.Lock()
+		t := b.topic(s.topic)
+		if 0 < len(t.queue) {
+			data := t.queue[0]
+			t.queue = t.queue[1:]
+			t.inflight++
+			s.n++
+			n := s.n
+			s.inflight[n] = data
+			b.Unlock()
+
+			ack := func() error {
+				b.Lock()
+				defer b.Unlock()
+				if _, have := s.inflight[n]; have {
+					delete(s.inflight, n)
+					b.topic(s.topic).inflight--
+				}
+				return nil
+			}
+
+			return &Message{
+				Data: data,
+				Ack:  ack,
+			}, nil
+		}
+		if t.closed {
+			b.Unlock()
+			return nil, io.EOF
+		}
+		ready := t.ready
+		b.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ready:
+		}
+	}
+}
+
+// Close requeues unacknowledged messages in their original order.
+func (s *memSource) Close() error {
+	b := s.b
+	b.Lock()
+	defer b.Unlock()
+
+	t := b.topic(s.topic)
+	requeue := make([][]byte, 0, len(s.inflight)+len(t.queue))
+	for i := 1; i <= s.n; i++ {
+		if data, have := s.inflight[i]; have {
+			requeue = append(requeue, data)
+		}
+	}
+	t.inflight -= len(s.inflight)
+	t.queue = append(requeue, t.queue...)
+	s.inflight = make(map[int][]byte)
+	t.changed()
+
+	return nil
+}
+
+type memSink struct {
+	b     *MemBroker
+	topic string
+}
+
+func (s *memSink) Send(ctx context.Context, data []byte) error {
+	bs := make([]byte, len(data))
+	copy(bs, data)
+	s.b.Put(s.topic, bs)
+	return nil
+}
+
+func (s *memSink) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (s *memSink) Close() error {
+	return nil
+}
@@ -0,0 +1,294 @@
+package bus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/tle"
+)
+
+// Pipe moves TLEs from a Source to a Node and reports from that Node
+// to a Sink.
+type Pipe struct {
+	Node   *node.Node
+	Source Source
+	Sink   Sink
+
+	// Publisher is the publisher for all in-coming TLEs.
+	Publisher string
+
+	// Parser parses TLEs.  The default is tle.NewSGP4TLE.
+	Parser func(line0, line1, line2 string) (prop.Propagator, error)
+
+	// BatchSize is the maximum number of TLEs in a batch given to
+	// the Node.
+	BatchSize int
+
+	// BatchWait is the maximum time a partial batch waits for
+	// more TLEs.
+	BatchWait time.Duration
+
+	// Encode renders a Report for the Sink.  The default is
+	// json.Marshal.  A Report rendered as nil isn't sent.
+	Encode func(*node.Report) ([]byte, error)
+
+	// Logging turns on logging, which uses log.Printf.
+	Logging bool
+}
+
+// NewPipe makes a Pipe, and it sets the Node's Out and Processed
+// channels.
+func NewPipe(n *node.Node, src Source, sink Sink) *Pipe {
+	n.Out = make(chan []*node.Report, 32)
+	n.Processed = make(chan []*node.PubTLE)
+	return &Pipe{
+		Node:      n,
+		Source:    src,
+		Sink:      sink,
+		BatchSize: 128,
+		BatchWait: time.Second,
+	}
+}
+
+// pending is a batch given to the Node along with the Messages that
+// will be acknowledged when the Node has processed it.
+type pending struct {
+	sats []*node.PubTLE
+	msgs []*Message
+}
+
+func (p *Pipe) logf(format string, args ...interface{}) {
+	if p.Logging {
+		log.Printf(format, args...)
+	}
+}
+
+// Run runs the Pipe until the context is done or an error occurs.
+//
+// The Node should be running already (or soon).  When the Source is
+// exhausted, Run continues to write the Node's reports.
+func (p *Pipe) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		msgs     = make(chan *Message)
+		pendings = make(chan *pending, 1024)
+		errs     = make(chan error, 2)
+	)
+
+	go func() {
+		for {
+			m, err := p.Source.Recv(ctx)
+			if err == io.EOF {
+				close(msgs)
+				return
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case msgs <- m:
+			}
+		}
+	}()
+
+	go func() {
+		if err := p.write(ctx, pendings); err != nil {
+			errs <- err
+		}
+	}()
+
+	var (
+		batch = &pending{}
+		timer = time.NewTimer(p.BatchWait)
+	)
+
+	submit := func() error {
+		if len(batch.msgs) == 0 {
+			return nil
+		}
+		b := batch
+		batch = &pending{}
+		if len(b.sats) == 0 {
+			// Nothing for the Node to do.
+			return ack(b.msgs)
+		}
+		p.logf("Pipe submitting %d TLEs from %d messages", len(b.sats), len(b.msgs))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case pendings <- b:
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case p.Node.In <- b.sats:
+		}
+		return nil
+	}
+
+	// failed returns the error (if any) that should terminate Run.
+	failed := func(err error) error {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case <-timer.C:
+			if err := submit(); err != nil {
+				return failed(err)
+			}
+			timer.Reset(p.BatchWait)
+		case m, more := <-msgs:
+			if !more {
+				if err := submit(); err != nil {
+					return failed(err)
+				}
+				msgs = nil // Keep writing.
+				continue
+			}
+			batch.msgs = append(batch.msgs, m)
+			batch.sats = append(batch.sats, p.parse(m)...)
+			if p.BatchSize <= len(batch.sats) {
+				if err := submit(); err != nil {
+					return failed(err)
+				}
+			}
+		}
+	}
+}
+
+// parse returns the TLEs in a Message.  Problems are logged, and
+// problematic TLEs are skipped.
+func (p *Pipe) parse(m *Message) []*node.PubTLE {
+	var (
+		acc    []*node.PubTLE
+		parser = p.Parser
+	)
+	if parser == nil {
+		parser = tle.NewSGP4TLE
+	}
+
+	f := func(i int, line0 string, o prop.Propagator) error {
+		if err := tle.Check(o); err != nil {
+			log.Printf("sat error at %d %s: %s", i, strings.TrimSpace(line0), err)
+			return nil
+		}
+		acc = append(acc, &node.PubTLE{
+			Publisher: p.Publisher,
+			TLE:       o.(*tle.SGP4TLE),
+		})
+		return nil
+	}
+
+	if err := tle.DoTLEs(bufio.NewReader(bytes.NewReader(m.Data)), parser, f); err != nil {
+		log.Printf("message parse error: %s", err)
+	}
+
+	return acc
+}
+
+// write sends reports to the Sink, and it acknowledges Messages when
+// their batches have been processed.
+func (p *Pipe) write(ctx context.Context, pendings chan *pending) error {
+	var (
+		n      = p.Node
+		encode = p.Encode
+	)
+	if encode == nil {
+		encode = func(r *node.Report) ([]byte, error) {
+			return json.Marshal(r)
+		}
+	}
+
+	send := func(rs []*node.Report) error {
+		for _, r := range rs {
+			bs, err := encode(r)
+			if err != nil {
+				return err
+			}
+			if bs == nil {
+				continue
+			}
+			if err = p.Sink.Send(ctx, bs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case rs := <-n.Out:
+			if err := send(rs); err != nil {
+				return err
+			}
+		case sats := <-n.Processed:
+			// All of this batch's reports are already in
+			// Out.
+		DRAIN:
+			for {
+				select {
+				case rs := <-n.Out:
+					if err := send(rs); err != nil {
+						return err
+					}
+				default:
+					break DRAIN
+				}
+			}
+			if err := p.Sink.Flush(ctx); err != nil {
+				return err
+			}
+			var b *pending
+			select {
+			case <-ctx.Done():
+				return nil
+			case b = <-pendings:
+			}
+			if len(sats) != len(b.sats) || (0 < len(sats) && sats[0] != b.sats[0]) {
+				return fmt.Errorf("processed batch (%d) isn't the pending batch (%d)",
+					len(sats), len(b.sats))
+			}
+			p.logf("Pipe acknowledging %d messages", len(b.msgs))
+			if err := ack(b.msgs); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ack acknowledges the Messages.
+func ack(msgs []*Message) error {
+	for _, m := range msgs {
+		if m.Ack == nil {
+			continue
+		}
+		if err := m.Ack(); err != nil {
+			return fmt.Errorf("ack: %w", err)
+		}
+	}
+	return nil
+}
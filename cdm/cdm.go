@@ -0,0 +1,401 @@
+// Package cdm converts node.Reports to CCSDS Conjunction Data
+// Messages (CCSDS 508.0-B-1) in KVN or XML.
+//
+// These CDMs are screening-grade: they come from SGP4 propagation of
+// TLEs, the state vectors are in TEME, and covariances are either
+// empty or come from a crude CovarianceModel.  Every CDM says so in
+// its comments.
+package cdm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/prop"
+)
+
+// Version is the CCSDS_CDM_VERS.
+const Version = "1.0"
+
+// Disclaimer is a COMMENT in every CDM's header.
+const Disclaimer = "SCREENING-GRADE: derived from SGP4 propagation of TLEs; not for operational use"
+
+// ErrNotConjunction is returned for a Report that isn't a (current)
+// close approach, such as a cancellation or a consistency report.
+var ErrNotConjunction = errors.New("report is not a conjunction")
+
+// CDM is a Conjunction Data Message.
+type CDM struct {
+	Comments     []string
+	CreationDate time.Time
+	Originator   string
+	MessageID    string
+
+	// TCA is the time of closest approach.
+	TCA time.Time
+
+	// MissDistance is in m.
+	MissDistance float64
+
+	// RelativeSpeed is in m/s.
+	RelativeSpeed float64
+
+	// RelativePosition (m) and RelativeVelocity (m/s) are
+	// OBJECT2 relative to OBJECT1 in OBJECT1's RTN frame.
+	RelativePosition, RelativeVelocity [3]float64
+
+	Objects [2]Object
+}
+
+// Object is an OBJECT1 or OBJECT2 segment.
+type Object struct {
+	Comments []string
+
+	// Designator is the catalog number.
+	Designator              string
+	CatalogName             string
+	Name                    string
+	InternationalDesignator string
+
+	// Type is PAYLOAD, ROCKET BODY, DEBRIS, or UNKNOWN.
+	Type string
+
+	RefFrame string
+
+	// Position (km) and Velocity (km/s) are in RefFrame.
+	Position, Velocity [3]float64
+
+	// Covariance is nil if unknown.
+	Covariance *Covariance
+}
+
+// Options configure FromReport.
+type Options struct {
+	// Originator is the ORIGINATOR, which defaults to "SPI".
+	Originator string
+
+	// Covariance, if not nil, provides covariances.
+	Covariance CovarianceModel
+}
+
+// objectTypes maps tle.GetType values to OBJECT_TYPEs.
+var objectTypes = map[string]string{
+	"payload": "PAYLOAD",
+	"rocket":  "ROCKET BODY",
+	"debris":  "DEBRIS",
+}
+
+// FromReport makes a CDM for the conjunction Report.
+//
+// The MESSAGE_ID is the Report's Id.
+func FromReport(r *node.Report, opts *Options) (*CDM, error) {
+	if r.Kind != "" || r.Canceled {
+		return nil, ErrNotConjunction
+	}
+	if len(r.Objs) != 2 {
+		return nil, fmt.Errorf("report %s has %d objects", r.Id, len(r.Objs))
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	originator := opts.Originator
+	if originator == "" {
+		originator = "SPI"
+	}
+
+	var (
+		e0  = ephemeris(&r.Objs[0])
+		e1  = ephemeris(&r.Objs[1])
+		pos = node.RIC(e0, e1)
+		vel = node.RICVelocity(e0, e1)
+	)
+
+	c := &CDM{
+		Comments:         []string{Disclaimer},
+		CreationDate:     r.Generated,
+		Originator:       originator,
+		MessageID:        r.Id,
+		TCA:              r.At,
+		MissDistance:     1000 * float64(r.Dist),
+		RelativeSpeed:    1000 * float64(r.Speed),
+		RelativePosition: meters(pos),
+		RelativeVelocity: meters(vel),
+	}
+
+	for i := range r.Objs {
+		s := &r.Objs[i]
+		o := Object{
+			Comments: []string{
+				fmt.Sprintf("SPI name %s", s.Name),
+				"State from SGP4 (TEME)",
+			},
+			Designator:              s.CatNum(),
+			CatalogName:             "SATCAT",
+			Name:                    objectName(s),
+			InternationalDesignator: "UNKNOWN",
+			Type:                    "UNKNOWN",
+			RefFrame:                "TEME",
+			Position:                float64s(s.ECI),
+			Velocity:                float64s(s.Vel),
+		}
+		if s.Obj != nil {
+			o.InternationalDesignator = intlDesignator(s.Obj.TLE[1])
+			if s.Publisher != "" {
+				o.Comments = append(o.Comments, "TLE publisher "+s.Publisher)
+			}
+		}
+		if t, have := objectTypes[s.Type]; have {
+			o.Type = t
+		}
+		if opts.Covariance != nil {
+			o.Covariance = opts.Covariance.Covariance(s)
+		}
+		if o.Covariance == nil {
+			o.Comments = append(o.Comments, "Covariance unknown")
+		} else {
+			o.Comments = append(o.Comments, "Covariance from a default model")
+		}
+		c.Objects[i] = o
+	}
+
+	return c, nil
+}
+
+func ephemeris(s *node.State) prop.Ephemeris {
+	return prop.Ephemeris{
+		ECI: s.ECI,
+		V:   s.Vel,
+	}
+}
+
+func float64s(v prop.Vect) [3]float64 {
+	return [3]float64{float64(v.X), float64(v.Y), float64(v.Z)}
+}
+
+func meters(v prop.Vect) [3]float64 {
+	xs := float64s(v)
+	for i := range xs {
+		xs[i] *= 1000
+	}
+	return xs
+}
+
+// objectName returns the TLE's name (from line 0) or the State's
+// name.
+func objectName(s *node.State) string {
+	if s.Obj != nil {
+		name := strings.TrimSpace(s.Obj.TLE[0])
+		name = strings.TrimSpace(strings.TrimPrefix(name, "0 "))
+		if name != "" {
+			return name
+		}
+	}
+	return s.Name
+}
+
+// intlDesignator converts the international designator in a TLE's
+// line 1 ("98067A") to the CDM form ("1998-067A").
+func intlDesignator(line1 string) string {
+	if len(line1) < 17 {
+		return "UNKNOWN"
+	}
+	d := strings.TrimSpace(line1[9:17])
+	if len(d) < 6 {
+		return "UNKNOWN"
+	}
+	y, err := strconv.Atoi(d[:2])
+	if err != nil {
+		return "UNKNOWN"
+	}
+	if y < 57 {
+		y += 2000
+	} else {
+		y += 1900
+	}
+	return fmt.Sprintf("%d-%s", y, d[2:])
+}
+
+// Covariance is the lower triangle of the 6x6 RTN covariance in CDM
+// order (CR_R, CT_R, CT_T, CN_R, ..., CNDOT_NDOT) with units m**2,
+// m**2/s, and m**2/s**2.
+type Covariance [21]float64
+
+// CovarianceModel provides a covariance for an object's state.
+type CovarianceModel interface {
+	// Covariance returns nil if the covariance is unknown.
+	Covariance(s *node.State) *Covariance
+}
+
+// Sigmas are uncorrelated standard deviations of the RTN position (m)
+// and velocity (m/s).
+type Sigmas struct {
+	R, T, N          float64
+	RDot, TDot, NDot float64
+}
+
+// Covariance returns the diagonal covariance.
+func (s Sigmas) Covariance(*node.State) *Covariance {
+	var c Covariance
+	for i, x := range []float64{s.R, s.T, s.N, s.RDot, s.TDot, s.NDot} {
+		// Index of the i-th diagonal element in the lower
+		// triangle.
+		c[i*(i+3)/2] = x * x
+	}
+	return &c
+}
+
+// TypeSigmas gives Sigmas by object type (see tle.GetType).  The
+// empty type is the default.
+type TypeSigmas map[string]Sigmas
+
+// Covariance uses the Sigmas for the State's type.
+func (ts TypeSigmas) Covariance(s *node.State) *Covariance {
+	x, have := ts[s.Type]
+	if !have {
+		if x, have = ts[""]; !have {
+			return nil
+		}
+	}
+	return x.Covariance(s)
+}
+
+// covarianceKeys are the KVN keys for a Covariance.
+var covarianceKeys = [21]string{
+	"CR_R",
+	"CT_R", "CT_T",
+	"CN_R", "CN_T", "CN_N",
+	"CRDOT_R", "CRDOT_T", "CRDOT_N", "CRDOT_RDOT",
+	"CTDOT_R", "CTDOT_T", "CTDOT_N", "CTDOT_RDOT", "CTDOT_TDOT",
+	"CNDOT_R", "CNDOT_T", "CNDOT_N", "CNDOT_RDOT", "CNDOT_TDOT", "CNDOT_NDOT",
+}
+
+// covarianceUnits gives the units for the element at the given row
+// and column.
+func covarianceUnits(row, col int) string {
+	switch {
+	case row < 3:
+		return "m**2"
+	case col < 3:
+		return "m**2/s"
+	default:
+		return "m**2/s**2"
+	}
+}
+
+// kv is a keyword and value with optional units.
+type kv struct {
+	key, value, units string
+}
+
+// section is a group of keywords, which KVN flattens and XML nests.
+type section struct {
+	tag  string
+	kvs  []kv
+	subs []*section
+}
+
+func comments(cs []string) []kv {
+	acc := make([]kv, len(cs))
+	for i, c := range cs {
+		acc[i] = kv{key: "COMMENT", value: c}
+	}
+	return acc
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000")
+}
+
+func formatFloat(x float64) string {
+	if x == 0 {
+		return "0.0"
+	}
+	if math.Abs(x) < 1e-3 || 1e9 <= math.Abs(x) {
+		return strconv.FormatFloat(x, 'E', 6, 64)
+	}
+	return strconv.FormatFloat(x, 'f', 6, 64)
+}
+
+// sections returns the header and the body sections.
+func (c *CDM) sections() (*section, []*section) {
+	header := &section{
+		tag: "header",
+		kvs: append(comments(c.Comments),
+			kv{key: "CREATION_DATE", value: formatTime(c.CreationDate)},
+			kv{key: "ORIGINATOR", value: c.Originator},
+			kv{key: "MESSAGE_ID", value: c.MessageID}),
+	}
+
+	rtn := [3]string{"R", "T", "N"}
+	relative := &section{
+		tag: "relativeMetadataData",
+		kvs: []kv{
+			{key: "TCA", value: formatTime(c.TCA)},
+			{key: "MISS_DISTANCE", value: formatFloat(c.MissDistance), units: "m"},
+			{key: "RELATIVE_SPEED", value: formatFloat(c.RelativeSpeed), units: "m/s"},
+		},
+	}
+	rsv := &section{tag: "relativeStateVector"}
+	for i, x := range c.RelativePosition {
+		rsv.kvs = append(rsv.kvs, kv{key: "RELATIVE_POSITION_" + rtn[i], value: formatFloat(x), units: "m"})
+	}
+	for i, x := range c.RelativeVelocity {
+		rsv.kvs = append(rsv.kvs, kv{key: "RELATIVE_VELOCITY_" + rtn[i], value: formatFloat(x), units: "m/s"})
+	}
+	relative.subs = []*section{rsv}
+
+	body := []*section{relative}
+
+	xyz := [3]string{"X", "Y", "Z"}
+	for i := range c.Objects {
+		o := &c.Objects[i]
+		metadata := &section{
+			tag: "metadata",
+			kvs: append(comments(o.Comments),
+				kv{key: "OBJECT", value: fmt.Sprintf("OBJECT%d", i+1)},
+				kv{key: "OBJECT_DESIGNATOR", value: o.Designator},
+				kv{key: "CATALOG_NAME", value: o.CatalogName},
+				kv{key: "OBJECT_NAME", value: o.Name},
+				kv{key: "INTERNATIONAL_DESIGNATOR", value: o.InternationalDesignator},
+				kv{key: "OBJECT_TYPE", value: o.Type},
+				kv{key: "EPHEMERIS_NAME", value: "NONE"},
+				kv{key: "COVARIANCE_METHOD", value: "DEFAULT"},
+				kv{key: "MANEUVERABLE", value: "N/A"},
+				kv{key: "REF_FRAME", value: o.RefFrame}),
+		}
+
+		sv := &section{tag: "stateVector"}
+		for k, x := range o.Position {
+			sv.kvs = append(sv.kvs, kv{key: xyz[k], value: formatFloat(x), units: "km"})
+		}
+		for k, x := range o.Velocity {
+			sv.kvs = append(sv.kvs, kv{key: xyz[k] + "_DOT", value: formatFloat(x), units: "km/s"})
+		}
+
+		cov := &section{tag: "covarianceMatrix"}
+		for k, key := range covarianceKeys {
+			row := int((math.Sqrt(float64(8*k+1)) - 1) / 2)
+			col := k - row*(row+1)/2
+			x := kv{key: key, units: covarianceUnits(row, col)}
+			if o.Covariance != nil {
+				x.value = formatFloat(o.Covariance[k])
+			}
+			cov.kvs = append(cov.kvs, x)
+		}
+
+		body = append(body, &section{
+			tag: "segment",
+			subs: []*section{
+				metadata,
+				{tag: "data", subs: []*section{sv, cov}},
+			},
+		})
+	}
+
+	return header, body
+}
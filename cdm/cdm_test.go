@@ -0,0 +1,229 @@
+package cdm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/tle"
+)
+
+// testReport makes a Report for the ISS and the ISS one second later,
+// which is about 7.7 km ahead in-track.
+func testReport(t *testing.T) *node.Report {
+	p, err := tle.NewSGP4TLE("0 ISS (ZARYA)",
+		"1 25544U 98067A   20264.51782528 -.00002182  00000-0 -11606-4 0  2927",
+		"2 25544  51.6416 247.4627 0006703 130.5360 325.0288 15.72125391563537")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := p.(*tle.SGP4TLE)
+
+	at := time.Date(2020, 9, 20, 13, 0, 0, 0, time.UTC)
+	e0, err := o.Prop(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1, err := o.Prop(at.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	norm := func(x, y, z float32) float32 {
+		return float32(math.Sqrt(float64(x*x + y*y + z*z)))
+	}
+
+	return &node.Report{
+		Schema:    node.ReportSchema,
+		Id:        "id",
+		Sig:       "sig",
+		Generated: at.Add(-time.Minute),
+		At:        at,
+		Dist:      norm(e1.ECI.X-e0.ECI.X, e1.ECI.Y-e0.ECI.Y, e1.ECI.Z-e0.ECI.Z),
+		Speed:     norm(e1.V.X-e0.V.X, e1.V.Y-e0.V.Y, e1.V.Z-e0.V.Z),
+		Objs: []node.State{
+			{
+				Name:      "25544/a",
+				Obj:       o,
+				Publisher: "a",
+				Type:      "payload",
+				ECI:       e0.ECI,
+				Vel:       e0.V,
+			},
+			{
+				Name:   "25544/b",
+				Digest: "digest",
+				Type:   "debris",
+				ECI:    e1.ECI,
+				Vel:    e1.V,
+			},
+		},
+	}
+}
+
+func TestFromReport(t *testing.T) {
+	c, err := FromReport(testReport(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		p = c.RelativePosition
+		d = math.Sqrt(p[0]*p[0] + p[1]*p[1] + p[2]*p[2])
+	)
+	if 1 < math.Abs(d-c.MissDistance) {
+		t.Fatalf("relative position %v vs miss distance %f", p, c.MissDistance)
+	}
+	if p[1] < 7000 || 100 < math.Abs(p[0]) || 100 < math.Abs(p[2]) {
+		t.Fatalf("relative position %v isn't in-track", p)
+	}
+
+	o0, o1 := &c.Objects[0], &c.Objects[1]
+	if o0.Designator != "25544" || o0.Name != "ISS (ZARYA)" || o0.InternationalDesignator != "1998-067A" || o0.Type != "PAYLOAD" {
+		t.Fatalf("object 1 %#v", o0)
+	}
+	if o1.Designator != "25544" || o1.Name != "25544/b" || o1.InternationalDesignator != "UNKNOWN" || o1.Type != "DEBRIS" {
+		t.Fatalf("object 2 %#v", o1)
+	}
+	if o0.Covariance != nil || o1.Covariance != nil {
+		t.Fatal("unexpected covariance")
+	}
+}
+
+func TestNotConjunction(t *testing.T) {
+	r := testReport(t)
+	r.Canceled = true
+	if _, err := FromReport(r, nil); err != ErrNotConjunction {
+		t.Fatal(err)
+	}
+
+	r = testReport(t)
+	r.Kind = node.ConsistencyReport
+	if _, err := FromReport(r, nil); err != ErrNotConjunction {
+		t.Fatal(err)
+	}
+}
+
+func TestKVN(t *testing.T) {
+	opts := &Options{
+		Originator: "TEST",
+		Covariance: TypeSigmas{
+			"payload": {R: 100, T: 1000, N: 10, RDot: 1, TDot: 0.1, NDot: 0.01},
+		},
+	}
+	c, err := FromReport(testReport(t), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Objects[1].Covariance != nil {
+		t.Fatal("debris has a covariance")
+	}
+
+	var buf bytes.Buffer
+	if err := c.WriteKVN(&buf); err != nil {
+		t.Fatal(err)
+	}
+	kvn := buf.String()
+
+	lines := strings.Split(kvn, "\n")
+	if !strings.HasPrefix(lines[0], "CCSDS_CDM_VERS") {
+		t.Fatalf("first line %q", lines[0])
+	}
+
+	values := make(map[string][]string)
+	for _, line := range lines {
+		if strings.HasPrefix(line, "COMMENT") || line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			t.Fatalf("bad line %q", line)
+		}
+		key := strings.TrimSpace(parts[0])
+		values[key] = append(values[key], strings.Join(strings.Fields(parts[1]), " "))
+	}
+
+	for key, want := range map[string]string{
+		"ORIGINATOR": "TEST",
+		"MESSAGE_ID": "id",
+		"TCA":        "2020-09-20T13:00:00.000",
+		"CR_R":       "10000.000000 [m**2]",
+		"CT_R":       "0.0 [m**2]",
+		"CNDOT_NDOT": "1.000000E-04 [m**2/s**2]",
+	} {
+		if got := values[key]; len(got) == 0 || got[0] != want {
+			t.Fatalf("%s: %v != %s", key, got, want)
+		}
+	}
+	if got := values["CR_R"]; len(got) != 2 || got[1] != "" {
+		t.Fatalf("OBJECT2 CR_R %v", got)
+	}
+	if !strings.Contains(kvn, "COMMENT "+Disclaimer) {
+		t.Fatal("no disclaimer")
+	}
+}
+
+func TestXML(t *testing.T) {
+	c, err := FromReport(testReport(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.WriteXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		d        = xml.NewDecoder(&buf)
+		path     []string
+		segments int
+		miss     string
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch x := tok.(type) {
+		case xml.StartElement:
+			path = append(path, x.Name.Local)
+			if x.Name.Local == "segment" {
+				segments++
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		case xml.CharData:
+			if 0 < len(path) && path[len(path)-1] == "MISS_DISTANCE" {
+				miss = string(x)
+			}
+		}
+	}
+
+	if segments != 2 {
+		t.Fatalf("%d segments", segments)
+	}
+	if miss != formatFloat(c.MissDistance) {
+		t.Fatalf("MISS_DISTANCE %q", miss)
+	}
+}
+
+func TestIntlDesignator(t *testing.T) {
+	for line1, want := range map[string]string{
+		"1 25544U 98067A   20264.51782528 -.00002182  00000-0 -11606-4 0  2927": "1998-067A",
+		"1 45657U 20038AX  20264.51782528 -.00002182  00000-0 -11606-4 0  2927": "2020-038AX",
+		"1 45657U          20264.51782528 -.00002182  00000-0 -11606-4 0  2927": "UNKNOWN",
+	} {
+		if got := intlDesignator(line1); got != want {
+			t.Fatalf("%s != %s", got, want)
+		}
+	}
+}
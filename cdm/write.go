@@ -0,0 +1,85 @@
+package cdm
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteKVN writes the CDM in KVN.
+//
+// Unknown covariance elements have empty values.
+func (c *CDM) WriteKVN(w io.Writer) error {
+	var (
+		bw           = bufio.NewWriter(w)
+		header, body = c.sections()
+		line         = func(x kv) {
+			if x.key == "COMMENT" {
+				fmt.Fprintf(bw, "COMMENT %s\n", x.value)
+				return
+			}
+			if x.units == "" || x.value == "" {
+				fmt.Fprintf(bw, "%-32s = %s\n", x.key, x.value)
+				return
+			}
+			fmt.Fprintf(bw, "%-32s = %-24s [%s]\n", x.key, x.value, x.units)
+		}
+		walk func(s *section)
+	)
+	walk = func(s *section) {
+		for _, x := range s.kvs {
+			line(x)
+		}
+		for _, sub := range s.subs {
+			walk(sub)
+		}
+	}
+
+	line(kv{key: "CCSDS_CDM_VERS", value: Version})
+	walk(header)
+	for _, s := range body {
+		walk(s)
+	}
+
+	return bw.Flush()
+}
+
+// WriteXML writes the CDM in XML.
+//
+// Unknown covariance elements are empty.
+func (c *CDM) WriteXML(w io.Writer) error {
+	var (
+		bw           = bufio.NewWriter(w)
+		header, body = c.sections()
+		escape       = func(s string) string {
+			var b strings.Builder
+			xml.EscapeText(&b, []byte(s))
+			return b.String()
+		}
+		walk func(s *section, indent string)
+	)
+	walk = func(s *section, indent string) {
+		fmt.Fprintf(bw, "%s<%s>\n", indent, s.tag)
+		for _, x := range s.kvs {
+			units := ""
+			if x.units != "" {
+				units = fmt.Sprintf(` units="%s"`, escape(x.units))
+			}
+			fmt.Fprintf(bw, "%s  <%s%s>%s</%s>\n", indent, x.key, units, escape(x.value), x.key)
+		}
+		for _, sub := range s.subs {
+			walk(sub, indent+"  ")
+		}
+		fmt.Fprintf(bw, "%s</%s>\n", indent, s.tag)
+	}
+
+	fmt.Fprintf(bw, "%s", xml.Header)
+	fmt.Fprintf(bw, "<cdm id=\"CCSDS_CDM_VERS\" version=\"%s\">\n", Version)
+	walk(header, "  ")
+	walk(&section{tag: "body", subs: body}, "  ")
+	fmt.Fprintf(bw, "</cdm>\n")
+
+	return bw.Flush()
+}
@@ -6,7 +6,6 @@ import (
 	"context"
 	"encoding/json"
 	"flag"
-	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
@@ -17,6 +16,8 @@ import (
 	"github.com/ut-astria/spi/index"
 	"github.com/ut-astria/spi/node"
 	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/schema"
+	"github.com/ut-astria/spi/sgp4"
 	"github.com/ut-astria/spi/tle"
 )
 
@@ -46,14 +47,19 @@ func main() {
 			"Threshold for index search distance")
 
 		slowSampleThreshold = flag.Float64("slow-sample-threshold", float64(n.SlowSampleThreshold),
-			"Maximum relative speed (m/s) to trigger slow approach sampling")
+			"Maximum relative speed (km/s) to trigger slow approach sampling")
 
 		numWorkers = flag.Int("workers", runtime.NumCPU(), "Nummber of workers")
 
+		gravity = flag.String("gravity", "", "SGP4 gravity model (wgs72old, wgs72, or wgs84)")
+		opsMode = flag.String("opsmode", "", "SGP4 operation mode (afspc or improved)")
+
 		cfg = flag.String("cfg", "", "Filename for JSON configuration; overrides any other args")
 		// ToDo: Command-line args override cfg file.
 
 		ts = flag.String("t0", "now", "Logical starting time (example: \"2020-09-18T17:31:16Z\")")
+
+		encoding = flag.String("encoding", schema.JSON, "Report encoding (json, protobuf, or cbor)")
 	)
 
 	flag.Parse()
@@ -68,10 +74,23 @@ func main() {
 	}
 	log.Printf("t0: %s", *ts)
 
+	enc, err := schema.NewEncoder(os.Stdout, *encoding)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	n.ScanDist = float32(*scanDist)
 	n.IndexDist = float32(*indexDist)
 	n.SlowSampleThreshold = float32(*slowSampleThreshold)
 
+	if *gravity != "" || *opsMode != "" {
+		opts, err := sgp4Options(*gravity, *opsMode)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n.SGP4 = opts
+	}
+
 	if *cfg != "" {
 		js, err := ioutil.ReadFile(*cfg)
 		if err != nil {
@@ -108,7 +127,12 @@ func main() {
 		return nil
 	}
 
-	if err := tle.DoTLEs(r, tle.NewSGP4TLE, f); err != nil {
+	parser := tle.NewSGP4TLE
+	if n.SGP4 != nil {
+		parser = tle.NewSGP4TLEWith(*n.SGP4)
+	}
+
+	if err := tle.DoTLEs(r, parser, f); err != nil {
 		log.Fatal(err)
 	}
 
@@ -117,7 +141,9 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	n.Prepare(ctx)
+	if err := n.Prepare(ctx); err != nil {
+		log.Fatal(err)
+	}
 
 	iis := make([]*node.IndexInput, 0, len(sats))
 	for _, sat := range sats {
@@ -128,7 +154,16 @@ func main() {
 		iis = append(iis, ii)
 	}
 
+	ps := make([]prop.Propagator, len(iis))
+	for k, ii := range iis {
+		ps[k] = ii.Sat.TLE
+	}
+
 	var (
+		batch = &tle.Batch{
+			Workers: 1,
+		}
+
 		tick  = n.Resolution
 		ticks = int(float64(n.Horizon) / float64(n.Resolution/time.Second))
 		t     = t0
@@ -150,9 +185,14 @@ func main() {
 
 			i := n.NewIndex(t)
 			ios := make([]*node.IndexOutput, 0, len(iis))
-			for _, ii := range iis {
 
-				e, err := ii.Sat.TLE.Prop(t)
+			// Each worker handles a single tick, so we
+			// propagate in this goroutine.
+			m := batch.PropBatch(ps, []time.Time{t})
+
+			for k, ii := range iis {
+
+				e, err := m.At(k, 0)
 				if err != nil {
 					log.Printf("sat.Prop %s", err)
 					continue
@@ -208,7 +248,9 @@ func main() {
 
 			}
 			for _, r := range rs {
-				fmt.Printf("%s\n", JSON(r, false))
+				if err := enc.Encode(r); err != nil {
+					log.Fatal(err)
+				}
 			}
 
 			select {
@@ -228,16 +270,23 @@ func main() {
 	}
 }
 
-func JSON(x interface{}, pretty bool) string {
-	var js []byte
-	var err error
-	if pretty {
-		js, err = json.MarshalIndent(&x, "", "  ")
-	} else {
-		js, err = json.Marshal(&x)
+// sgp4Options makes SGP4 options based on sgp4.DefaultOptions and
+// the given (optional) names.
+func sgp4Options(gravity, opsMode string) (*sgp4.Options, error) {
+	opts := sgp4.DefaultOptions
+	if gravity != "" {
+		g, err := sgp4.ParseGravity(gravity)
+		if err != nil {
+			return nil, err
+		}
+		opts.Gravity = g
 	}
-	if err != nil {
-		return fmt.Sprintf("%#v", x)
+	if opsMode != "" {
+		m, err := sgp4.ParseOpsMode(opsMode)
+		if err != nil {
+			return nil, err
+		}
+		opts.OpsMode = m
 	}
-	return string(js)
+	return &opts, nil
 }
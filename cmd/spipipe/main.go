@@ -7,19 +7,28 @@ import (
 	"flag"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	_ "net/http/pprof"
 
 	"github.com/pkg/profile"
 
+	"github.com/ut-astria/spi/bus"
+	"github.com/ut-astria/spi/jsonl"
 	"github.com/ut-astria/spi/node"
 	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/rules"
+	"github.com/ut-astria/spi/schema"
+	"github.com/ut-astria/spi/sgp4"
 	"github.com/ut-astria/spi/tle"
 )
 
@@ -42,10 +51,38 @@ func main() {
 		scan            = flag.Bool("scan", n.Scan, "Scan")
 		sample          = flag.Int("slow-sample", n.SlowSample, "Slow sampling rate")
 		sampleThreshold = flag.Float64("slow-sample-threshold", float64(n.SlowSampleThreshold), "Slow sample threshold")
+		filterApsides   = flag.Bool("filter-apsides", n.FilterApsides, "Apogee/perigee pre-filter")
+		filterPath      = flag.Bool("filter-path", n.FilterPath, "Orbit path pre-filter")
+		filterPad       = flag.Float64("filter-pad", float64(n.FilterPad), "Pre-filter padding (km)")
+		shards          = flag.Int("shards", n.Shards, "Number of nodes sharing the horizon")
+		shard           = flag.Int("shard", n.Shard, "This node's shard")
+		partitions      = flag.Int("partitions", n.Partitions, "Number of catalog partitions")
+		partition       = flag.Int("partition", n.Partition, "This node's catalog partition")
+		gravity         = flag.String("gravity", "", "SGP4 gravity model (wgs72old, wgs72, or wgs84)")
+		opsMode         = flag.String("opsmode", "", "SGP4 operation mode (afspc or improved)")
+
+		source = flag.String("source", "", "Three-line TLE source (-, file:NAME, fifo:NAME, or tcp:HOST:PORT) with acknowledged input")
+		sink   = flag.String("sink", "-", "Report sink (-, file:NAME, or tcp:HOST:PORT) when using -source; other output goes to stderr when this is stdout")
 
 		sampleMod   = flag.Int("sample-mod", 0, "Sample modulus")
 		sampleRem   = flag.Int("sample-rem", 0, "Sample remainder")
 		batchOutput = flag.Bool("batch-output", false, "output batches")
+		routes      = assignFlag{}
+		inputs      = assignFlag{}
+		outputs     = assignFlag{}
+
+		publisherPolicy   = flag.String("publisher-policy", node.AllPublishers, "Publisher policy (all, freshest, or priority)")
+		publisherPriority = flag.String("publisher-priority", "", "Comma-separated publishers (highest priority first)")
+		crossCheck        = flag.Float64("cross-check", 0, "Warn when publishers' positions for an object differ by more than this distance (km)")
+		consistency       = flag.Bool("consistency", false, "Report divergence between publishers' TLEs for the same object")
+		consistencyDist   = flag.Float64("consistency-dist", 1, "Minimum divergence (km) for a consistency report")
+		watch             = flag.String("watch", "", "JSON watch list file (reloaded on SIGHUP)")
+		volumes           = flag.String("volumes", "", "JSON file with an array of RIC screening volumes")
+		rois              = flag.String("rois", "", "GeoJSON file with regions of interest for overflight reports")
+		shadow            = flag.String("shadow", node.ConicalShadow, "Shadow model (conical or cylindrical) for lighting in reports")
+		rulesFile         = flag.String("rules", "", "JSON report rules file (reloaded on SIGHUP)")
+		compact           = flag.Bool("compact", false, "Compact reports (without TLEs) along with catalog events")
+		encoding          = flag.String("encoding", schema.JSON, "Report encoding (json, protobuf, or cbor); other encodings write other output to stderr")
 
 		logging          = flag.Bool("v", false, "Logging")
 		memProf          = flag.Bool("prof-mem", false, "Enable memory profiling")
@@ -53,6 +90,10 @@ func main() {
 		wg               sync.WaitGroup
 	)
 
+	flag.Var(routes, "route", "Route an output type to a file (example: metrics=metrics.jsonl); repeatable")
+	flag.Var(inputs, "input", "TLE input for a publisher (example: spacetrack=st.tle); repeatable (default: stdin)")
+	flag.Var(outputs, "output", "Named output for routed reports (example: alerts=alerts.jsonl); repeatable")
+
 	flag.Parse()
 
 	{ // Configure runtime
@@ -65,13 +106,100 @@ func main() {
 		}
 	}
 
-	pub := func(topic, msg string) {
-		js := fmt.Sprintf(`{"%s":%s"}`, topic, msg)
-		if _, err := fmt.Printf("%s\n", js); err != nil {
+	if err := schema.CheckEncoding(*encoding); err != nil {
+		log.Fatal(err)
+	}
+	binary := *encoding != schema.JSON
+	if binary && (*source != "" || *batchOutput) {
+		log.Fatalf("-encoding %s doesn't support -source or -batch-output", *encoding)
+	}
+
+	// With a binary encoding, stdout only has reports.  The same
+	// goes for a Sink on stdout, which writes independently.
+	var def io.Writer = os.Stdout
+	if binary || (*source != "" && *sink == "-") {
+		def = os.Stderr
+	}
+
+	w := jsonl.NewWriter(def)
+	for typ, filename := range routes {
+		f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w.Route(typ, f)
+	}
+
+	pub := func(typ string, payload interface{}) {
+		if err := w.Write(typ, payload); err != nil {
 			panic(err)
 		}
 	}
 
+	// report writes a report to stdout.
+	report := func(r *node.Report) error {
+		pub(jsonl.ReportType(r), r)
+		return nil
+	}
+	if binary {
+		report = newReportWriter(os.Stdout, *encoding)
+	}
+
+	named := make(map[string]func(*node.Report) error, len(outputs))
+	for name, filename := range outputs {
+		f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		named[name] = newReportWriter(f, *encoding)
+	}
+
+	engine := rules.NewEngine(nil)
+	loadRules := func() error {
+		rs, err := rules.ReadRuleSet(*rulesFile)
+		if err != nil {
+			return err
+		}
+		for _, name := range rs.Routes() {
+			if _, have := named[name]; !have {
+				return fmt.Errorf("%s: no -output for route %s", *rulesFile, name)
+			}
+		}
+		engine.Set(rs)
+		return nil
+	}
+	if *rulesFile != "" {
+		if err := loadRules(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// apply applies the rules to the report, writes it to its
+	// routes, and returns true if the report should be written to
+	// the default output.
+	apply := func(r *node.Report) bool {
+		routes, keep := engine.Apply(r)
+		if !keep {
+			return false
+		}
+		for _, name := range routes {
+			if err := named[name](r); err != nil {
+				panic(err)
+			}
+		}
+		return len(routes) == 0
+	}
+
+	if *gravity != "" || *opsMode != "" {
+		opts, err := sgp4Options(*gravity, *opsMode)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n.SGP4 = opts
+	}
+
 	read := func(publisher string, r *bufio.Reader) []*node.PubTLE {
 		var batch []*node.PubTLE
 		t := time.NewTimer(time.Second)
@@ -140,7 +268,12 @@ func main() {
 			return nil
 		}
 
-		if err := tle.DoTLEs(r, tle.NewSGP4TLE, f); err != nil {
+		parser := tle.NewSGP4TLE
+		if n.SGP4 != nil {
+			parser = tle.NewSGP4TLEWith(*n.SGP4)
+		}
+
+		if err := tle.DoTLEs(r, parser, f); err != nil {
 			panic(err)
 		}
 
@@ -157,45 +290,207 @@ func main() {
 	n.SlowSample = *sample
 	n.SlowSampleThreshold = float32(*sampleThreshold)
 	n.Scan = *scan
+	n.FilterApsides = *filterApsides
+	n.FilterPath = *filterPath
+	n.FilterPad = float32(*filterPad)
+	n.Shards = *shards
+	n.Shard = *shard
+	n.Partitions = *partitions
+	n.Partition = *partition
+	n.PublisherPolicy = *publisherPolicy
+	if *publisherPriority != "" {
+		n.PublisherPriority = strings.Split(*publisherPriority, ",")
+	}
+	n.CrossCheck = float32(*crossCheck)
+	n.Consistency = *consistency
+	n.ConsistencyDist = float32(*consistencyDist)
+	if err := node.CheckShadowModel(*shadow); err != nil {
+		log.Fatal(err)
+	}
+	n.ShadowModel = *shadow
+
+	if *volumes != "" {
+		js, err := ioutil.ReadFile(*volumes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(js, &n.ScreeningVolumes); err != nil {
+			log.Fatalf("%s: %s", *volumes, err)
+		}
+		for k := range n.ScreeningVolumes {
+			if err := n.ScreeningVolumes[k].Check(); err != nil {
+				log.Fatalf("%s: volume %d: %s", *volumes, k, err)
+			}
+		}
+	}
+
+	if *rois != "" {
+		rs, err := node.ReadROIs(*rois)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n.ROIs = rs
+	}
+
+	if *watch != "" {
+		wl, err := node.ReadWatchList(*watch)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n.WatchList = wl
+		n.Watches = make(chan *node.WatchList)
+	}
+
+	if err := n.Cfg.Check(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *watch != "" || *rulesFile != "" {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-hup:
+				}
+				if *rulesFile != "" {
+					if err := loadRules(); err != nil {
+						log.Printf("rules: %s", err)
+					}
+				}
+				if *watch == "" {
+					continue
+				}
+				wl, err := node.ReadWatchList(*watch)
+				if err != nil {
+					log.Printf("watch list: %s", err)
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case n.Watches <- wl:
+				}
+			}
+		}()
+	}
 
 	n.Metrics = make(chan node.Metrics)
 	n.Errs = make(chan error)
+	if *compact {
+		// Unbuffered so that catalog events precede the reports
+		// that refer to them.
+		n.CompactReports = true
+		n.Catalog = make(chan []*node.CatalogEvent)
+	}
 	n.Out = make(chan []*node.Report, 32)
 
+	// When using a Source, the Pipe writes the reports.
+	out := n.Out
+	var pipe *bus.Pipe
+	if *source != "" {
+		src, err := bus.OpenSource(*source, 3)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Messages are three-line TLEs.
+		src = bus.NewTLESource(src)
+		snk, err := bus.OpenSink(*sink)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pipe = bus.NewPipe(n, src, snk)
+		pipe.Publisher = "stdin"
+		pipe.Logging = *logging
+		pipe.BatchSize = inputBatchSize
+		if n.SGP4 != nil {
+			pipe.Parser = tle.NewSGP4TLEWith(*n.SGP4)
+		}
+		pipe.Encode = func(r *node.Report) ([]byte, error) {
+			if !apply(r) {
+				return nil, nil
+			}
+			return w.Encode(jsonl.ReportType(r), r)
+		}
+		out = nil
+	}
+
 	go func() {
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case err := <-n.Errs:
-				js := JSON(map[string]interface{}{
-					"error": err.Error(),
-				}, false)
-				pub("errs", js)
+				pub(jsonl.TypeError, jsonl.NewError(err))
 			case m := <-n.Metrics:
-				pub("metrics", JSON(m, false))
-			case rs := <-n.Out:
+				pub(jsonl.TypeMetrics, m)
+			case es := <-n.Catalog:
+				pub(jsonl.TypeCatalog, es)
+			case rs := <-out:
+				acc := make([]*node.Report, 0, len(rs))
+				for _, r := range rs {
+					if apply(r) {
+						acc = append(acc, r)
+					}
+				}
 				if *batchOutput {
-					pub("reports", JSON(rs, false))
+					if 0 < len(acc) {
+						pub(jsonl.TypeReports, acc)
+					}
 				} else {
-					for _, r := range rs {
-						pub("report", JSON(r, false))
+					for _, r := range acc {
+						if err := report(r); err != nil {
+							panic(err)
+						}
 					}
 				}
 			}
 		}
 	}()
 
+	pub(jsonl.TypeLifecycle, &jsonl.Lifecycle{
+		Event: jsonl.Start,
+		Time:  time.Now().UTC(),
+		Cfg:   &n.Cfg,
+	})
+
 	go n.Run(ctx)
 
-	go func() {
-		r := bufio.NewReader(os.Stdin)
-		defer os.Stdin.Close()
-		if err := read("stdin", r); err != nil {
-			panic(err)
+	if pipe != nil {
+		go func() {
+			if err := pipe.Run(ctx); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	} else {
+		if len(inputs) == 0 {
+			inputs["stdin"] = "-"
 		}
-		log.Printf("Reader done")
-	}()
+		for publisher, filename := range inputs {
+			go func(publisher, filename string) {
+				in := os.Stdin
+				if filename != "-" {
+					f, err := os.Open(filename)
+					if err != nil {
+						log.Fatal(err)
+					}
+					in = f
+				}
+				defer in.Close()
+				if err := read(publisher, bufio.NewReader(in)); err != nil {
+					panic(err)
+				}
+				log.Printf("Reader done (%s)", publisher)
+				pub(jsonl.TypeLifecycle, &jsonl.Lifecycle{
+					Event:  jsonl.InputDone,
+					Time:   time.Now().UTC(),
+					Detail: publisher,
+				})
+			}(publisher, filename)
+		}
+	}
 
 	if 0 < *duration {
 		time.Sleep(*duration)
@@ -204,9 +499,51 @@ func main() {
 		wg.Wait()
 	}
 
+	pub(jsonl.TypeLifecycle, &jsonl.Lifecycle{
+		Event: jsonl.Stop,
+		Time:  time.Now().UTC(),
+	})
+
 	log.Printf("main done")
 }
 
+// newReportWriter returns a function that writes reports to w using
+// the encoding.  JSON reports are jsonl Envelopes.
+func newReportWriter(w io.Writer, encoding string) func(*node.Report) error {
+	if encoding == schema.JSON {
+		jw := jsonl.NewWriter(w)
+		return func(r *node.Report) error {
+			return jw.Write(jsonl.ReportType(r), r)
+		}
+	}
+	e, err := schema.NewEncoder(w, encoding)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return e.Encode
+}
+
+// assignFlag collects KEY=VALUE flag values (such as output types to
+// filenames).
+type assignFlag map[string]string
+
+func (r assignFlag) String() string {
+	acc := make([]string, 0, len(r))
+	for typ, filename := range r {
+		acc = append(acc, typ+"="+filename)
+	}
+	return strings.Join(acc, ",")
+}
+
+func (r assignFlag) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("bad value '%s' (want KEY=VALUE)", s)
+	}
+	r[parts[0]] = parts[1]
+	return nil
+}
+
 func SatBatches(sats []*node.PubTLE, n int) [][]*node.PubTLE {
 	acc := make([][]*node.PubTLE, 0, 1+len(sats)/n)
 	var batch []*node.PubTLE
@@ -226,6 +563,27 @@ func SatBatches(sats []*node.PubTLE, n int) [][]*node.PubTLE {
 	return acc
 }
 
+// sgp4Options makes SGP4 options based on sgp4.DefaultOptions and
+// the given (optional) names.
+func sgp4Options(gravity, opsMode string) (*sgp4.Options, error) {
+	opts := sgp4.DefaultOptions
+	if gravity != "" {
+		g, err := sgp4.ParseGravity(gravity)
+		if err != nil {
+			return nil, err
+		}
+		opts.Gravity = g
+	}
+	if opsMode != "" {
+		m, err := sgp4.ParseOpsMode(opsMode)
+		if err != nil {
+			return nil, err
+		}
+		opts.OpsMode = m
+	}
+	return &opts, nil
+}
+
 func JSON(x interface{}, pretty bool) string {
 	var js []byte
 	var err error
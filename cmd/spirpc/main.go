@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/node/rpc"
+	"github.com/ut-astria/spi/node/rpc/pb"
+)
+
+func main() {
+
+	log.SetFlags(log.Lmicroseconds | log.LUTC)
+
+	n := node.NewNode(nil)
+
+	flag.BoolVar(&n.Scan, "scan", n.Scan, "Scan for sub-tick proximity")
+	flag.IntVar(&n.SlowSample, "slow-sample", n.SlowSample, "Sample during slow approaches")
+	flag.IntVar(&n.Horizon, "horizon", n.Horizon, "Horizon in number of ticks")
+	flag.IntVar(&n.IndexLevel, "index-level", n.IndexLevel, "Index's cells level")
+	flag.BoolVar(&n.Logging, "v", n.Logging, "Logging")
+
+	var (
+		addr      = flag.String("addr", ":9090", "gRPC listen address")
+		publisher = flag.String("publisher", "grpc", "Default publisher for submitted elements")
+
+		scanDist = flag.Float64("scan-dist", float64(n.ScanDist),
+			"Threshold for sub-tick scan distance")
+
+		indexDist = flag.Float64("index-dist", float64(n.IndexDist),
+			"Threshold for index search distance")
+
+		cfg = flag.String("cfg", "", "Filename for JSON configuration; overrides any other args")
+	)
+
+	flag.Parse()
+
+	n.ScanDist = float32(*scanDist)
+	n.IndexDist = float32(*indexDist)
+
+	if *cfg != "" {
+		js, err := ioutil.ReadFile(*cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = json.Unmarshal(js, &n.Cfg); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := n.Cfg.Check(); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var (
+		s   = rpc.NewServer(n)
+		srv = grpc.NewServer()
+	)
+	s.Publisher = *publisher
+	pb.RegisterSPIServer(srv, s)
+
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+		sig := <-sigs
+		log.Printf("Received %s", sig)
+		cancel()
+		srv.GracefulStop()
+	}()
+
+	go s.Run(ctx)
+	go n.Run(ctx)
+
+	log.Printf("Listening at %s", *addr)
+	if err := srv.Serve(l); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("main done")
+}
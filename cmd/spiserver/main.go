@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/node/server"
+)
+
+func main() {
+
+	log.SetFlags(log.Lmicroseconds | log.LUTC)
+
+	n := node.NewNode(nil)
+
+	flag.BoolVar(&n.Scan, "scan", n.Scan, "Scan for sub-tick proximity")
+	flag.IntVar(&n.SlowSample, "slow-sample", n.SlowSample, "Sample during slow approaches")
+	flag.IntVar(&n.Horizon, "horizon", n.Horizon, "Horizon in number of ticks")
+	flag.IntVar(&n.IndexLevel, "index-level", n.IndexLevel, "Index's cells level")
+	flag.BoolVar(&n.Logging, "v", n.Logging, "Logging")
+
+	var (
+		addr      = flag.String("addr", ":8080", "HTTP listen address")
+		publisher = flag.String("publisher", "http", "Default publisher for submitted TLEs")
+
+		scanDist = flag.Float64("scan-dist", float64(n.ScanDist),
+			"Threshold for sub-tick scan distance")
+
+		indexDist = flag.Float64("index-dist", float64(n.IndexDist),
+			"Threshold for index search distance")
+
+		cfg = flag.String("cfg", "", "Filename for JSON configuration; overrides any other args")
+	)
+
+	flag.Parse()
+
+	n.ScanDist = float32(*scanDist)
+	n.IndexDist = float32(*indexDist)
+
+	if *cfg != "" {
+		js, err := ioutil.ReadFile(*cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = json.Unmarshal(js, &n.Cfg); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := n.Cfg.Check(); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+		s := <-sigs
+		log.Printf("Received %s", s)
+		cancel()
+	}()
+
+	s := server.NewServer(n)
+	s.Publisher = *publisher
+
+	go s.Run(ctx)
+	go n.Run(ctx)
+
+	log.Printf("Listening at %s", *addr)
+	if err := s.ListenAndServe(ctx, *addr); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("main done")
+}
@@ -13,14 +13,21 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/ut-astria/spi/cdm"
+	"github.com/ut-astria/spi/jsonl"
 	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/node/server"
 	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/schema"
 	"github.com/ut-astria/spi/tle"
+	"github.com/ut-astria/spi/viz"
 
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -31,7 +38,7 @@ import (
 func main() {
 
 	usage := func() string {
-		return `Usage: csv|vsc|new|old|elements|sample|tag|prop|plot
+		return `Usage: csv|vsc|new|old|elements|sample|tag|prop|plot|cdm|czml|kml|screen
 
 csv: TLE to CSV
 vsc: CSV to TLE
@@ -42,6 +49,10 @@ sample: sample TLEs
 tag: add tag to TLE line0
 prop: propagate (SGP4)
 plot: generate a crude PNG of reports
+cdm: convert reports to screening-grade CCSDS CDMs
+czml: render reports as CZML (Cesium)
+kml: render reports as KML (Google Earth)
+screen: screen a hypothetical TLE against a spiserver (what-if)
 `
 	}
 
@@ -219,8 +230,6 @@ plot: generate a crude PNG of reports
 
 	case "elements":
 		// Extract some elements as JSON.
-		panic("not implemented (for Vallado)")
-
 		var (
 			fs     = flag.NewFlagSet("elements", flag.PanicOnError)
 			inFile = fs.String("in", defaultFile, "TLE input filename")
@@ -240,16 +249,18 @@ plot: generate a crude PNG of reports
 		}
 
 		err = tle.DoTLEs(bufio.NewReader(r), nil, func(i int, line0 string, p prop.Propagator) error {
-			// m := make(map[string]interface{})
-			// o := p.(*tle.SGP4TLE)
-			// es := o.Sat.Elements()
-			// m["elements"] = es
-			// m["tle"] = o.TLE
-			// js, err := json.Marshal(&m)
-			// if err != nil {
-			// 	log.Fatalf("json.Marshal %s on %#v", err, m)
-			// }
-			// fmt.Printf("%s\n", js)
+			o := p.(*tle.SGP4TLE)
+			m := map[string]interface{}{
+				"CatNum":   o.CatNum,
+				"TLE":      o.TLE,
+				"Type":     o.Type(),
+				"Elements": o.Elements(),
+			}
+			js, err := json.Marshal(&m)
+			if err != nil {
+				log.Fatalf("json.Marshal %s on %#v", err, m)
+			}
+			fmt.Printf("%s\n", js)
 			return nil
 		})
 
@@ -296,6 +307,8 @@ plot: generate a crude PNG of reports
 			from     = fs.String("from", "", "Start time")
 			duration = fs.Duration("horizon", 600*time.Second, "Duration")
 			interval = fs.Duration("interval", 20*time.Second, "Interval")
+			format   = fs.String("format", "json", "Output format (json, geojson, or csv)")
+			shadow   = fs.String("shadow", node.ConicalShadow, "Shadow model (conical or cylindrical)")
 			// vallado  = flag.Bool("vallado", true, "Use Vallado SGP4 implementation")
 		)
 
@@ -321,8 +334,36 @@ plot: generate a crude PNG of reports
 		}
 		then := now.Add(*duration)
 
+		if err := node.CheckShadowModel(*shadow); err != nil {
+			log.Fatal(err)
+		}
+
+		switch *format {
+		case "json", "geojson", "csv":
+		default:
+			log.Fatalf("unknown format '%s'", *format)
+		}
+
+		var (
+			out    = bufio.NewWriter(os.Stdout)
+			tracks []*viz.ObjectTrack
+		)
+
+		if *format == "csv" {
+			if err := viz.WriteCSV(out, nil, true); err != nil {
+				log.Fatal(err)
+			}
+		}
+
 		err = tle.DoTLEs(bufio.NewReader(r), nil, func(i int, line0 string, p prop.Propagator) error {
 			o := p.(*tle.SGP4TLE)
+			track := &viz.ObjectTrack{
+				Name: strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(o.TLE[0]), "0 ")),
+				TLE:  o,
+			}
+			if track.Name == "" {
+				track.Name = o.CatNum
+			}
 			for t := now; t.Before(then); t = t.Add(*interval) {
 				e, err := o.Prop(t)
 				if err != nil {
@@ -334,22 +375,46 @@ plot: generate a crude PNG of reports
 					return err
 				}
 
+				light := node.Light(t, e, *shadow)
+
+				if *format != "json" {
+					track.Points = append(track.Points, viz.Point{
+						T:     t,
+						LLA:   *lla,
+						Light: light,
+					})
+					continue
+				}
+
 				m := map[string]interface{}{
 					"At":    t,
 					"State": e,
 					"TLE":   o.TLE,
 					"LLA":   lla,
 					"Age":   o.ApproxAge(t).Seconds(),
+					"Light": light,
 				}
 				js, err := json.Marshal(&m)
 				if err != nil {
 					log.Fatalf("prop json.Marshal error %s on %#v", err, m)
 				}
-				fmt.Printf("%s\n", js)
+				fmt.Fprintf(out, "%s\n", js)
+			}
+			switch *format {
+			case "csv":
+				return viz.WriteCSV(out, []*viz.ObjectTrack{track}, false)
+			case "geojson":
+				tracks = append(tracks, track)
 			}
 			return nil
 		})
 
+		if err == nil && *format == "geojson" {
+			err = viz.WriteGeoJSON(out, tracks)
+		}
+		if err == nil {
+			err = out.Flush()
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -429,6 +494,10 @@ plot: generate a crude PNG of reports
 			if r.At.After(t1) {
 				t1 = r.At
 			}
+			if len(r.Objs) != 2 {
+				// Not a pair (say an overflight).
+				continue
+			}
 			pair := r.Objs[0].Name + "/" + r.Objs[1].Name
 			ps, have := pairs[pair]
 			if !have {
@@ -485,12 +554,318 @@ plot: generate a crude PNG of reports
 			panic(err)
 		}
 
+	case "cdm":
+		// Reports in and CDMs out.
+		var (
+			fs         = flag.NewFlagSet("cdm", flag.PanicOnError)
+			inFile     = fs.String("in", defaultFile, "reports input filename")
+			encoding   = fs.String("encoding", schema.JSON, "reports encoding (json, protobuf, or cbor)")
+			envelopes  = fs.Bool("envelopes", false, "input is spipipe JSON Lines envelopes")
+			format     = fs.String("format", "kvn", "CDM format (kvn or xml)")
+			dir        = fs.String("dir", "", "directory for CDM files (named by MESSAGE_ID) instead of stdout")
+			originator = fs.String("originator", "SPI", "ORIGINATOR")
+			covFile    = fs.String("covariance", "", "JSON file with RTN sigmas (m, m/s) by object type (\"\" for the default)")
+		)
+
+		fs.Parse(args)
+
+		if *format != "kvn" && *format != "xml" {
+			log.Fatalf("unknown format '%s'", *format)
+		}
+
+		opts := &cdm.Options{
+			Originator: *originator,
+		}
+		if *covFile != "" {
+			js, err := ioutil.ReadFile(*covFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			var ts cdm.TypeSigmas
+			if err := json.Unmarshal(js, &ts); err != nil {
+				log.Fatalf("%s: %s", *covFile, err)
+			}
+			opts.Covariance = ts
+		}
+
+		in := os.Stdin
+		if *inFile != "-" {
+			f, err := os.Open(*inFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		var (
+			converted, skipped int
+			out                = bufio.NewWriter(os.Stdout)
+		)
+
+		err := doReports(in, *encoding, *envelopes, func(r *node.Report) error {
+			c, err := cdm.FromReport(r, opts)
+			if err == cdm.ErrNotConjunction {
+				skipped++
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			write := c.WriteKVN
+			if *format == "xml" {
+				write = c.WriteXML
+			}
+
+			if *dir == "" {
+				if 0 < converted {
+					fmt.Fprintf(out, "\n")
+				}
+				converted++
+				return write(out)
+			}
+
+			f, err := os.Create(filepath.Join(*dir, c.MessageID+"."+*format))
+			if err != nil {
+				return err
+			}
+			if err := write(f); err != nil {
+				f.Close()
+				return err
+			}
+			converted++
+			return f.Close()
+		})
+		if err == nil {
+			err = out.Flush()
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("Wrote %d CDMs (skipped %d reports)", converted, skipped)
+
+	case "czml", "kml":
+		// Reports (with TLEs) in and CZML or KML out.
+		var (
+			fs        = flag.NewFlagSet(cmd, flag.PanicOnError)
+			inFile    = fs.String("in", defaultFile, "reports input filename")
+			encoding  = fs.String("encoding", schema.JSON, "reports encoding (json, protobuf, or cbor)")
+			envelopes = fs.Bool("envelopes", false, "input is spipipe JSON Lines envelopes")
+			before    = fs.Duration("before", viz.DefaultWindow.Before, "track duration before each report's time")
+			after     = fs.Duration("after", viz.DefaultWindow.After, "track duration after each report's time")
+			step      = fs.Duration("step", viz.DefaultWindow.Step, "track sampling interval")
+		)
+
+		fs.Parse(args)
+
+		in := os.Stdin
+		if *inFile != "-" {
+			f, err := os.Open(*inFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		var (
+			w = viz.Window{
+				Before: *before,
+				After:  *after,
+				Step:   *step,
+			}
+			es      []*viz.Encounter
+			skipped int
+		)
+
+		err := doReports(in, *encoding, *envelopes, func(r *node.Report) error {
+			if r.Canceled {
+				skipped++
+				return nil
+			}
+			e, err := viz.NewEncounter(r, w)
+			if err == viz.ErrNoTLE {
+				skipped++
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			es = append(es, e)
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		out := bufio.NewWriter(os.Stdout)
+		if cmd == "czml" {
+			err = viz.WriteCZML(out, es)
+		} else {
+			err = viz.WriteKML(out, es)
+		}
+		if err == nil {
+			err = out.Flush()
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("Rendered %d reports (skipped %d)", len(es), skipped)
+
+	case "screen":
+		// Screen a hypothetical object against the live set of a
+		// running spiserver.  The node's state doesn't change.
+		var (
+			fs     = flag.NewFlagSet("screen", flag.PanicOnError)
+			inFile = fs.String("in", defaultFile, "TLE input filename (the first TLE is the hypothetical object)")
+			url    = fs.String("url", "http://localhost:8080", "spiserver URL")
+			name   = fs.String("name", "", "Name for the hypothetical object")
+			from   = fs.String("from", "", "Start time (RFC3339, default now)")
+			to     = fs.String("to", "", "End time (RFC3339, default from plus the node's horizon)")
+			dist   = fs.Float64("dist", 0, "Maximum distance (km) instead of the node's screening")
+		)
+
+		fs.Parse(args)
+
+		var r io.Reader
+		var err error
+		if *inFile == "-" {
+			r = os.Stdin
+		} else {
+			r, err = os.Open(*inFile)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var o *tle.SGP4TLE
+		err = tle.DoTLEs(bufio.NewReader(r), nil, func(i int, line0 string, p prop.Propagator) error {
+			if o == nil {
+				o = p.(*tle.SGP4TLE)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if o == nil {
+			log.Fatal("no TLE")
+		}
+
+		js, err := json.Marshal(o)
+		if err != nil {
+			log.Fatal(err)
+		}
+		req := &server.ScreenRequest{
+			Name: *name,
+			TLE:  js,
+			Dist: float32(*dist),
+		}
+		for _, x := range []struct {
+			s string
+			t *time.Time
+		}{{*from, &req.From}, {*to, &req.To}} {
+			if x.s == "" {
+				continue
+			}
+			if *x.t, err = time.Parse(time.RFC3339, x.s); err != nil {
+				log.Fatalf("Bad time: %s %s", x.s, err)
+			}
+		}
+
+		if js, err = json.Marshal(req); err != nil {
+			log.Fatal(err)
+		}
+		resp, err := http.Post(strings.TrimSuffix(*url, "/")+"/screen", "application/json", bytes.NewReader(js))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			msg, _ := ioutil.ReadAll(resp.Body)
+			log.Fatalf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+		}
+
+		var rs []*node.Report
+		if err := json.NewDecoder(resp.Body).Decode(&rs); err != nil {
+			log.Fatal(err)
+		}
+		out := bufio.NewWriter(os.Stdout)
+		for _, r := range rs {
+			js, err := json.Marshal(r)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Fprintf(out, "%s\n", js)
+		}
+		if err := out.Flush(); err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("%d close approaches", len(rs))
+
 	default:
 		fmt.Printf("%s\n", usage())
 		os.Exit(1)
 	}
 }
 
+// doReports calls the function for each report from the reader,
+// which has either encoded reports (see package schema) or JSON Lines
+// envelopes (see package jsonl).
+func doReports(in io.Reader, encoding string, envelopes bool, f func(*node.Report) error) error {
+	if !envelopes {
+		d, err := schema.NewDecoder(in, encoding)
+		if err != nil {
+			return err
+		}
+		for {
+			r, err := d.Decode()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := f(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	d := jsonl.NewDecoder(in)
+	for {
+		e, err := d.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var rs []*node.Report
+		switch e.Type {
+		case jsonl.TypeReport, jsonl.TypeConsistency, jsonl.TypeOverflight:
+			r, err := e.Report()
+			if err != nil {
+				return err
+			}
+			rs = []*node.Report{r}
+		case jsonl.TypeReports:
+			if rs, err = e.Reports(); err != nil {
+				return err
+			}
+		}
+		for _, r := range rs {
+			if err := f(r); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func doTLEs(filename string, f func(i int, o *tle.SGP4TLE) error) error {
 	r, err := os.Open(filename)
 	if err != nil {
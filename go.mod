@@ -3,8 +3,9 @@ module github.com/ut-astria/spi
 go 1.13
 
 require (
+	github.com/fxamacker/cbor/v2 v2.5.0
 	github.com/golang/geo v0.0.0-20200319012246-673a6f80352d
-	github.com/golang/protobuf v1.3.4 // indirect
+	github.com/golang/protobuf v1.3.4
 	github.com/jsmorph/go-satellite v0.0.0-20200209185444-f2e743f52cab
 	github.com/kr/pretty v0.2.0 // indirect
 	github.com/onsi/ginkgo v1.12.0 // indirect
@@ -13,5 +14,6 @@ require (
 	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e // indirect
 	golang.org/x/text v0.3.2 // indirect
 	gonum.org/v1/plot v0.8.0
+	google.golang.org/grpc v1.27.1
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 )
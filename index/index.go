@@ -239,6 +239,16 @@ type Index struct {
 
 	IPPS  map[Key]*IdProbPoss
 	Cells map[CellId]*Cell
+
+	// watching indicates that Search should only return Conjs
+	// that involve at least one watched position.
+	//
+	// See SetWatching and UpdateWatched.
+	watching bool
+
+	// watched is the set of ids with watched positions when
+	// watching.
+	watched map[Id]bool
 }
 
 // NewIndex creates a new index based on cells with the given level
@@ -250,6 +260,7 @@ func NewIndex(level int, dist float32) *Index {
 		IPPS:       make(map[Key]*IdProbPoss),
 		CellFinder: NewCellFinder(level),
 		Cells:      make(map[CellId]*Cell),
+		watched:    make(map[Id]bool),
 	}
 }
 
@@ -261,14 +272,47 @@ func NewIndexWithFinder(finder *CellFinder, dist float32) *Index {
 		IPPS:       make(map[Key]*IdProbPoss),
 		CellFinder: finder,
 		Cells:      make(map[CellId]*Cell),
+		watched:    make(map[Id]bool),
+	}
+}
+
+// SetWatching turns watching on or off.
+//
+// When watching, Search only returns Conjs that involve at least one
+// watched position (see UpdateWatched).  Turning watching on marks
+// all current positions as watched, so subsequent UpdateWatched calls
+// for unwatched keys will report the resulting cancellations.
+//
+// This implementation is not safe for concurrent use.
+func (i *Index) SetWatching(watching bool) {
+	if i.watching == watching {
+		return
+	}
+	i.watching = watching
+	i.watched = make(map[Id]bool)
+	if watching {
+		for _, ipps := range i.IPPS {
+			i.watched[ipps.Id] = true
+		}
 	}
 }
 
+// Watching reports whether the index is watching.
+func (i *Index) Watching() bool {
+	return i.watching
+}
+
 // Search is the core method for finding Conjs.
 //
 // This implementation is not safe for concurrent use.
 func (i *Index) Search(cid CellId, spp IdProbPos, d float32) []Conj {
 
+	// An unwatched position only pairs with watched ones.
+	picky := i.watching && !i.watched[spp.Id]
+	if picky && len(i.watched) == 0 {
+		return nil
+	}
+
 	var (
 		neighbors = append(i.CellFinder.Neighbors(cid), s2.CellID(cid))
 		cells     = make([]*Cell, 0, len(neighbors)+1)
@@ -289,6 +333,9 @@ func (i *Index) Search(cid CellId, spp IdProbPos, d float32) []Conj {
 			if spp0 == spp {
 				continue
 			}
+			if picky && !i.watched[spp0.Id] {
+				continue
+			}
 			if spp0.CatalogNum == spp.CatalogNum {
 				if spp0.CatalogNum != 0 && spp.CatalogNum != 0 {
 					continue
@@ -313,6 +360,34 @@ func (i *Index) Search(cid CellId, spp IdProbPos, d float32) []Conj {
 	return cs
 }
 
+// Query returns the indexed positions within d of the given
+// position.  The index isn't changed, and watching doesn't apply.
+//
+// As with Search, only the position's cell and its neighbors are
+// examined, so d shouldn't exceed the index's Dist.
+//
+// This implementation is not safe for concurrent use.
+func (i *Index) Query(p Pos, d float32) ([]IdProbPos, error) {
+	cid, err := i.CellFinder.Find(p)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := make([]IdProbPos, 0, 2)
+	for _, n := range append(i.CellFinder.Neighbors(cid), s2.CellID(cid)) {
+		cell, have := i.Cells[CellId(n)]
+		if !have {
+			continue
+		}
+		for _, ipp := range cell.items {
+			if ipp.Dist(p) <= d {
+				acc = append(acc, ipp)
+			}
+		}
+	}
+	return acc, nil
+}
+
 func (c *Cell) Add(ipp IdProbPos) {
 	c.items = append(c.items, ipp)
 }
@@ -373,7 +448,13 @@ func (i *Index) GetCell(p Pos) (*Cell, CellId, error) {
 	return cell, id, nil
 }
 
-// Update updates the index and returns canceled Conj(s) and new
+// Update calls UpdateWatched with watched false, which is only
+// significant when watching.
+func (i *Index) Update(id Id, key Key, pps []ProbPos) ([]Conj, []Conj, Id, error) {
+	return i.UpdateWatched(id, key, pps, false)
+}
+
+// UpdateWatched updates the index and returns canceled Conj(s) and new
 // Conj(s).
 //
 // This implementation is not safe for concurrent use.
@@ -395,7 +476,10 @@ func (i *Index) GetCell(p Pos) (*Cell, CellId, error) {
 // Some of the constants in this method body relate to tuning (such as
 // Level).  The coarser the cells, the larger the initial allocations.
 // ToDo: Expose these values.
-func (i *Index) Update(id Id, key Key, pps []ProbPos) ([]Conj, []Conj, Id, error) {
+//
+// When watching (see SetWatching), watched indicates whether the
+// given positions are watched.
+func (i *Index) UpdateWatched(id Id, key Key, pps []ProbPos, watched bool) ([]Conj, []Conj, Id, error) {
 
 	// Remove all ps previously associated with key.
 	var (
@@ -429,6 +513,13 @@ func (i *Index) Update(id Id, key Key, pps []ProbPos) ([]Conj, []Conj, Id, error
 		oldCs = append(oldCs, cs...)
 	}
 
+	if i.watching {
+		delete(i.watched, oldId)
+		if watched && 0 < len(pps) {
+			i.watched[id] = true
+		}
+	}
+
 	// Write pps and gather new Conjs.
 	newCs := make([]Conj, 0, 8)
 	for _, pp := range pps {
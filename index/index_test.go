@@ -350,3 +350,85 @@ LOOP:
 	micsPer := secsPer * 1000 * 1000
 	log.Printf("b.N=%d %d %d %d %v mics/op", limit, i, cans, novs, micsPer)
 }
+
+func TestWatching(t *testing.T) {
+	var (
+		i   = NewIndex(7, 10)
+		pps = []ProbPos{{Pos{X: 10, Y: 11, Z: 12}}}
+		a   = Key{CatalogNum: 1}
+		b   = Key{CatalogNum: 2}
+		c   = Key{CatalogNum: 3}
+	)
+
+	update := func(id Id, k Key, watched bool) ([]Conj, []Conj) {
+		cans, novs, _, err := i.UpdateWatched(id, k, pps, watched)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cans, novs
+	}
+
+	// b and c: both unwatched.
+	i.SetWatching(true)
+	if _, n := update(2, b, false); len(n) != 0 {
+		t.Fatal(n)
+	}
+	if _, n := update(3, c, false); len(n) != 0 {
+		t.Fatal("unwatched pair reported", n)
+	}
+
+	// a pairs with both.
+	if _, n := update(1, a, true); len(n) != 2 {
+		t.Fatalf("%d novel", len(n))
+	}
+
+	// b becomes watched: b-c is novel.
+	if _, n := update(2, b, true); len(n) != 1 {
+		t.Fatalf("%d novel", len(n))
+	}
+
+	// a becomes unwatched: a-c is canceled.
+	if cs, n := update(1, a, false); len(cs) != 1 || len(n) != 0 {
+		t.Fatalf("%d canceled, %d novel", len(cs), len(n))
+	}
+
+	// Turning watching off and on again marks everything
+	// watched.
+	i.SetWatching(false)
+	i.SetWatching(true)
+	if cs, _ := update(3, c, false); len(cs) != 0 {
+		t.Fatalf("%d canceled", len(cs))
+	}
+	if cs, _ := update(1, a, false); len(cs) != 1 {
+		t.Fatalf("%d canceled", len(cs))
+	}
+}
+
+func TestQuery(t *testing.T) {
+	var (
+		i = NewIndex(7, 10)
+		p = Pos{X: 7000, Y: 10, Z: 12}
+		q = Pos{X: 7005, Y: 10, Z: 12}
+		r = Pos{X: 7020, Y: 10, Z: 12}
+	)
+	i.SetWatching(true)
+
+	if _, _, _, err := i.Update(1, Key{CatalogNum: 1}, []ProbPos{{p}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := i.Update(2, Key{CatalogNum: 2}, []ProbPos{{r}}); err != nil {
+		t.Fatal(err)
+	}
+	cells := len(i.Cells)
+
+	ipps, err := i.Query(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ipps) != 1 || ipps[0].Id != 1 {
+		t.Fatalf("%v", ipps)
+	}
+	if len(i.Cells) != cells || len(i.IPPS) != 2 {
+		t.Fatal("Query changed the index")
+	}
+}
@@ -0,0 +1,360 @@
+// Package jsonl implements the JSON Lines protocol emitted by
+// spipipe.
+//
+// Every line is an Envelope:
+//
+//	{"type":"report","version":1,"seq":42,"payload":{...}}
+//
+// Seq increases by one for each envelope written by a Writer across
+// all types, so a consumer that reads several routed streams can merge
+// them and detect gaps.
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/tle"
+)
+
+// Version is the current protocol version.
+const Version = 1
+
+// Envelope types.
+const (
+	// TypeReport has a node.Report payload.
+	TypeReport = "report"
+
+	// TypeReports has a []*node.Report payload.
+	TypeReports = "reports"
+
+	// TypeConsistency has a node.Report payload with Kind
+	// node.ConsistencyReport.
+	TypeConsistency = "consistency"
+
+	// TypeOverflight has a node.Report payload with Kind
+	// node.OverflightReport.
+	TypeOverflight = "overflight"
+
+	// TypeCatalog has a []*node.CatalogEvent payload.
+	TypeCatalog = "catalog"
+
+	// TypeMetrics has a node.Metrics payload.
+	TypeMetrics = "metrics"
+
+	// TypeError has an Error payload.
+	TypeError = "error"
+
+	// TypeLifecycle has a Lifecycle payload.
+	TypeLifecycle = "lifecycle"
+)
+
+// Envelope frames every line.
+type Envelope struct {
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	Seq     uint64          `json:"seq"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Error is the payload for TypeError.
+type Error struct {
+	Error string `json:"error"`
+
+	// Warning indicates that the error was a node.Warning.
+	Warning bool `json:"warning,omitempty"`
+}
+
+// NewError makes an Error payload.
+func NewError(err error) *Error {
+	_, warning := err.(*node.Warning)
+	return &Error{
+		Error:   err.Error(),
+		Warning: warning,
+	}
+}
+
+// Lifecycle events.
+const (
+	// Start is emitted when processing starts.  The Lifecycle's
+	// Cfg is the node's configuration.
+	Start = "start"
+
+	// InputDone is emitted when an input is exhausted.
+	InputDone = "input-done"
+
+	// Stop is emitted when processing stops.
+	Stop = "stop"
+)
+
+// Lifecycle is the payload for TypeLifecycle.
+type Lifecycle struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+
+	// Cfg, if not nil, is the node's configuration.
+	Cfg *node.Cfg `json:"cfg,omitempty"`
+
+	Detail string `json:"detail,omitempty"`
+}
+
+// Writer writes Envelopes, optionally routing each type to a different
+// io.Writer.
+//
+// A Writer is safe for concurrent use.
+type Writer struct {
+	sync.Mutex
+
+	seq    uint64
+	def    io.Writer
+	routes map[string]io.Writer
+}
+
+// NewWriter makes a Writer that writes to w by default.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		def:    w,
+		routes: make(map[string]io.Writer),
+	}
+}
+
+// Route sends Envelopes of the given type to w.  A nil w discards
+// them.
+func (w *Writer) Route(typ string, out io.Writer) {
+	w.Lock()
+	if out == nil {
+		out = ioutil.Discard
+	}
+	w.routes[typ] = out
+	w.Unlock()
+}
+
+// Encode makes an Envelope (with the next Seq) and returns its line
+// (without a newline).  Callers that write that line themselves (say
+// to a bus.Sink) can use this method instead of Write.
+func (w *Writer) Encode(typ string, payload interface{}) ([]byte, error) {
+	js, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	w.Lock()
+	w.seq++
+	seq := w.seq
+	w.Unlock()
+	return json.Marshal(&Envelope{
+		Type:    typ,
+		Version: Version,
+		Seq:     seq,
+		Payload: js,
+	})
+}
+
+// Write writes an Envelope line for the payload.
+func (w *Writer) Write(typ string, payload interface{}) error {
+	js, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	out, have := w.routes[typ]
+	if !have {
+		out = w.def
+	}
+
+	w.seq++
+	line, err := json.Marshal(&Envelope{
+		Type:    typ,
+		Version: Version,
+		Seq:     w.seq,
+		Payload: js,
+	})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	_, err = out.Write(line)
+	return err
+}
+
+// Decoder reads Envelopes.
+type Decoder struct {
+	s *bufio.Scanner
+}
+
+// NewDecoder makes a Decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &Decoder{
+		s: s,
+	}
+}
+
+// Next returns the next Envelope or io.EOF.  Blank lines are skipped.
+//
+// Envelopes with a Version greater than this package's Version result
+// in an error.
+func (d *Decoder) Next() (*Envelope, error) {
+	for d.s.Scan() {
+		line := d.s.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e Envelope
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, err
+		}
+		if e.Type == "" {
+			return nil, fmt.Errorf("envelope without a type")
+		}
+		if Version < e.Version {
+			return nil, fmt.Errorf("unsupported version %d (> %d)", e.Version, Version)
+		}
+		return &e, nil
+	}
+	if err := d.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// check returns an error if the Envelope isn't of the given type.
+func (e *Envelope) check(typ string) error {
+	if e.Type != typ {
+		return fmt.Errorf("envelope type %s isn't %s", e.Type, typ)
+	}
+	return nil
+}
+
+// ReportType returns the envelope type for the Report: TypeReport,
+// TypeConsistency, or TypeOverflight.
+func ReportType(r *node.Report) string {
+	switch r.Kind {
+	case node.ConsistencyReport:
+		return TypeConsistency
+	case node.OverflightReport:
+		return TypeOverflight
+	}
+	return TypeReport
+}
+
+// Report decodes a TypeReport, TypeConsistency, or TypeOverflight
+// payload.
+func (e *Envelope) Report() (*node.Report, error) {
+	if e.Type != TypeConsistency && e.Type != TypeOverflight {
+		if err := e.check(TypeReport); err != nil {
+			return nil, err
+		}
+	}
+	var r node.Report
+	if err := json.Unmarshal(e.Payload, &r); err != nil {
+		return nil, err
+	}
+	if err := restore(&r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// restore re-parses the Report's TLEs so that they can be propagated.
+func restore(r *node.Report) error {
+	for i, s := range r.Objs {
+		if s.Obj == nil {
+			continue
+		}
+		js, err := json.Marshal(s.Obj)
+		if err != nil {
+			return err
+		}
+		if r.Objs[i].Obj, err = tle.ParseSGP4TLE(string(js)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reports decodes a TypeReports payload.
+func (e *Envelope) Reports() ([]*node.Report, error) {
+	if err := e.check(TypeReports); err != nil {
+		return nil, err
+	}
+	var rs []*node.Report
+	if err := json.Unmarshal(e.Payload, &rs); err != nil {
+		return nil, err
+	}
+	for _, r := range rs {
+		if err := restore(r); err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
+
+// Catalog decodes a TypeCatalog payload.
+func (e *Envelope) Catalog() ([]*node.CatalogEvent, error) {
+	if err := e.check(TypeCatalog); err != nil {
+		return nil, err
+	}
+	var es []*node.CatalogEvent
+	if err := json.Unmarshal(e.Payload, &es); err != nil {
+		return nil, err
+	}
+	for _, x := range es {
+		if x.Obj == nil {
+			continue
+		}
+		js, err := json.Marshal(x.Obj)
+		if err != nil {
+			return nil, err
+		}
+		if x.Obj, err = tle.ParseSGP4TLE(string(js)); err != nil {
+			return nil, err
+		}
+	}
+	return es, nil
+}
+
+// Metrics decodes a TypeMetrics payload.
+func (e *Envelope) Metrics() (*node.Metrics, error) {
+	if err := e.check(TypeMetrics); err != nil {
+		return nil, err
+	}
+	var m node.Metrics
+	if err := json.Unmarshal(e.Payload, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Error decodes a TypeError payload.
+func (e *Envelope) Error() (*Error, error) {
+	if err := e.check(TypeError); err != nil {
+		return nil, err
+	}
+	var x Error
+	if err := json.Unmarshal(e.Payload, &x); err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+// Lifecycle decodes a TypeLifecycle payload.
+func (e *Envelope) Lifecycle() (*Lifecycle, error) {
+	if err := e.check(TypeLifecycle); err != nil {
+		return nil, err
+	}
+	var x Lifecycle
+	if err := json.Unmarshal(e.Payload, &x); err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
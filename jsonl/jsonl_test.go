@@ -0,0 +1,274 @@
+package jsonl
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/tle"
+)
+
+var update = flag.Bool("update", false, "update testdata/envelopes.golden")
+
+const golden = "testdata/envelopes.golden"
+
+func testReport(t *testing.T) *node.Report {
+	p, err := tle.NewSGP4TLE("0 ISS (ZARYA)",
+		"1 25544U 98067A   20264.51782528 -.00002182  00000-0 -11606-4 0  2927",
+		"2 25544  51.6416 247.4627 0006703 130.5360 325.0288 15.72125391563537")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := p.(*tle.SGP4TLE)
+
+	var (
+		at    = time.Date(2020, 9, 20, 13, 0, 0, 0, time.UTC)
+		state = node.State{
+			Name: "25544/test",
+			Obj:  o,
+			Age:  2060,
+			Type: "payload",
+			ECI:  prop.Vect{X: 1, Y: 2, Z: 3},
+			Vel:  prop.Vect{X: 4, Y: 5, Z: 6},
+			LLA:  node.LatLonAlt{Lat: 10, Lon: 20, Alt: 400},
+		}
+	)
+
+	return &node.Report{
+		Schema:    node.ReportSchema,
+		Id:        "id",
+		Sig:       "sig",
+		Generated: at.Add(-time.Minute),
+		At:        at,
+		Dist:      1.5,
+		Speed:     7.25,
+		Objs:      []node.State{state, state},
+	}
+}
+
+// writeAll writes one of each type of Envelope.
+func writeAll(t *testing.T, w *Writer) {
+	var (
+		r  = testReport(t)
+		t0 = time.Date(2020, 9, 20, 12, 0, 0, 0, time.UTC)
+	)
+
+	write := func(typ string, payload interface{}) {
+		if err := w.Write(typ, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The node.Cfg isn't included here since it changes as features
+	// are added.
+	write(TypeLifecycle, &Lifecycle{
+		Event: Start,
+		Time:  t0,
+	})
+	write(TypeReport, r)
+	write(TypeReports, []*node.Report{r})
+	write(TypeMetrics, &node.Metrics{
+		T:    t0,
+		T1:   t0.Add(time.Minute),
+		In:   3,
+		Live: 2,
+		Lag:  time.Millisecond,
+	})
+	write(TypeError, NewError(node.Warningf("something %d", 42)))
+	write(TypeError, NewError(fmt.Errorf("bad")))
+	write(TypeLifecycle, &Lifecycle{
+		Event:  InputDone,
+		Time:   t0,
+		Detail: "stdin",
+	})
+	write(TypeCatalog, []*node.CatalogEvent{
+		{
+			Event:  node.CatalogNew,
+			Time:   t0,
+			Name:   r.Objs[0].Name,
+			Digest: "digest",
+			Obj:    r.Objs[0].Obj,
+		},
+		{
+			Event:  node.CatalogRetired,
+			Time:   t0,
+			Name:   r.Objs[1].Name,
+			Digest: "digest",
+		},
+	})
+	write(TypeLifecycle, &Lifecycle{
+		Event: Stop,
+		Time:  t0,
+	})
+}
+
+func TestGolden(t *testing.T) {
+	var buf bytes.Buffer
+	writeAll(t, NewWriter(&buf))
+
+	if *update {
+		if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("output doesn't match %s:\n%s", golden, buf.Bytes())
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	bs, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		d     = NewDecoder(bytes.NewReader(bs))
+		types []string
+	)
+
+	for i := 1; ; i++ {
+		e, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Seq != uint64(i) {
+			t.Fatalf("seq %d != %d", e.Seq, i)
+		}
+		if e.Version != Version {
+			t.Fatalf("version %d", e.Version)
+		}
+		types = append(types, e.Type)
+
+		switch e.Type {
+		case TypeReport:
+			r, err := e.Report()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Sig != "sig" || len(r.Objs) != 2 {
+				t.Fatalf("report %#v", r)
+			}
+			// The TLE should be usable.
+			if _, err := r.Objs[0].Obj.Prop(r.At); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.Metrics(); err == nil {
+				t.Fatal("report decoded as metrics")
+			}
+		case TypeReports:
+			rs, err := e.Reports()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rs) != 1 {
+				t.Fatalf("%d reports", len(rs))
+			}
+		case TypeMetrics:
+			m, err := e.Metrics()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.In != 3 || m.Lag != time.Millisecond {
+				t.Fatalf("metrics %#v", m)
+			}
+		case TypeError:
+			x, err := e.Error()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Error == "" {
+				t.Fatal("empty error")
+			}
+		case TypeCatalog:
+			es, err := e.Catalog()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(es) != 2 || es[1].Obj != nil {
+				t.Fatalf("catalog %#v", es)
+			}
+			if _, err := es[0].Obj.Prop(es[0].Time); err != nil {
+				t.Fatal(err)
+			}
+		case TypeLifecycle:
+			x, err := e.Lifecycle()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Time.IsZero() {
+				t.Fatal("lifecycle without time")
+			}
+		}
+	}
+
+	if len(types) != 9 {
+		t.Fatalf("types: %v", types)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	var (
+		def, metrics bytes.Buffer
+		w            = NewWriter(&def)
+	)
+	w.Route(TypeMetrics, &metrics)
+	w.Route(TypeLifecycle, nil)
+
+	writeAll(t, w)
+
+	count := func(bs []byte) map[string][]uint64 {
+		acc := make(map[string][]uint64)
+		d := NewDecoder(bytes.NewReader(bs))
+		for {
+			e, err := d.Next()
+			if err == io.EOF {
+				return acc
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			acc[e.Type] = append(acc[e.Type], e.Seq)
+		}
+	}
+
+	var (
+		d = count(def.Bytes())
+		m = count(metrics.Bytes())
+	)
+
+	if len(m) != 1 || len(m[TypeMetrics]) != 1 || m[TypeMetrics][0] != 4 {
+		t.Fatalf("metrics: %v", m)
+	}
+	if _, have := d[TypeMetrics]; have {
+		t.Fatal("metrics in default output")
+	}
+	if _, have := d[TypeLifecycle]; have {
+		t.Fatal("lifecycle not discarded")
+	}
+	if len(d[TypeError]) != 2 {
+		t.Fatalf("default: %v", d)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	d := NewDecoder(bytes.NewReader([]byte(`{"type":"report","version":99,"seq":1,"payload":{}}`)))
+	if _, err := d.Next(); err == nil {
+		t.Fatal("should have complained about version")
+	}
+}
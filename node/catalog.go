@@ -0,0 +1,137 @@
+package node
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ut-astria/spi/index"
+	"github.com/ut-astria/spi/misc"
+	"github.com/ut-astria/spi/tle"
+)
+
+// Compact reports (see Cfg.CompactReports) refer to objects by Name
+// and Digest.  The Node announces each change to the set of objects
+// via CatalogEvents on its Catalog channel, so a consumer can
+// maintain a local catalog and join reports with it.  A consumer
+// should keep an object's TLE (by Digest) as long as reports that
+// refer to it are active.
+
+// CatalogEvent types.
+const (
+	// CatalogNew announces an object (a CatNum and Publisher) that
+	// reports can refer to.
+	CatalogNew = "new"
+
+	// CatalogUpdated announces a new TLE for an object.
+	CatalogUpdated = "updated"
+
+	// CatalogRetired announces that reports will no longer refer to
+	// an object (say due to a publisher policy).
+	CatalogRetired = "retired"
+)
+
+// CatalogEvent is a change in the set of objects that reports can
+// refer to.
+type CatalogEvent struct {
+	// Event is CatalogNew, CatalogUpdated, or CatalogRetired.
+	Event string
+
+	// Time is the (real) time of the event.
+	Time time.Time
+
+	// Name is the object's Name().
+	Name string
+
+	// Publisher is the publisher of the TLE.
+	Publisher string `json:",omitempty"`
+
+	// Digest is the TLE's digest (see PubTLE.Digest).  For a
+	// retired object, Digest is that of the last announced TLE.
+	Digest string
+
+	// Obj is the TLE, which is nil for a retired object.
+	Obj *tle.SGP4TLE `json:",omitempty"`
+}
+
+// Digest returns a digest of the TLE's lines, which identifies the
+// TLE in compact reports.
+func (p *PubTLE) Digest() string {
+	return misc.SHA([]byte(strings.Join(p.TLE.TLE, "\n")))
+}
+
+// CatNum returns the object's catalog number, which comes from the
+// Name in a compact report.
+func (s *State) CatNum() string {
+	if s.Obj != nil {
+		return s.Obj.CatNum
+	}
+	if i := strings.Index(s.Name, "/"); 0 <= i {
+		return s.Name[:i]
+	}
+	return s.Name
+}
+
+// announce sends CatalogEvents for the given changes to the set of
+// candidates.
+func (n *Node) announce(ctx context.Context, work, retired map[index.Key]*IndexInput) {
+	var (
+		now = time.Now().UTC()
+		acc = make([]*CatalogEvent, 0, len(work)+len(retired))
+	)
+
+	for k, ii := range work {
+		if ii.retire {
+			continue
+		}
+		var (
+			digest    = ii.Sat.Digest()
+			prev, was = n.catalog[k]
+			event     = CatalogNew
+		)
+		if was {
+			if prev == digest {
+				continue
+			}
+			event = CatalogUpdated
+		}
+		n.catalog[k] = digest
+		acc = append(acc, &CatalogEvent{
+			Event:     event,
+			Time:      now,
+			Name:      ii.Sat.Name(),
+			Publisher: ii.Sat.Publisher,
+			Digest:    digest,
+			Obj:       ii.Sat.TLE,
+		})
+	}
+
+	for k, ii := range retired {
+		digest, was := n.catalog[k]
+		if !was {
+			continue
+		}
+		delete(n.catalog, k)
+		acc = append(acc, &CatalogEvent{
+			Event:     CatalogRetired,
+			Time:      now,
+			Name:      ii.Sat.Name(),
+			Publisher: ii.Sat.Publisher,
+			Digest:    digest,
+		})
+	}
+
+	if len(acc) == 0 {
+		return
+	}
+
+	sort.Slice(acc, func(i, j int) bool {
+		return acc[i].Name < acc[j].Name
+	})
+
+	select {
+	case <-ctx.Done():
+	case n.Catalog <- acc:
+	}
+}
@@ -0,0 +1,84 @@
+package node
+
+import (
+	"testing"
+)
+
+// catalogEvents drains the Node's Catalog channel, and it returns
+// counts by event type.
+func catalogEvents(n *Node) map[string]int {
+	acc := make(map[string]int)
+	for {
+		select {
+		case es := <-n.Catalog:
+			for _, e := range es {
+				acc[e.Event]++
+			}
+		default:
+			return acc
+		}
+	}
+}
+
+func TestCompactReports(t *testing.T) {
+	var (
+		a = testTLEs(t, "a", 3)
+		n = testNode()
+
+		digests = make(map[string]string)
+	)
+	for _, sat := range a {
+		digests[sat.Name()] = sat.Digest()
+	}
+
+	n.CompactReports = true
+	n.Catalog = make(chan []*CatalogEvent, 16)
+
+	rs := testSlices(t, n, testEpoch, a)
+	if len(rs) == 0 {
+		t.Fatal("no reports")
+	}
+	for _, r := range rs {
+		if r.Schema != ReportSchema {
+			t.Fatalf("schema %d", r.Schema)
+		}
+		for _, s := range r.Objs {
+			if s.Obj != nil {
+				t.Fatal("compact report has a TLE")
+			}
+			if s.Digest != digests[s.Name] {
+				t.Fatalf("%s digest %s", s.Name, s.Digest)
+			}
+			if s.CatNum() == "" || s.CatNum() != s.Name[:5] {
+				t.Fatalf("%s catnum %s", s.Name, s.CatNum())
+			}
+		}
+	}
+
+	if es := catalogEvents(n); es[CatalogNew] != len(a) || len(es) != 1 {
+		t.Fatalf("events: %v", es)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	var (
+		a  = testTLEs(t, "a", 3)
+		a2 = republish(t, a, "a", "20016.08400000")
+		b  = republish(t, a, "b", "20016.09000000")
+	)
+
+	n := testNode()
+	n.Catalog = make(chan []*CatalogEvent, 16)
+	testSlices(t, n, testEpoch, a, a, a2)
+	if es := catalogEvents(n); es[CatalogNew] != len(a) || es[CatalogUpdated] != len(a) || len(es) != 2 {
+		t.Fatalf("events: %v", es)
+	}
+
+	n = testNode()
+	n.Catalog = make(chan []*CatalogEvent, 16)
+	n.PublisherPolicy = FreshestPublisher
+	testSlices(t, n, testEpoch, a, b)
+	if es := catalogEvents(n); es[CatalogNew] != 2*len(a) || es[CatalogRetired] != len(a) || len(es) != 2 {
+		t.Fatalf("events: %v", es)
+	}
+}
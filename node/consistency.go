@@ -0,0 +1,114 @@
+package node
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ut-astria/spi/index"
+	"github.com/ut-astria/spi/sgp4"
+)
+
+// Consistency reports (see Cfg.Consistency) compare the views (live
+// TLEs from different publishers) of each object directly rather
+// than relying on the indexes, which can't see beyond IndexDist.
+//
+// Whenever a view of an object changes, each time slice compares
+// every pair of the object's views at its time.  Like overflights,
+// each slice remembers the Reports it made for each object, and it
+// cancels those that are no longer predicted.
+//
+// Objects that the pre-filters keep out of the indexes still get
+// compared (see IndexInput.unindexed).
+
+// views returns the live views, ordered by Publisher, of each object
+// with a key among the IndexInputs.  An object that this Node
+// shouldn't report (see OwnsObject and Cfg.WatchList) has no views,
+// which cancels any previous Reports.
+func (n *Node) views(iis map[index.Key]*IndexInput) map[index.CatalogNum][]*PubTLE {
+	if !n.Consistency {
+		return nil
+	}
+	acc := make(map[index.CatalogNum][]*PubTLE)
+	for k := range iis {
+		cat := k.CatalogNum
+		if _, have := acc[cat]; have {
+			continue
+		}
+		var (
+			keys    = make([]index.Key, 0, len(n.pubs[cat]))
+			watched = false
+		)
+		for k0 := range n.pubs[cat] {
+			keys = append(keys, k0)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].Publisher < keys[j].Publisher
+		})
+		ps := make([]*PubTLE, 0, len(keys))
+		for _, k0 := range keys {
+			p := n.live[k0].Sat
+			watched = watched || n.Watched(p)
+			ps = append(ps, p)
+		}
+		if !watched || !n.OwnsObject(ps[0].TLE.CatNum) {
+			ps = nil
+		}
+		acc[cat] = ps
+	}
+	return acc
+}
+
+// consistencyWork compares each pair of each object's views in the
+// slice at t, and it returns cancellations of previous consistency
+// Reports that are no longer predicted along with Reports that are
+// new.
+func (n *Node) consistencyWork(ctx context.Context, t time.Time, views map[index.CatalogNum][]*PubTLE) []*Report {
+	var acc []*Report
+	for cat, ps := range views {
+		var rs []*Report
+		for i, p0 := range ps {
+			for _, p1 := range ps[i+1:] {
+				r, err := n.consistencyReport(t, p0, p1)
+				if err != nil {
+					if !sgp4.HasDecayed(err) {
+						n.logf(ctx, "consistency %s: %s", p0.Name(), err)
+					}
+					continue
+				}
+				if r != nil {
+					rs = append(rs, r)
+				}
+			}
+		}
+		// The object's reports are kept under its CatalogNum.
+		cs, err := changes(n.consistencies.swap(t, index.Key{CatalogNum: cat}, rs), rs)
+		if err != nil {
+			n.logf(ctx, "consistency: %s", err)
+			continue
+		}
+		acc = append(acc, cs...)
+	}
+	return acc
+}
+
+// consistencyReport builds a consistency Report for two publishers'
+// views of the same object.
+//
+// The Report's Dist is the divergence between those views at the
+// given time.  No Report is generated when that divergence is less
+// than ConsistencyDist.
+func (n *Node) consistencyReport(t time.Time, o0, o1 *PubTLE) (*Report, error) {
+	d, es, then, err := ScanPair(false, t, time.Second, 100, o0.TLE, o1.TLE, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if d < n.ConsistencyDist {
+		return nil, nil
+	}
+
+	v := es[0].V.Dist(es[1].V)
+
+	return n.makeReport(t, then, d, v, es, o0, o1, ConsistencyReport, false)
+}
@@ -0,0 +1,137 @@
+package node
+
+import (
+	"testing"
+)
+
+func TestConsistency(t *testing.T) {
+	var (
+		a = testTLEs(t, "a", 3)
+		// The same elements about 60 ms later: about 0.5 km apart.
+		b = republish(t, a, "b", "20016.08333400")
+	)
+
+	kinds := func(rs []*Report) (conjs, cons int) {
+		for _, r := range rs {
+			if r.Kind != ConsistencyReport {
+				if r.Objs[0].Obj.CatNum == r.Objs[1].Obj.CatNum {
+					t.Fatalf("conjunction for %s", r.Objs[0].Obj.CatNum)
+				}
+				conjs++
+				continue
+			}
+			if r.Objs[0].Obj.CatNum != r.Objs[1].Obj.CatNum {
+				t.Fatalf("consistency for %s and %s", r.Objs[0].Obj.CatNum, r.Objs[1].Obj.CatNum)
+			}
+			if r.Objs[0].Publisher == r.Objs[1].Publisher {
+				t.Fatalf("consistency for publisher %s", r.Objs[0].Publisher)
+			}
+			if r.Dist < 0.1 || 1 < r.Dist {
+				t.Fatalf("consistency dist %f", r.Dist)
+			}
+			cons++
+		}
+		return
+	}
+
+	t.Run("off", func(t *testing.T) {
+		conjs, cons := kinds(active(testSlices(t, testNode(), testEpoch, a, b)))
+		if conjs == 0 || cons != 0 {
+			t.Fatalf("conjs: %d, consistency: %d", conjs, cons)
+		}
+	})
+
+	t.Run("on", func(t *testing.T) {
+		n := testNode()
+		n.Consistency = true
+		n.ConsistencyDist = 0.1
+		conjs, cons := kinds(active(testSlices(t, n, testEpoch, a, b)))
+		if conjs == 0 || cons < len(a) {
+			t.Fatalf("conjs: %d, consistency: %d", conjs, cons)
+		}
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		n := testNode()
+		n.Consistency = true
+		n.ConsistencyDist = 10
+		if _, cons := kinds(active(testSlices(t, n, testEpoch, a, b))); cons != 0 {
+			t.Fatalf("consistency: %d", cons)
+		}
+	})
+}
+
+func TestConsistencyFiltered(t *testing.T) {
+	var (
+		// One object, so the only partners are other views.
+		a = testTLEs(t, "a", 1)
+		b = republish(t, a, "b", "20016.08333400")
+		n = testNode()
+	)
+	n.Consistency = true
+	n.ConsistencyDist = 0.1
+	n.FilterApsides = true
+
+	rs := active(testSlices(t, n, testEpoch, a, b))
+	if len(rs) == 0 {
+		t.Fatal("no consistency reports")
+	}
+	for _, r := range rs {
+		if r.Kind != ConsistencyReport {
+			t.Fatalf("kind %q", r.Kind)
+		}
+	}
+}
+
+func TestConsistencyDistant(t *testing.T) {
+	var (
+		a = testTLEs(t, "a", 3)
+		// About 12 seconds later: far beyond IndexDist.
+		far = republish(t, a, "b", "20016.08347000")
+		// A correction from the same publisher.
+		near = republish(t, a, "b", "20016.08333400")
+		n    = testNode()
+	)
+	n.Consistency = true
+	n.ConsistencyDist = 10
+
+	count := func(rs []*Report) int {
+		cons := 0
+		for _, r := range rs {
+			if r.Kind != ConsistencyReport {
+				continue
+			}
+			if r.Dist < n.indexDist() {
+				t.Fatalf("consistency dist %f", r.Dist)
+			}
+			cons++
+		}
+		return cons
+	}
+
+	if cons := count(active(testSlices(t, n, testEpoch, a, far))); cons != len(a)*n.Horizon {
+		t.Fatalf("consistency: %d", cons)
+	}
+
+	n = testNode()
+	n.Consistency = true
+	n.ConsistencyDist = 10
+	if cons := count(active(testSlices(t, n, testEpoch, a, far, near))); cons != 0 {
+		t.Fatalf("consistency after the correction: %d", cons)
+	}
+}
+
+func TestConsistencyCheck(t *testing.T) {
+	for _, policy := range []string{"", AllPublishers} {
+		c := Cfg{Consistency: true, PublisherPolicy: policy}
+		if err := c.Check(); err != nil {
+			t.Fatalf("%q: %s", policy, err)
+		}
+	}
+	for _, policy := range []string{FreshestPublisher, PriorityPublisher} {
+		c := Cfg{Consistency: true, PublisherPolicy: policy}
+		if err := c.Check(); err == nil {
+			t.Fatalf("expected an error for %q", policy)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package node
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/ut-astria/spi/index"
+	"github.com/ut-astria/spi/tle"
+)
+
+// Orbit is a summary of an orbit that supports cheap screening of
+// pairs of objects that cannot approach each other.
+//
+// Distances are in km, and angles are in radians.
+type Orbit struct {
+	// Perigee and Apogee are radii (not altitudes).
+	Perigee, Apogee float64
+
+	// P is the semi-latus rectum.
+	P float64
+
+	// E is the eccentricity.
+	E float64
+
+	// H is the unit normal to the orbital plane.
+	H [3]float64
+
+	// U is the unit vector toward perigee, and V completes the
+	// in-plane basis (V = H x U).
+	U, V [3]float64
+
+	// Drift is the J2 secular rate (rad/s) of the RAAN plus that of
+	// the argument of perigee (absolute values).
+	Drift float64
+}
+
+// Earth constants (WGS-72) used for J2 drift estimates.
+const (
+	earthRadius = 6378.135
+	earthMu     = 398600.8
+	earthJ2     = 0.001082616
+)
+
+// NewOrbit makes an Orbit from mean elements: semi-major axis (km),
+// eccentricity, and inclination, RAAN, and argument of perigee (all
+// degrees).
+func NewOrbit(a, e, incl, raan, argp float64) *Orbit {
+	var (
+		rad = math.Pi / 180
+
+		si, ci = math.Sincos(incl * rad)
+		so, co = math.Sincos(raan * rad)
+		sw, cw = math.Sincos(argp * rad)
+
+		p = a * (1 - e*e)
+		n = math.Sqrt(earthMu / (a * a * a))
+		k = n * earthJ2 * (earthRadius / p) * (earthRadius / p)
+
+		raanDot = -1.5 * k * ci
+		argpDot = 0.75 * k * (5*ci*ci - 1)
+	)
+
+	return &Orbit{
+		Perigee: a * (1 - e),
+		Apogee:  a * (1 + e),
+		P:       p,
+		E:       e,
+		H:       [3]float64{si * so, -si * co, ci},
+		U: [3]float64{
+			co*cw - so*sw*ci,
+			so*cw + co*sw*ci,
+			sw * si,
+		},
+		V: [3]float64{
+			-co*sw - so*cw*ci,
+			-so*sw + co*cw*ci,
+			cw * si,
+		},
+		Drift: math.Abs(raanDot) + math.Abs(argpDot),
+	}
+}
+
+// OrbitOf makes an Orbit for the given TLE.
+func OrbitOf(o *tle.SGP4TLE) *Orbit {
+	return NewOrbit(o.SemiMajorAxis(), o.Eccentricity(),
+		o.Inclination(), o.RAAN(), o.ArgPerigee())
+}
+
+// ApsidesFilter is the classic apogee/perigee filter.  It returns
+// false if the two orbits' radial bands are separated by more than
+// d.
+func ApsidesFilter(a, b *Orbit, d float64) bool {
+	var (
+		q = math.Max(a.Perigee, b.Perigee)
+		Q = math.Min(a.Apogee, b.Apogee)
+	)
+	return q-Q <= d
+}
+
+// PathFilter is an orbit-path (geometric) filter.  It returns false
+// if the two orbits cannot come within d of each other during the
+// given horizon.
+//
+// Two points (one on each orbit) can only be within d if each is
+// within d of the other orbit's plane, and that constraint confines
+// both points to windows around the planes' mutual line of nodes.
+// If, at both nodes, the ranges of radii the orbits take within those
+// windows are separated by more than d, then the pair is rejected.
+// The windows are widened by the J2 drift of the planes and apsides
+// over the horizon.
+//
+// Nearly coplanar orbits are never rejected.
+func PathFilter(a, b *Orbit, d float64, horizon time.Duration) bool {
+	var (
+		n    = cross(a.H, b.H)
+		sinI = norm(n)
+	)
+
+	if sinI < 1e-6 {
+		return true
+	}
+
+	var (
+		drift = (a.Drift + b.Drift) * horizon.Seconds()
+		wa    = d / (a.Perigee * sinI)
+		wb    = d / (b.Perigee * sinI)
+	)
+
+	if 1 <= wa || 1 <= wb {
+		return true
+	}
+
+	var (
+		da = math.Asin(wa) + drift
+		db = math.Asin(wb) + drift
+	)
+
+	if math.Pi/2 <= da || math.Pi/2 <= db {
+		return true
+	}
+
+	for _, sign := range []float64{1, -1} {
+		node := [3]float64{
+			sign * n[0] / sinI,
+			sign * n[1] / sinI,
+			sign * n[2] / sinI,
+		}
+		var (
+			aMin, aMax = a.radii(a.anomaly(node), da)
+			bMin, bMax = b.radii(b.anomaly(node), db)
+		)
+		if aMin-bMax <= d && bMin-aMax <= d {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anomaly returns the true anomaly of the given in-plane direction.
+func (o *Orbit) anomaly(x [3]float64) float64 {
+	return math.Atan2(dot(x, o.V), dot(x, o.U))
+}
+
+// radii returns the minimum and maximum radii for true anomalies
+// within w of nu.
+func (o *Orbit) radii(nu, w float64) (float64, float64) {
+	var (
+		r = func(nu float64) float64 {
+			return o.P / (1 + o.E*math.Cos(nu))
+		}
+		r0 = r(nu - w)
+		r1 = r(nu + w)
+
+		min = math.Min(r0, r1)
+		max = math.Max(r0, r1)
+
+		contains = func(x float64) bool {
+			// Is x in [nu-w,nu+w] (mod 2pi)?
+			d := math.Remainder(x-nu, 2*math.Pi)
+			return math.Abs(d) <= w
+		}
+	)
+
+	if contains(0) {
+		min = o.Perigee
+	}
+	if contains(math.Pi) {
+		max = o.Apogee
+	}
+
+	return min, max
+}
+
+// bandWidth is the width (km) of the radial bands that index the
+// candidates' Orbits for screen.
+const bandWidth = 100
+
+// band returns the radial band containing the radius r.
+func band(r float64) int {
+	return int(math.Floor(r / bandWidth))
+}
+
+// addOrbit remembers the candidate's Orbit, and it adds the key to
+// each band between the Orbit's perigee and apogee.  A previous
+// Orbit for the key is forgotten.
+func (n *Node) addOrbit(k index.Key, o *Orbit) {
+	n.forgetOrbit(k)
+	n.orbits[k] = o
+	for b := band(o.Perigee); b <= band(o.Apogee); b++ {
+		keys, have := n.bands[b]
+		if !have {
+			keys = make(map[index.Key]bool)
+			n.bands[b] = keys
+		}
+		keys[k] = true
+	}
+}
+
+// forgetOrbit removes the key's Orbit (if any) and the key's bands.
+func (n *Node) forgetOrbit(k index.Key) {
+	o, have := n.orbits[k]
+	if !have {
+		return
+	}
+	delete(n.orbits, k)
+	for b := band(o.Perigee); b <= band(o.Apogee); b++ {
+		delete(n.bands[b], k)
+		if len(n.bands[b]) == 0 {
+			delete(n.bands, b)
+		}
+	}
+}
+
+// nearBands calls f for each candidate (other than the given key)
+// whose radial band is within d of the Orbit's, until f returns
+// false.  Objects with bands separated by more than d can never be
+// within d of each other, so the others aren't possible partners
+// according to any pre-filter.
+func (n *Node) nearBands(k index.Key, o *Orbit, d float64, f func(index.Key) bool) {
+	seen := make(map[index.Key]bool)
+	for b := band(o.Perigee - d); b <= band(o.Apogee+d); b++ {
+		for k0 := range n.bands[b] {
+			if k0 == k || seen[k0] {
+				continue
+			}
+			seen[k0] = true
+			if !ApsidesFilter(o, n.orbits[k0], d) {
+				continue
+			}
+			if !f(k0) {
+				return
+			}
+		}
+	}
+}
+
+// filtering reports whether any pre-filter is on.
+func (n *Node) filtering() bool {
+	return n.FilterApsides || n.FilterPath
+}
+
+// indexable returns the live TLEs that should be submitted to a new
+// index.
+func (n *Node) indexable() map[index.Key]*IndexInput {
+	if n.filtering() {
+		return n.indexed
+	}
+	return n.candidates()
+}
+
+// direct reports whether a live key needs processing in each slice
+// even when the pre-filters keep it out of the indexes.  That's the
+// case for a watched object that this Node owns when reporting
+// overflights and for an object with more than one view when
+// reporting Consistency.
+func (n *Node) direct(ii *IndexInput) bool {
+	if n.overflighting() && n.Watched(ii.Sat) && n.OwnsObject(ii.Sat.TLE.CatNum) {
+		return true
+	}
+	return n.Consistency && 1 < len(n.pubs[ii.Key.CatalogNum])
+}
+
+// withUnindexed returns the IndexInputs along with an unindexed
+// IndexInput (see IndexInput.unindexed) for each of the given
+// candidates that isn't among them but needs direct processing.
+//
+// The given maps aren't modified.
+func (n *Node) withUnindexed(iis, candidates map[index.Key]*IndexInput) map[index.Key]*IndexInput {
+	if !n.filtering() {
+		return iis
+	}
+	var acc map[index.Key]*IndexInput
+	for k, ii := range candidates {
+		if _, have := iis[k]; have || !n.direct(ii) {
+			continue
+		}
+		if acc == nil {
+			acc = make(map[index.Key]*IndexInput, len(iis)+1)
+			for k0, ii0 := range iis {
+				acc[k0] = ii0
+			}
+		}
+		acc[k] = &IndexInput{
+			Id:        ii.Id,
+			Key:       k,
+			Sat:       ii.Sat,
+			unindexed: true,
+		}
+	}
+	if acc == nil {
+		return iis
+	}
+	return acc
+}
+
+// possible reports whether the pre-filters allow the given orbits to
+// approach within d during horizon h.
+func (n *Node) possible(a, b *Orbit, d float64, h time.Duration) bool {
+	if n.FilterApsides && !ApsidesFilter(a, b, d) {
+		return false
+	}
+	if n.FilterPath && !PathFilter(a, b, d, h) {
+		return false
+	}
+	return true
+}
+
+// screen applies the pre-filters to new IndexInputs (which must
+// already be candidates).
+//
+// The returned IndexInputs should be submitted to indexes.  They are
+// the new inputs that have a possible partner, the partners
+// themselves if they weren't already indexed, and retirements of
+// previously indexed keys that no longer have any possible partner.
+//
+// Keys sharing a CatalogNum are never partners since indexes never
+// report them.
+//
+// Only the candidates in nearby radial bands (see nearBands) are
+// considered, so screening a new TLE doesn't cost a pass over the
+// whole catalog.
+func (n *Node) screen(ctx context.Context, iis map[index.Key]*IndexInput) map[index.Key]*IndexInput {
+	var (
+		acc = make(map[index.Key]*IndexInput, len(iis))
+		d   = float64(n.indexDist() + n.FilterPad)
+		h   = time.Duration(n.Horizon) * n.Resolution
+	)
+
+	for k, ii := range iis {
+		n.addOrbit(k, OrbitOf(ii.Sat.TLE))
+	}
+
+	candidates := n.candidates()
+
+	for k, ii := range iis {
+		var (
+			o         = n.orbits[k]
+			partnered = false
+		)
+		n.nearBands(k, o, d, func(k0 index.Key) bool {
+			if k0.CatalogNum == k.CatalogNum {
+				return true
+			}
+			_, have := n.indexed[k0]
+			if partnered && have {
+				// Nothing more to learn.
+				return true
+			}
+			if !n.possible(o, n.orbits[k0], d, h) {
+				return true
+			}
+			partnered = true
+			if !have {
+				ii0 := candidates[k0]
+				n.indexed[k0] = ii0
+				acc[k0] = ii0
+			}
+			return true
+		})
+
+		if partnered {
+			n.indexed[k] = ii
+			acc[k] = ii
+		} else if _, have := n.indexed[k]; have {
+			delete(n.indexed, k)
+			acc[k] = &IndexInput{
+				Id:        ii.Id,
+				Key:       k,
+				Sat:       ii.Sat,
+				retire:    true,
+				unindexed: n.direct(ii),
+			}
+		}
+	}
+
+	n.logf(ctx, "Screened %d new TLEs: %d to index (%d indexed of %d candidates)",
+		len(iis), len(acc), len(n.indexed), len(n.candidates()))
+
+	return acc
+}
+
+func cross(a, b [3]float64) [3]float64 {
+	return [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func dot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func norm(a [3]float64) float64 {
+	return math.Sqrt(dot(a, a))
+}
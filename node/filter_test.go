@@ -0,0 +1,144 @@
+package node
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/tle"
+)
+
+func TestApsidesFilter(t *testing.T) {
+	var (
+		leo  = NewOrbit(6778, 0.001, 51.6, 0, 0)
+		leo2 = NewOrbit(6800, 0.001, 97.5, 120, 30)
+		geo  = NewOrbit(42164, 0.0002, 0.1, 0, 0)
+		d    = 75.0
+	)
+
+	if !ApsidesFilter(leo, leo2, d) {
+		t.Fatal("leo/leo2 should be possible")
+	}
+	if ApsidesFilter(leo, geo, d) {
+		t.Fatal("leo/geo should be impossible")
+	}
+	if ApsidesFilter(geo, leo, d) {
+		t.Fatal("geo/leo should be impossible")
+	}
+}
+
+func TestPathFilter(t *testing.T) {
+	var (
+		d = 75.0
+		h = time.Hour
+
+		// A circular orbit with radius 7000 km.
+		a = NewOrbit(7000, 0, 10, 0, 0)
+
+		// Eccentric orbits that cross radius 7000 km but in
+		// a different plane.  Where they cross depends on the
+		// argument of perigee.
+		e    = 0.2
+		p    = 7000 * (1 - e*e)
+		nu   = math.Acos((p/7000-1)/e) * 180 / math.Pi
+		miss = NewOrbit(7000, e, 60, 0, 0)
+		hit  = NewOrbit(7000, e, 60, 0, -nu)
+	)
+
+	if !ApsidesFilter(a, miss, d) {
+		t.Fatal("apsides filter should pass")
+	}
+
+	if PathFilter(a, miss, d, h) {
+		t.Fatal("miss should be impossible")
+	}
+
+	if !PathFilter(a, hit, d, h) {
+		t.Fatal("hit should be possible")
+	}
+
+	if !PathFilter(miss, hit, d, h) {
+		t.Fatal("coplanar orbits should be possible")
+	}
+
+	// A very long horizon lets the nodes drift anywhere.
+	if !PathFilter(a, miss, d, 365*24*time.Hour) {
+		t.Fatal("drift should make miss possible")
+	}
+}
+
+func TestScreen(t *testing.T) {
+	var (
+		motions = []string{"15.07452065", "14.20000000", "13.07452065", "02.00563000", "01.00273000"}
+		sats    = make([]*PubTLE, 0, 40)
+	)
+	for i := 0; i < cap(sats); i++ {
+		var (
+			line1 = fmt.Sprintf("1 %05dU 20001A   20016.08333333  .00000000  00000+0  00000+0 0    07", 60000+i)
+			line2 = fmt.Sprintf("2 %05d 064.8760 %8.4f 0036285 284.0373 175.5769 %s    00", 60000+i, 9.0*float64(i), motions[i%len(motions)])
+		)
+		if i%7 == 0 {
+			// A lone orbit.
+			line2 = line2[:52] + fmt.Sprintf("%11.8f", 11+0.1*float64(i)) + line2[63:]
+		}
+		p, err := tle.NewSGP4TLE(fmt.Sprintf("TEST %d", i), line1, line2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sats = append(sats, &PubTLE{
+			Publisher: "a",
+			TLE:       p.(*tle.SGP4TLE),
+		})
+	}
+
+	n := testNode()
+	n.FilterApsides = true
+	testSlices(t, n, testEpoch, sats[:25], sats[25:])
+
+	// Brute force.
+	var (
+		d    = float64(n.indexDist() + n.FilterPad)
+		want = 0
+	)
+	for k, ii := range n.candidates() {
+		partnered := false
+		for k0 := range n.candidates() {
+			if k0.CatalogNum != k.CatalogNum && ApsidesFilter(n.orbits[k], n.orbits[k0], d) {
+				partnered = true
+				break
+			}
+		}
+		if _, have := n.indexed[k]; have != partnered {
+			t.Fatalf("%s indexed: %v", ii.Sat.Name(), have)
+		}
+		if partnered {
+			want++
+		}
+	}
+	if want == 0 || want == len(sats) {
+		t.Fatalf("%d of %d partnered", want, len(sats))
+	}
+
+	t.Run("retired", func(t *testing.T) {
+		var (
+			a = testTLEs(t, "a", 3)
+			b = republish(t, a, "b", "20016.08334000")
+			n = testNode()
+		)
+		n.FilterApsides = true
+		n.PublisherPolicy = FreshestPublisher
+		testSlices(t, n, testEpoch, a, b)
+
+		if len(n.orbits) != len(b) {
+			t.Fatalf("%d orbits", len(n.orbits))
+		}
+		for _, keys := range n.bands {
+			for k := range keys {
+				if n.live[k].Sat.Publisher != "b" {
+					t.Fatalf("band has %s", n.live[k].Sat.Name())
+				}
+			}
+		}
+	})
+}
@@ -22,8 +22,11 @@ func TimeToGST(t time.Time) (float64, float64) {
 	return sat.GSTimeFromDateNano(y, m, d, h, min, sec, ns)
 }
 
+// LatLonAlt is a geodetic position.
 type LatLonAlt struct {
-	Lat, Lon, Alt float32
+	Lat float32 `units:"deg"`
+	Lon float32 `units:"deg"`
+	Alt float32 `units:"km"`
 }
 
 func ECIToLLA(t time.Time, p prop.Vect) (*LatLonAlt, error) {
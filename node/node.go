@@ -24,6 +24,7 @@ var DefaultCfg = &Cfg{
 	Scan:                true,
 	SlowSample:          10,
 	SlowSampleThreshold: 0.1,
+	FilterPad:           25,
 }
 
 // Cfg is a Node configuration.
@@ -45,8 +46,16 @@ type Cfg struct {
 	ScanDist float32
 
 	// IndexDist is indexing's maximum distance for emitting a report.
+	//
+	// Indexes use a larger distance if required to contain every
+	// ScreeningVolume.
 	IndexDist float32
 
+	// ScreeningVolumes, when one applies to a pair, replace
+	// ScanDist's sphere with a box or ellipsoid in the primary's
+	// RIC frame.  The first applicable volume is used.
+	ScreeningVolumes []ScreeningVolume `json:",omitempty"`
+
 	// IndexLevel is the S2 Cell level for all indexes.
 	IndexLevel int
 
@@ -54,10 +63,102 @@ type Cfg struct {
 	// less than SlowSampleThreshold.
 	SlowSample int
 
-	// SlowSampleThreshold is the maximum relative speed for
+	// SlowSampleThreshold is the maximum relative speed (km/s) for
 	// sampling to occur.
 	SlowSampleThreshold float32
 
+	// FilterApsides turns on the apogee/perigee pre-filter.
+	//
+	// When a pre-filter is on, objects that cannot approach any
+	// other live object within IndexDist+FilterPad are not
+	// submitted to indexes.
+	FilterApsides bool
+
+	// FilterPath turns on the orbit-path pre-filter.
+	FilterPath bool
+
+	// FilterPad is added to IndexDist (km) for pre-filtering in
+	// order to tolerate differences between mean elements and
+	// propagated positions.
+	FilterPad float32
+
+	// SGP4, if not nil, gives the SGP4 options (gravity model and
+	// operation mode) for in-coming TLEs that don't specify their
+	// own.
+	SGP4 *sgp4.Options `json:",omitempty"`
+
+	// PublisherPolicy determines which publishers' TLEs for an
+	// object are indexed: AllPublishers (the default),
+	// FreshestPublisher, or PriorityPublisher.
+	PublisherPolicy string
+
+	// PublisherPriority lists publishers from highest to lowest
+	// priority for PriorityPublisher.
+	PublisherPriority []string
+
+	// CrossCheck, if positive, is the distance (km) beyond which
+	// the positions given by different publishers for the same
+	// object result in a warning.
+	CrossCheck float32
+
+	// Consistency enables reports (with Kind ConsistencyReport)
+	// when different publishers' TLEs for the same object diverge
+	// by at least ConsistencyDist.  Requires AllPublishers.
+	//
+	// Each time slice compares every pair of views of an object
+	// directly (without the index), so divergence of any size is
+	// reported throughout the horizon.
+	Consistency bool `json:",omitempty"`
+
+	// ConsistencyDist is the minimum divergence (km) for a
+	// consistency report.
+	ConsistencyDist float32 `json:",omitempty"`
+
+	// WatchList, if not nil, restricts reports to pairs that
+	// involve at least one watched object.  This list is the
+	// initial one; see Node.Watches for runtime updates.
+	WatchList *WatchList `json:",omitempty"`
+
+	// ROIs, if not empty, are regions of interest for overflight
+	// reports (with Kind OverflightReport) for watched objects.
+	// See ReadROIs.
+	ROIs []*ROI `json:",omitempty"`
+
+	// Shards is the number of nodes in a cluster that share the
+	// processing horizon.  Each Node instantiates only the time
+	// slices it owns (see Owns).  Zero or one means no sharding.
+	Shards int
+
+	// Shard is this Node's shard (in [0,Shards)).
+	Shard int
+
+	// Partitions is the number of catalog partitions in a
+	// cluster.  Each Node owns the objects in its Partition, and it
+	// only reports pairs whose primary (lesser catalog number) it
+	// owns.  Zero or one means no partitioning.
+	//
+	// Unlike -sample-mod for spipipe, which drops input, every Node
+	// still needs to receive all TLEs.
+	Partitions int
+
+	// Partition is this Node's partition (in [0,Partitions)).
+	Partition int
+
+	// CompactReports, when true, omits TLEs from reports, which
+	// refer to their objects by Name and Digest instead.  See
+	// Node.Catalog.
+	CompactReports bool `json:",omitempty"`
+
+	// ShadowModel (ConicalShadow or CylindricalShadow) determines
+	// the eclipse state in the Lighting of each report's States.
+	// The empty string is ConicalShadow.
+	ShadowModel string `json:",omitempty"`
+
+	// PropWorkers is the number of goroutines used to propagate
+	// the live set when filling a new time slice.  Zero means
+	// runtime.NumCPU().
+	PropWorkers int
+
 	// Logging turns on logging, which uses log.Printf.
 	Logging bool
 }
@@ -75,6 +176,14 @@ type Metrics struct {
 	// Live is the number of live TLEs.
 	Live int
 
+	// Indexed is the number of live TLEs submitted to indexes,
+	// which is less than Live when pre-filters are on.
+	Indexed int
+
+	// Slices is the number of time slices this Node owns
+	// currently.
+	Slices int
+
 	// Goroutines is the current number of goroutines.
 	Goroutines int
 
@@ -113,12 +222,31 @@ type Node struct {
 	// Out produces the emitted reports.
 	Out chan []*Report
 
+	// Catalog, if not nil, receives CatalogEvents for changes to
+	// the set of objects that reports can refer to.  Events are
+	// sent before any reports that refer to them.
+	Catalog chan []*CatalogEvent
+
+	// Hub, if not nil, receives the emitted reports instead of Out.
+	// Each report is published once, and the Hub's Subscriptions
+	// filter and buffer them independently.
+	Hub *Hub
+
 	// Errs, if not null, produces (asynchronous) errors.
 	Errs chan error
 
 	// Metrics emits Metrics.
 	Metrics chan Metrics
 
+	// Processed, if not nil, receives each batch from In after Run
+	// has processed it.  By then, all reports resulting from that
+	// batch have been sent to Out.
+	Processed chan []*PubTLE
+
+	// Watches, if not nil, receives replacement watch lists
+	// (see Cfg.WatchList).  A nil WatchList watches everything.
+	Watches chan *WatchList
+
 	// TimeOffset is the difference between real time and logical time.
 	TimeOffset time.Duration
 
@@ -133,6 +261,51 @@ type Node struct {
 	// live the current set of TLEs, which are indexed by their
 	// index.Keys.
 	live map[index.Key]*IndexInput
+
+	// indexed is the subset of live that passed the pre-filters.
+	indexed map[index.Key]*IndexInput
+
+	// orbits has the Orbit for each candidate key when
+	// pre-filtering.
+	orbits map[index.Key]*Orbit
+
+	// bands has the candidate keys in each radial band (see
+	// addOrbit) when pre-filtering.
+	bands map[int]map[index.Key]bool
+
+	// pubs has the live keys for each CatalogNum when using a
+	// publisher policy, cross-checking, or Consistency.
+	pubs map[index.CatalogNum]map[index.Key]bool
+
+	// chosen is the selected key for each CatalogNum when using a
+	// publisher policy.
+	chosen map[index.CatalogNum]index.Key
+
+	// selected is the subset of live chosen by the publisher
+	// policy.
+	selected map[index.Key]*IndexInput
+
+	// watcher is the compiled WatchList (if any).
+	watcher *watcher
+
+	// catalog has the Digest for each key announced via Catalog.
+	catalog map[index.Key]string
+
+	// overflights has the overflight Reports for each time slice.
+	overflights *sliceReports
+
+	// consistencies has the consistency Reports for each time
+	// slice.
+	consistencies *sliceReports
+
+	// screenings receives requests from Screen.
+	screenings chan *screening
+
+	// submissions receives batches from Submit.
+	submissions chan *submission
+
+	// configs receives requests from Config.
+	configs chan chan Cfg
 }
 
 // NewNode makes a new Node, with cfg defaulting to DefaultCfg.
@@ -143,19 +316,61 @@ func NewNode(cfg *Cfg) *Node {
 		cfg = DefaultCfg
 	}
 	return &Node{
-		Cfg:     *cfg,
-		In:      make(chan []*PubTLE),
-		Out:     make(chan []*Report),
-		Errs:    nil,
-		interns: NewInterns(),
-		live:    make(map[index.Key]*IndexInput),
+		Cfg:      *cfg,
+		In:       make(chan []*PubTLE),
+		Out:      make(chan []*Report),
+		Errs:     nil,
+		interns:  NewInterns(),
+		live:     make(map[index.Key]*IndexInput),
+		indexed:  make(map[index.Key]*IndexInput),
+		orbits:   make(map[index.Key]*Orbit),
+		bands:    make(map[int]map[index.Key]bool),
+		pubs:     make(map[index.CatalogNum]map[index.Key]bool),
+		chosen:   make(map[index.CatalogNum]index.Key),
+		selected: make(map[index.Key]*IndexInput),
+		catalog:  make(map[index.Key]string),
+
+		overflights:   newSliceReports(),
+		consistencies: newSliceReports(),
+		screenings:    make(chan *screening),
+		submissions:   make(chan *submission),
+		configs:       make(chan chan Cfg),
 	}
 }
 
-// Prepare initializes a few values required before using the Node.
+// Check returns an error for an invalid configuration.
+func (c *Cfg) Check() error {
+	if shards := c.Shards; c.Shard < 0 || (shards <= 1 && c.Shard != 0) || (1 < shards && shards <= c.Shard) {
+		return fmt.Errorf("shard %d not in [0,%d)", c.Shard, shards)
+	}
+	if parts := c.Partitions; c.Partition < 0 || (parts <= 1 && c.Partition != 0) || (1 < parts && parts <= c.Partition) {
+		return fmt.Errorf("partition %d not in [0,%d)", c.Partition, parts)
+	}
+	switch c.PublisherPolicy {
+	case "", AllPublishers, FreshestPublisher, PriorityPublisher:
+	default:
+		return fmt.Errorf("unknown publisher policy %q", c.PublisherPolicy)
+	}
+	if c.Consistency && c.PublisherPolicy != "" && c.PublisherPolicy != AllPublishers {
+		return fmt.Errorf("consistency requires publisher policy %q (not %q)", AllPublishers, c.PublisherPolicy)
+	}
+	for k := range c.ScreeningVolumes {
+		if err := c.ScreeningVolumes[k].Check(); err != nil {
+			return fmt.Errorf("screening volume %d: %s", k, err)
+		}
+	}
+	return nil
+}
+
+// Prepare checks the configuration, and it initializes a few values
+// required before using the Node.
 //
 // Run calls Prepare if Finder is nil.
-func (n *Node) Prepare(ctx context.Context) {
+func (n *Node) Prepare(ctx context.Context) error {
+	if err := n.Cfg.Check(); err != nil {
+		return err
+	}
+
 	if n.T0.IsZero() {
 		n.T0 = time.Now().UTC()
 	}
@@ -163,6 +378,9 @@ func (n *Node) Prepare(ctx context.Context) {
 	n.T0 = RoundTime(n.T0.UTC(), n.Resolution)
 	n.TimeOffset = n.T0.Sub(time.Now())
 	n.Finder = index.NewCellFinder(n.IndexLevel)
+	n.watcher = n.WatchList.compile()
+
+	return nil
 }
 
 // Run executes the main event loop in the current goroutine.
@@ -173,25 +391,23 @@ func (n *Node) Prepare(ctx context.Context) {
 func (n *Node) Run(ctx context.Context) {
 
 	if n.Finder == nil {
-		n.Prepare(ctx)
+		if err := n.Prepare(ctx); err != nil {
+			n.errf(ctx, "Node.Run: %s", err)
+			return
+		}
 	}
 
 	var (
-		indexes = make(map[time.Time]*Index)
-		t0      = RoundTime(time.Now().UTC(), n.Resolution)
-		t1      = t0.Add(time.Duration(n.Horizon) * n.Resolution)
-		ticker  = time.NewTicker(n.Resolution)
+		t0     = RoundTime(time.Now().UTC(), n.Resolution)
+		t1     = t0.Add(time.Duration(n.Horizon) * n.Resolution)
+		ticker = time.NewTicker(n.Resolution)
+
+		indexes = n.NewIndexes(ctx, t0, t1)
 
 		inCount  = uint64(0)
 		inCount0 = inCount
 	)
 
-	for t := t0; t.Before(t1); t = t.Add(n.Resolution) {
-		i := n.NewIndex(t)
-		indexes[t] = i
-		go i.Run(ctx)
-	}
-
 LOOP:
 	for {
 		n.logf(ctx, "Node listening (ids:%d, indexes:%d)",
@@ -219,6 +435,8 @@ LOOP:
 					Goroutines: runtime.NumGoroutine(),
 					Strings:    n.interns.IdCount(),
 					Live:       len(n.live),
+					Indexed:    len(n.indexable()),
+					Slices:     len(indexes),
 				}
 				go func() {
 					select {
@@ -229,42 +447,132 @@ LOOP:
 
 			}
 
-			// Remove and terminate earliest index.
-			i := indexes[t0]
-			delete(indexes, t0)
-			i.Stop(ctx)
+			// Remove and terminate earliest index (if we
+			// own it).
+			if i, have := indexes[t0]; have {
+				delete(indexes, t0)
+				i.Stop(ctx)
+			}
+			n.overflights.forget(t0)
+			n.consistencies.forget(t0)
 			t0 = t0.Add(n.Resolution)
 
-			// Make the new index, and give it the live TLEs.
-			i = n.NewIndex(t1)
-			indexes[t1] = i
-			go i.Run(ctx)
-			n.logf(ctx, "Processing live sats (%d)", len(n.live))
-			n.process(ctx, map[time.Time]*Index{
-				t1: i,
-			}, n.live)
-			n.logf(ctx, "Processed live sats")
+			// Make the new index, and give it the live
+			// TLEs (if we own it).
+			if n.Owns(t1) {
+				i := n.NewIndex(t1)
+				indexes[t1] = i
+				go i.Run(ctx)
+				n.logf(ctx, "Processing live sats (%d)", len(n.live))
+				n.process(ctx, map[time.Time]*Index{
+					t1: i,
+				}, n.withUnindexed(n.indexable(), n.candidates()))
+				n.logf(ctx, "Processed live sats")
+			}
 
 			// Increment our virtual clock.
 			t1 = t1.Add(n.Resolution)
 
+		case wl := <-n.Watches:
+			if err := n.rewatch(ctx, indexes, wl); err != nil {
+				n.warnf(ctx, "rewatch: %s", err)
+			}
+
+		case s := <-n.screenings:
+			p, err := n.planScreen(indexes, s.req)
+			s.reply <- screened{p, err}
+
+		case c := <-n.configs:
+			c <- n.Cfg
+
+		case sub := <-n.submissions:
+			inCount += uint64(len(sub.sats))
+			n.ingest(ctx, sub.sats, indexes)
+			close(sub.done)
+
 		case sats := <-n.In:
-			// Process in-coming TLEs.
 			inCount += uint64(len(sats))
-			n.logf(ctx, "Processing %d new TLEs", len(sats))
-			n.processNew(ctx, sats, indexes)
-			n.logf(ctx, "Processed %d new TLEs", len(sats))
+			n.ingest(ctx, sats, indexes)
+			if n.Processed != nil {
+				select {
+				case <-ctx.Done():
+				case n.Processed <- sats:
+				}
+			}
 		}
 	}
 
 	n.logf(ctx, "Node.Run stopping")
 }
 
+// ingest processes in-coming TLEs.
+func (n *Node) ingest(ctx context.Context, sats []*PubTLE, indexes map[time.Time]*Index) {
+	n.logf(ctx, "Processing %d new TLEs", len(sats))
+	n.processNew(ctx, sats, indexes)
+	n.logf(ctx, "Processed %d new TLEs", len(sats))
+}
+
+// submission is a batch from Submit.
+type submission struct {
+	sats []*PubTLE
+	done chan bool
+}
+
+// Submit gives the batch to Run (like In), and it waits until Run has
+// processed it.  By then, all reports resulting from the batch have
+// been sent to Out.
+//
+// Unlike a batch from In, a submitted batch isn't sent to Processed.
+func (n *Node) Submit(ctx context.Context, sats []*PubTLE) error {
+	sub := &submission{
+		sats: sats,
+		done: make(chan bool),
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case n.submissions <- sub:
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-sub.done:
+		return nil
+	}
+}
+
+// Config returns a copy of the Node's configuration, which Run makes
+// (since Run can change it).
+func (n *Node) Config(ctx context.Context) (*Cfg, error) {
+	c := make(chan Cfg, 1)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case n.configs <- c:
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case cfg := <-c:
+		return &cfg, nil
+	}
+}
+
 // IndexInput represents (interned) data submitted to an Index.
 type IndexInput struct {
 	Id  index.Id
 	Key index.Key
 	Sat *PubTLE
+
+	// retire indicates that the Key's positions should be removed
+	// from the index.
+	retire bool
+
+	// unindexed indicates a live key that the pre-filters keep out
+	// of the index but that still needs processing in each slice
+	// (see direct).  With retire, the key's positions are removed
+	// from the index, but its processing continues.
+	unindexed bool
 }
 
 // NewIndexInput builds an IndexInput.
@@ -325,6 +633,10 @@ type IndexOutput struct {
 func (n *Node) processNew(ctx context.Context, sats []*PubTLE, is map[time.Time]*Index) error {
 	iis := make(map[index.Key]*IndexInput, len(sats))
 
+	if n.SGP4 != nil {
+		sats = n.applySGP4Options(ctx, sats)
+	}
+
 	// Make IndexInputs and store the new live TLEs while we're at
 	// it.
 	internWork := func(is *Interns) error {
@@ -342,9 +654,37 @@ func (n *Node) processNew(ctx context.Context, sats []*PubTLE, is map[time.Time]
 		return err
 	}
 
+	var (
+		work    = iis
+		retired map[index.Key]*IndexInput
+	)
+	if n.publishing() {
+		work, retired = n.selectPublishers(ctx, iis)
+	}
+	if n.Catalog != nil {
+		n.announce(ctx, work, retired)
+	}
+	if n.filtering() {
+		for k := range retired {
+			n.forgetOrbit(k)
+		}
+		work = n.withUnindexed(n.screen(ctx, work), iis)
+		for k := range retired {
+			// An unindexed key might still have overflights
+			// to cancel.
+			if _, have := n.indexed[k]; !have && !n.overflighting() {
+				delete(retired, k)
+			}
+			delete(n.indexed, k)
+		}
+	}
+	for k, ii := range retired {
+		work[k] = ii
+	}
+
 	// Submit input to all the tiven indexes, and wait for all of
 	// that work to complete.
-	if err := n.process(ctx, is, iis); err != nil {
+	if err := n.process(ctx, is, work); err != nil {
 		return err
 	}
 
@@ -362,6 +702,30 @@ func (n *Node) processNew(ctx context.Context, sats []*PubTLE, is map[time.Time]
 	return nil
 }
 
+// applySGP4Options re-parses TLEs that don't specify SGP4 options so
+// that they use the Node's options.
+//
+// The given PubTLEs are not modified.
+func (n *Node) applySGP4Options(ctx context.Context, sats []*PubTLE) []*PubTLE {
+	acc := make([]*PubTLE, 0, len(sats))
+	for _, sat := range sats {
+		if sat.TLE.Opts != nil {
+			acc = append(acc, sat)
+			continue
+		}
+		o, err := sat.TLE.WithOptions(*n.SGP4)
+		if err != nil {
+			n.warnf(ctx, "SGP4 options for %s: %s", sat.Name(), err)
+			continue
+		}
+		acc = append(acc, &PubTLE{
+			Publisher: sat.Publisher,
+			TLE:       o,
+		})
+	}
+	return acc
+}
+
 // IndexWork returns a function that performs the core index operation
 // and all subsequent processing.
 //
@@ -373,17 +737,77 @@ func (n *Node) processNew(ctx context.Context, sats []*PubTLE, is map[time.Time]
 // Most of the work after the core index processing runs in a new
 // goroutine.
 func (n *Node) IndexWork(ctx context.Context, iis map[index.Key]*IndexInput, f func([]*Report)) func(*index.Index, time.Time) {
+	return n.indexWork(ctx, iis, 1, f)
+}
+
+// indexWork is IndexWork with the given number of propagation
+// workers.
+func (n *Node) indexWork(ctx context.Context, iis map[index.Key]*IndexInput, workers int, f func([]*Report)) func(*index.Index, time.Time) {
+
+	var (
+		watched = n.activeKeys(iis)
+		views   = n.views(iis)
+	)
 
 	return func(i *index.Index, t time.Time) {
 
 		// This work will be done in the index's goroutine.
 
-		ios := make([]*IndexOutput, 0, len(iis))
+		var (
+			ios  = make([]*IndexOutput, 0, len(iis))
+			list = make([]*IndexInput, 0, len(iis))
+			ps   = make([]prop.Propagator, 0, len(iis))
+
+			// ors has the reports that don't come from the
+			// index: overflights and consistency.
+			ors     = []*Report{}
+			overfly = func(ii *IndexInput, e *prop.Ephemeris, predict bool) {
+				if !n.overflighting() {
+					return
+				}
+				rs, err := n.overflightWork(t, ii, e, predict)
+				if err != nil {
+					n.overflightErr(ctx, ii, err)
+					return
+				}
+				ors = append(ors, rs...)
+			}
+		)
 
 		for _, ii := range iis {
+			if ii.retire {
+				if !ii.unindexed {
+					overfly(ii, nil, false)
+				}
+				// Just remove the key's positions.
+				cans, _, _, err := i.Update(ii.Id, ii.Key, nil)
+				if err != nil {
+					n.logf(ctx, "retire %s", err)
+				} else {
+					ios = append(ios, &IndexOutput{
+						Time:     t,
+						Canceled: cans,
+					})
+				}
+			}
+			if ii.retire && !ii.unindexed {
+				continue
+			}
+			if ii.unindexed && !n.overflighting() {
+				continue
+			}
+			list = append(list, ii)
+			ps = append(ps, ii.Sat.TLE)
+		}
+
+		b := &tle.Batch{
+			Workers: workers,
+		}
+		m := b.PropBatch(ps, []time.Time{t})
+
+		for k, ii := range list {
 
-			// We might want to Prop in a batch in another goroutine.
-			e, err := ii.Sat.TLE.Prop(t)
+			e, err := m.At(k, 0)
 			if err != nil {
 				n.logf(ctx, "sat.Prop %s", err)
 				continue
@@ -399,7 +823,15 @@ func (n *Node) IndexWork(ctx context.Context, iis map[index.Key]*IndexInput, f f
 
 			pps := []index.ProbPos{pp}
 
-			cans, novs, _, err := i.Update(ii.Id, ii.Key, pps)
+			w := watched == nil || watched[ii.Key]
+
+			overfly(ii, &e, w && n.OwnsObject(ii.Sat.TLE.CatNum))
+
+			if ii.unindexed {
+				continue
+			}
+
+			cans, novs, _, err := i.UpdateWatched(ii.Id, ii.Key, pps, w)
 
 			io := &IndexOutput{
 				Time:     t,
@@ -412,12 +844,14 @@ func (n *Node) IndexWork(ctx context.Context, iis map[index.Key]*IndexInput, f f
 
 		// We don't need the index anymore.
 
+		ors = append(ors, n.consistencyWork(ctx, t, views)...)
+
 		// We expect that most output will be empty, so let's
 		// perform this initial consolidation without creating
 		// a new goroutine.  ToDo: Reconsider.
 		ios = n.Consolidate(ios)
 		if 0 == len(ios) {
-			f([]*Report{})
+			f(ors)
 		} else {
 			// We have some work to do, so let's do it in
 			// a new goroutine.
@@ -425,7 +859,7 @@ func (n *Node) IndexWork(ctx context.Context, iis map[index.Key]*IndexInput, f f
 				ps := n.GetIndexOutputTLEs(ctx, ios)
 				rs := n.generateReports(ctx, ios, ps)
 				n.logf(ctx, "Generated %d reports", len(rs))
-				f(rs)
+				f(append(ors, rs...))
 			}()
 		}
 	}
@@ -437,15 +871,32 @@ func (n *Node) IndexWork(ctx context.Context, iis map[index.Key]*IndexInput, f f
 // This method waits for all of that work to complete before
 // returning.  That behavior results in backpressure for the Node's
 // input channel.
+//
+// When there is only one index (typically a new time slice receiving
+// the entire live set), propagation uses PropWorkers goroutines.
+// Otherwise the indexes themselves provide the parallelism.
 func (n *Node) process(ctx context.Context, indexes map[time.Time]*Index, iis map[index.Key]*IndexInput) error {
 	n.logf(ctx, "Index processing: %d (%d indexes)", len(iis), len(indexes))
 
+	workers := 1
+	if len(indexes) == 1 {
+		workers = n.PropWorkers
+		if workers == 0 {
+			workers = runtime.NumCPU()
+		}
+	}
+
 	var (
 		done = ctx.Done()
 		wg   = sync.WaitGroup{}
 
 		f = func(rs []*Report) {
-			if 0 < len(rs) {
+			switch {
+			case len(rs) == 0:
+			case n.Hub != nil:
+				n.Hub.Publish(ctx, rs)
+				n.logf(ctx, "Published %d reports", len(rs))
+			default:
 				select {
 				case <-done:
 				case n.Out <- rs:
@@ -455,7 +906,7 @@ func (n *Node) process(ctx context.Context, indexes map[time.Time]*Index, iis ma
 			wg.Done()
 		}
 
-		indexWork = n.IndexWork(ctx, iis, f)
+		indexWork = n.indexWork(ctx, iis, workers, f)
 	)
 
 	// Give work to all of these indexes.
@@ -493,13 +944,48 @@ type Index struct {
 
 func (n *Node) NewIndex(t time.Time) *Index {
 	return &Index{
-		I:    index.NewIndexWithFinder(n.Finder, n.IndexDist),
+		I:    n.newIndex(),
 		t:    t,
 		in:   make(chan func(*index.Index, time.Time)),
 		stop: make(chan bool),
 	}
 }
 
+func (n *Node) newIndex() *index.Index {
+	i := index.NewIndexWithFinder(n.Finder, n.indexDist())
+	i.SetWatching(n.watching())
+	return i
+}
+
+// NewIndexes makes and starts (with Run) an Index for each time
+// slice in [t0,t1) that this Node owns.
+func (n *Node) NewIndexes(ctx context.Context, t0, t1 time.Time) map[time.Time]*Index {
+	indexes := make(map[time.Time]*Index)
+	for t := t0; t.Before(t1); t = t.Add(n.Resolution) {
+		if !n.Owns(t) {
+			continue
+		}
+		i := n.NewIndex(t)
+		indexes[t] = i
+		go i.Run(ctx)
+	}
+	return indexes
+}
+
+// Owns reports whether this Node owns the time slice at t.
+//
+// When Shards is greater than one, slices are assigned to shards
+// round-robin: the slice number (t in units of Resolution since the
+// Unix epoch) modulo Shards gives the owning Shard.  Every Node in a
+// cluster must therefore use the same Resolution.
+func (n *Node) Owns(t time.Time) bool {
+	if n.Shards <= 1 {
+		return true
+	}
+	slice := t.UnixNano() / int64(n.Resolution)
+	return int(slice%int64(n.Shards)) == n.Shard
+}
+
 // Stop halts the Index's Run loop (if any).
 func (i *Index) Stop(ctx context.Context) {
 	close(i.stop)
@@ -578,6 +1064,9 @@ func (n *Node) generateReports(ctx context.Context, ios []*IndexOutput, ps map[i
 
 	for _, uo := range ios {
 		for _, c := range uo.Novel {
+			if !n.reportable(&c, ps) {
+				continue
+			}
 			r, err := n.ConjToReport(uo.Time, &c, n.ScanDist, ps, false)
 			if err != nil {
 				if sgp4.HasDecayed(err) {
@@ -593,6 +1082,9 @@ func (n *Node) generateReports(ctx context.Context, ios []*IndexOutput, ps map[i
 			novs++
 		}
 		for _, c := range uo.Canceled {
+			if !n.reportable(&c, ps) {
+				continue
+			}
 			r, err := n.ConjToReport(uo.Time, &c, n.ScanDist, ps, true)
 			if err != nil {
 				if sgp4.HasDecayed(err) {
@@ -621,30 +1113,65 @@ type State struct {
 	//
 	// We say "Obj" to facilitate generalization from TLEs
 	// specifically.
-	Obj *tle.SGP4TLE
+	//
+	// Obj is nil in compact reports (see Cfg.CompactReports).
+	Obj *tle.SGP4TLE `json:",omitempty"`
+
+	// Digest identifies the TLE in compact reports.  See
+	// PubTLE.Digest and CatalogEvent.
+	Digest string `json:",omitempty"`
 
 	// Age is the age of the source (TLE) in seconds.
-	Age int64 `json:",omitempty"` // Seconds
+	Age int64 `json:",omitempty" units:"s"`
+
+	// Publisher is the publisher of the TLE.
+	Publisher string `json:",omitempty"`
 
 	// Type is a crude classification of the object.
 	//
 	// See TLE.GetType().
 	Type string
 
-	// ECI is the position vector.
-	ECI prop.Vect
+	// ECI is the position vector (km).
+	ECI prop.Vect `units:"km"`
 
-	// Vel is relative velocity (m/s).
-	Vel prop.Vect
+	// Vel is the velocity vector (km/s).
+	Vel prop.Vect `units:"km/s"`
 
 	// LLA is latittude (deg), longitude (deg), and altitude (km)
 	LLA LatLonAlt
 
+	// Light is the object's illumination by the Sun (see
+	// Cfg.ShadowModel).
+	Light *Lighting `json:",omitempty"`
+
 	// ToDo: Prob (again)
 }
 
+// ReportSchema is the current version of the report schema.
+//
+// The version changes whenever a Report's fields or their meanings
+// change incompatibly.
+const ReportSchema = 1
+
+// ConsistencyReport is the Report Kind for a divergence between
+// publishers' TLEs for the same object.
+const ConsistencyReport = "consistency"
+
 // Report is a complete conjunction report: what we are here for.
 type Report struct {
+	// Schema is the version of the report schema (ReportSchema).
+	//
+	// See package schema for the JSON Schema document and other
+	// encodings.
+	Schema int
+
+	// Kind is empty for a conjunction, ConsistencyReport for a
+	// divergence between publishers' TLEs for one object,
+	// OverflightReport for an object entering or exiting an ROI, and
+	// WhatIfReport for a close approach found by Node.Screen.
+	Kind string `json:",omitempty"`
+
 	// Id is a logical identifier for this report.
 	Id string
 
@@ -664,13 +1191,23 @@ type Report struct {
 	Canceled bool `json:",omitempty"`
 
 	// Dist is the estimated Cartesian distance (km) between the two Objs.
-	Dist float32
+	Dist float32 `units:"km"`
 
-	// Speed is the estimated Relative speed (m/s) between the two Objs.
-	Speed float32
+	// Speed is the estimated Relative speed (km/s) between the two Objs.
+	Speed float32 `units:"km/s"`
 
 	// Objs is an array of the State of the two objects in this event.
+	//
+	// An overflight report has only one object.
 	Objs []State
+
+	// Overflight gives the ROI and the event for a Report with
+	// Kind OverflightReport.
+	Overflight *Overflight `json:",omitempty"`
+
+	// Tags are labels added after the report was generated (see
+	// package rules).  They are not part of Sig or Id.
+	Tags []string `json:",omitempty"`
 }
 
 // ConjToReport builds the final Reports.
@@ -681,14 +1218,9 @@ func (n *Node) ConjToReport(t time.Time, c *index.Conj, dist float32, ps map[ind
 
 	// Obtain the (populated) object instances based on their ids.
 
-	o0, have := ps[c.Ats[0].Id]
-	if !have {
-		return nil, Warningf("Node ids index doesn't have %v", c.Ats[0].Id)
-	}
-
-	o1, have := ps[c.Ats[1].Id]
-	if !have {
-		return nil, Warningf("Node ids index doesn't have %v", c.Ats[1].Id)
+	o0, o1, err := conjObjs(c, ps)
+	if err != nil {
+		return nil, err
 	}
 
 	// Possibly scan for a closer approach +/- one tick.  ScanPair
@@ -698,7 +1230,7 @@ func (n *Node) ConjToReport(t time.Time, c *index.Conj, dist float32, ps map[ind
 		return nil, err
 	}
 
-	if dist < d {
+	if !n.screened(o0.TLE, o1.TLE, es, d, dist) {
 		return nil, nil
 	}
 
@@ -720,60 +1252,103 @@ func (n *Node) ConjToReport(t time.Time, c *index.Conj, dist float32, ps map[ind
 
 	// p := c.Ats[0].Prob * c.Ats[1].Prob
 
-	l0, err := ECIToLLA(t, es[0].ECI)
-	if err != nil {
-		return nil, err
+	return n.makeReport(t, then, d, v, es, o0, o1, "", canceled)
+}
+
+// conjObjs finds the PubTLEs for the Conj's ids.
+func conjObjs(c *index.Conj, ps map[index.Id]*PubTLE) (*PubTLE, *PubTLE, error) {
+	o0, have := ps[c.Ats[0].Id]
+	if !have {
+		return nil, nil, Warningf("Node ids index doesn't have %v", c.Ats[0].Id)
 	}
-	s0 := State{
-		Name: o0.Name(),
-		Obj:  o0.TLE,
-		Age:  int64(o0.TLE.ApproxAge(t).Seconds()),
-		Type: o0.TLE.GetType(),
-		// Prob: c.Ats[0].ProbPos.Prob,
-		ECI: es[0].ECI,
-		Vel: es[0].V,
-		LLA: *l0,
+
+	o1, have := ps[c.Ats[1].Id]
+	if !have {
+		return nil, nil, Warningf("Node ids index doesn't have %v", c.Ats[1].Id)
 	}
 
-	l1, err := ECIToLLA(t, es[1].ECI)
+	return o0, o1, nil
+}
+
+// makeReport assembles a Report (including its Sig and Id).
+func (n *Node) makeReport(t, then time.Time, d, v float32, es []prop.Ephemeris, o0, o1 *PubTLE, kind string, canceled bool) (*Report, error) {
+
+	s0, err := n.state(t, es[0], o0)
 	if err != nil {
 		return nil, err
 	}
-	s1 := State{
-		Name: o1.Name(),
-		Obj:  o1.TLE,
-		Age:  int64(o1.TLE.ApproxAge(t).Seconds()),
-		Type: o1.TLE.GetType(),
-		// Prob: c.Ats[1].ProbPos.Prob,
-		ECI: es[1].ECI,
-		Vel: es[1].V,
-		LLA: *l1,
+
+	s1, err := n.state(t, es[1], o1)
+	if err != nil {
+		return nil, err
 	}
 
 	r := &Report{
+		Kind:  kind,
 		At:    then,
 		Dist:  d,
 		Speed: v,
 		Objs:  []State{s0, s1},
 	}
 
-	{
-		// Sig does not include Canceled or Generated.
-		js, err := json.Marshal(r)
-		if err != nil {
-			return nil, err
-		}
-		r.Sig = misc.SHA(js)
-		r.Canceled = canceled
-		r.Generated = time.Now().UTC()
-
-		// Id includes Canceled and Generated.
-		js, err = json.Marshal(r)
-		if err != nil {
-			return nil, err
-		}
-		r.Id = misc.SHA(js)
+	if err := sign(r, canceled); err != nil {
+		return nil, err
 	}
 
 	return r, nil
 }
+
+// state makes the State of the object at t.
+//
+// With CompactReports, the State refers to its object by Name and
+// Digest.
+func (n *Node) state(t time.Time, e prop.Ephemeris, o *PubTLE) (State, error) {
+	l, err := ECIToLLA(t, e.ECI)
+	if err != nil {
+		return State{}, err
+	}
+	s := State{
+		Name:      o.Name(),
+		Obj:       o.TLE,
+		Age:       int64(o.TLE.ApproxAge(t).Seconds()),
+		Publisher: o.Publisher,
+		Type:      o.TLE.GetType(),
+		ECI:       e.ECI,
+		Vel:       e.V,
+		LLA:       *l,
+		Light:     Light(t, e, n.ShadowModel),
+	}
+
+	if n.CompactReports {
+		s.Obj, s.Digest = nil, o.Digest()
+	}
+
+	return s, nil
+}
+
+// sign sets the Report's Sig, Schema, Canceled, Generated, and Id.
+//
+// A cancellation made by signing a copy of a Report has the same
+// Sig.
+func sign(r *Report, canceled bool) error {
+	r.Id, r.Sig, r.Schema, r.Canceled, r.Generated = "", "", 0, false, time.Time{}
+
+	// Sig does not include Schema, Canceled, or Generated.
+	js, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	r.Sig = misc.SHA(js)
+	r.Schema = ReportSchema
+	r.Canceled = canceled
+	r.Generated = time.Now().UTC()
+
+	// Id includes Canceled and Generated.
+	js, err = json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	r.Id = misc.SHA(js)
+
+	return nil
+}
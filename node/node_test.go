@@ -98,3 +98,39 @@ func TestNode(t *testing.T) {
 		}
 	}
 }
+
+func TestSubmit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := testNode()
+	n.T0 = testEpoch
+	go n.Run(ctx)
+
+	if err := n.Submit(ctx, testTLEs(t, "a", 3)); err != nil {
+		t.Fatal(err)
+	}
+	// The reports are already in Out.
+	select {
+	case rs := <-n.Out:
+		if len(rs) == 0 {
+			t.Fatal("no reports")
+		}
+	default:
+		t.Fatal("no reports after Submit")
+	}
+
+	cfg, err := n.Config(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.T0.Equal(testEpoch) || cfg.Horizon != n.Horizon {
+		t.Fatalf("config %#v", cfg)
+	}
+
+	canceled, stop := context.WithCancel(ctx)
+	stop()
+	if err := n.Submit(canceled, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,462 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/ut-astria/spi/index"
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/sgp4"
+	"github.com/ut-astria/spi/tle"
+)
+
+// Overflight reports are enter and exit events for objects and
+// regions of interest (ROIs).  Each time slice compares an object's
+// position at its time with the position one Resolution earlier, so
+// an event belongs to the slice that ends the interval containing
+// the crossing.  The event's At is the crossing time to within
+// overflightPrecision.
+//
+// Each slice remembers the events it reported for each key.  When a
+// key is resubmitted (a new TLE, a retirement, or a change to the
+// watch list), the slice cancels the remembered events that are no
+// longer predicted and reports the new ones.
+//
+// Only watched objects (see Cfg.WatchList) are considered.  The
+// pre-filters (FilterApsides and FilterPath) don't apply: a watched
+// object without any possible partner is still propagated in each
+// slice (see IndexInput.unindexed).  Since finding geodetic positions
+// isn't cheap, a watch list is a good idea.
+
+// OverflightReport is the Report Kind for an object entering or
+// exiting an ROI.
+const OverflightReport = "overflight"
+
+// Overflight events.
+const (
+	OverflightEnter = "enter"
+	OverflightExit  = "exit"
+)
+
+// overflightPrecision is the precision of an overflight event's At.
+const overflightPrecision = 10 * time.Millisecond
+
+// Ellipsoid radii (km) that bound geodetic altitudes.
+const (
+	equatorialRadius = 6378.137
+	polarRadius      = 6356.752
+)
+
+// Overflight describes the event in an overflight Report.
+type Overflight struct {
+	// ROI is the name of the region of interest.
+	ROI string
+
+	// Event is OverflightEnter or OverflightExit.
+	Event string
+}
+
+// ROI is a region of interest for overflight reports: an S2 region
+// (a polygon or a cap) with an altitude band.
+type ROI struct {
+	Name string
+
+	// Region is an *s2.Polygon or an s2.Cap.
+	Region s2.Region `json:"-"`
+
+	// MinAlt and MaxAlt (km) bound the altitude band.  A MaxAlt
+	// of zero means no upper bound.
+	MinAlt, MaxAlt float64
+}
+
+// Contains reports whether the position is in the ROI.
+func (r *ROI) Contains(p LatLonAlt) bool {
+	alt := float64(p.Alt)
+	if alt < r.MinAlt || (0 < r.MaxAlt && r.MaxAlt < alt) {
+		return false
+	}
+	ll := s2.LatLngFromDegrees(float64(p.Lat), float64(p.Lon))
+	return r.Region.ContainsPoint(s2.PointFromLatLng(ll))
+}
+
+// ReadROIs reads ROIs from the given GeoJSON file (see ParseROIs).
+func ReadROIs(filename string) ([]*ROI, error) {
+	bs, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := ParseROIs(bs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	return rs, nil
+}
+
+// ParseROIs makes an ROI for each Feature in a GeoJSON
+// FeatureCollection.
+//
+// A Polygon or MultiPolygon is an s2.Polygon.  Rings can have either
+// orientation, and a ring inside another ring is a hole.  A Point
+// with a "radius" (km along the surface) property is an s2.Cap.
+//
+// The optional properties "name", "minAlt" (km), and "maxAlt" (km)
+// give the ROI's Name, MinAlt, and MaxAlt.
+func ParseROIs(bs []byte) ([]*ROI, error) {
+	var fc struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+			Properties struct {
+				Name   string
+				Radius float64
+				MinAlt float64
+				MaxAlt float64
+			}
+		}
+	}
+	if err := json.Unmarshal(bs, &fc); err != nil {
+		return nil, err
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("GeoJSON type %q isn't FeatureCollection", fc.Type)
+	}
+
+	acc := make([]*ROI, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		var (
+			g     = f.Geometry
+			props = f.Properties
+			r     = &ROI{
+				Name:   props.Name,
+				MinAlt: props.MinAlt,
+				MaxAlt: props.MaxAlt,
+			}
+			err error
+		)
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("roi-%d", i)
+		}
+
+		switch g.Type {
+		case "Polygon":
+			var rings [][][]float64
+			if err = json.Unmarshal(g.Coordinates, &rings); err == nil {
+				r.Region, err = polygon(rings)
+			}
+		case "MultiPolygon":
+			var polys [][][][]float64
+			if err = json.Unmarshal(g.Coordinates, &polys); err == nil {
+				var rings [][][]float64
+				for _, p := range polys {
+					rings = append(rings, p...)
+				}
+				r.Region, err = polygon(rings)
+			}
+		case "Point":
+			var p []float64
+			if err = json.Unmarshal(g.Coordinates, &p); err == nil {
+				r.Region, err = capRegion(p, props.Radius)
+			}
+		default:
+			err = fmt.Errorf("unsupported geometry %q", g.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", r.Name, err)
+		}
+		if 0 < r.MaxAlt && r.MaxAlt < r.MinAlt {
+			return nil, fmt.Errorf("%s: maxAlt %f < minAlt %f", r.Name, r.MaxAlt, r.MinAlt)
+		}
+
+		acc = append(acc, r)
+	}
+	return acc, nil
+}
+
+// point makes an s2.Point from a GeoJSON [lon, lat] position.
+func point(p []float64) (s2.Point, error) {
+	if len(p) < 2 {
+		return s2.Point{}, fmt.Errorf("bad position %v", p)
+	}
+	return s2.PointFromLatLng(s2.LatLngFromDegrees(p[1], p[0])), nil
+}
+
+// polygon makes an s2.Polygon from GeoJSON linear rings.
+func polygon(rings [][][]float64) (*s2.Polygon, error) {
+	loops := make([]*s2.Loop, 0, len(rings))
+	for _, ring := range rings {
+		// GeoJSON repeats the first position at the end.
+		if 1 < len(ring) {
+			first, last := ring[0], ring[len(ring)-1]
+			if len(first) == len(last) && first[0] == last[0] && first[1] == last[1] {
+				ring = ring[:len(ring)-1]
+			}
+		}
+		if len(ring) < 3 {
+			return nil, fmt.Errorf("ring with %d positions", len(ring))
+		}
+		ps := make([]s2.Point, len(ring))
+		for i, p := range ring {
+			var err error
+			if ps[i], err = point(p); err != nil {
+				return nil, err
+			}
+		}
+		l := s2.LoopFromPoints(ps)
+		// Either orientation is fine.
+		l.Normalize()
+		if err := l.Validate(); err != nil {
+			return nil, err
+		}
+		loops = append(loops, l)
+	}
+	if len(loops) == 0 {
+		return nil, fmt.Errorf("no rings")
+	}
+	return s2.PolygonFromLoops(loops), nil
+}
+
+// capRegion makes an s2.Cap from a GeoJSON position and a radius (km
+// along the surface).
+func capRegion(p []float64, radius float64) (s2.Cap, error) {
+	if radius <= 0 {
+		return s2.Cap{}, fmt.Errorf("bad radius %f", radius)
+	}
+	c, err := point(p)
+	if err != nil {
+		return s2.Cap{}, err
+	}
+	return s2.CapFromCenterAngle(c, s1.Angle(radius/equatorialRadius)), nil
+}
+
+// sliceReports remembers Reports (say overflights) for each key in
+// each time slice.  Slices work concurrently, so access is
+// synchronized.
+//
+// The Reports are copies since consumers (see package rules) can
+// modify emitted Reports.
+type sliceReports struct {
+	sync.Mutex
+	slices map[time.Time]map[index.Key][]Report
+}
+
+func newSliceReports() *sliceReports {
+	return &sliceReports{
+		slices: make(map[time.Time]map[index.Key][]Report),
+	}
+}
+
+// swap remembers (copies of) the Reports for the key in the slice at
+// t, and it returns the previous ones.
+func (o *sliceReports) swap(t time.Time, k index.Key, rs []*Report) []Report {
+	o.Lock()
+	defer o.Unlock()
+	keys, have := o.slices[t]
+	if !have {
+		if len(rs) == 0 {
+			return nil
+		}
+		keys = make(map[index.Key][]Report)
+		o.slices[t] = keys
+	}
+	old := keys[k]
+	if len(rs) == 0 {
+		delete(keys, k)
+		return old
+	}
+	kept := make([]Report, len(rs))
+	for i, r := range rs {
+		kept[i] = *r
+	}
+	keys[k] = kept
+	return old
+}
+
+// forget discards the slice at t.
+func (o *sliceReports) forget(t time.Time) {
+	o.Lock()
+	delete(o.slices, t)
+	o.Unlock()
+}
+
+// changes returns cancellations of the old Reports that aren't
+// among the current ones along with the current Reports that are
+// new.
+func changes(old []Report, rs []*Report) ([]*Report, error) {
+	var (
+		sigs  = make(map[string]bool, len(old))
+		acc   = make([]*Report, 0, len(rs)+len(old))
+		fresh = make(map[string]bool, len(rs))
+	)
+	for _, r := range old {
+		sigs[r.Sig] = true
+	}
+	for _, r := range rs {
+		fresh[r.Sig] = true
+		if !sigs[r.Sig] {
+			acc = append(acc, r)
+		}
+	}
+	for _, r := range old {
+		if fresh[r.Sig] {
+			continue
+		}
+		c := r
+		if err := sign(&c, true); err != nil {
+			return nil, err
+		}
+		acc = append(acc, &c)
+	}
+	return acc, nil
+}
+
+// overflighting reports whether the Node generates overflight
+// reports.
+func (n *Node) overflighting() bool {
+	return 0 < len(n.ROIs)
+}
+
+// mightOverfly reports whether the position could be in the altitude
+// band of any ROI.  Geodetic altitude is between the position's
+// radius less the equatorial radius and its radius less the polar
+// radius.
+func (n *Node) mightOverfly(p prop.Vect) bool {
+	r := math.Sqrt(float64(p.X*p.X + p.Y*p.Y + p.Z*p.Z))
+	for _, roi := range n.ROIs {
+		if r-polarRadius < roi.MinAlt {
+			continue
+		}
+		if 0 < roi.MaxAlt && roi.MaxAlt < r-equatorialRadius {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// overflightWork finds the overflight Reports for the key in the
+// slice at t, and it returns cancellations of previous Reports that
+// are no longer predicted along with Reports that are new.
+//
+// The Ephemeris e is the object's at t.  If predict is false (say for
+// a retirement or an unwatched object), no events are predicted.
+func (n *Node) overflightWork(t time.Time, ii *IndexInput, e *prop.Ephemeris, predict bool) ([]*Report, error) {
+	var (
+		rs  []*Report
+		err error
+	)
+	if predict {
+		if rs, err = n.overflightReports(t, ii.Sat, e); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes(n.overflights.swap(t, ii.Key, rs), rs)
+}
+
+// overflightReports predicts the ROI events for the object in the
+// slice at t.  The Ephemeris e is the object's at t.
+func (n *Node) overflightReports(t time.Time, o *PubTLE, e *prop.Ephemeris) ([]*Report, error) {
+	t0 := t.Add(-n.Resolution)
+	e0, err := o.TLE.Prop(t0)
+	if err != nil {
+		return nil, err
+	}
+	if !n.mightOverfly(e0.ECI) && !n.mightOverfly(e.ECI) {
+		return nil, nil
+	}
+
+	l0, err := ECIToLLA(t0, e0.ECI)
+	if err != nil {
+		return nil, err
+	}
+	l1, err := ECIToLLA(t, e.ECI)
+	if err != nil {
+		return nil, err
+	}
+
+	var acc []*Report
+	for _, roi := range n.ROIs {
+		in := roi.Contains(*l1)
+		if roi.Contains(*l0) == in {
+			continue
+		}
+		then, e, err := roi.crossing(o.TLE, t0, t, in)
+		if err != nil {
+			return nil, err
+		}
+		event := OverflightExit
+		if in {
+			event = OverflightEnter
+		}
+		r, err := n.overflightReport(then, e, o, roi, event)
+		if err != nil {
+			return nil, err
+		}
+		acc = append(acc, r)
+	}
+	return acc, nil
+}
+
+// crossing finds the first time in (t0,t1] (to within
+// overflightPrecision) when the object's containment in the ROI is
+// in, and it returns that time and the object's Ephemeris then.
+func (r *ROI) crossing(o *tle.SGP4TLE, t0, t1 time.Time, in bool) (time.Time, prop.Ephemeris, error) {
+	for overflightPrecision < t1.Sub(t0) {
+		t := t0.Add(t1.Sub(t0) / 2)
+		e, err := o.Prop(t)
+		if err != nil {
+			return t, e, err
+		}
+		l, err := ECIToLLA(t, e.ECI)
+		if err != nil {
+			return t, e, err
+		}
+		if r.Contains(*l) == in {
+			t1 = t
+		} else {
+			t0 = t
+		}
+	}
+	e, err := o.Prop(t1)
+	return t1, e, err
+}
+
+// overflightReport assembles an overflight Report.
+func (n *Node) overflightReport(t time.Time, e prop.Ephemeris, o *PubTLE, roi *ROI, event string) (*Report, error) {
+	s, err := n.state(t, e, o)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{
+		Kind: OverflightReport,
+		At:   t,
+		Objs: []State{s},
+		Overflight: &Overflight{
+			ROI:   roi.Name,
+			Event: event,
+		},
+	}
+	if err := sign(r, false); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// overflightErr logs an overflight error unless the object has
+// decayed.
+func (n *Node) overflightErr(ctx context.Context, ii *IndexInput, err error) {
+	if sgp4.HasDecayed(err) {
+		return
+	}
+	n.logf(ctx, "overflight %s: %s", ii.Sat.Name(), err)
+}
@@ -0,0 +1,234 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/tle"
+)
+
+func TestParseROIs(t *testing.T) {
+	var (
+		square = `[[-10,-10],[10,-10],[10,10],[-10,10],[-10,-10]]`
+		hole   = `[[-1,-1],[1,-1],[1,1],[-1,1],[-1,-1]]`
+		gj     = `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Polygon","coordinates":[` + square + `,` + hole + `]},"properties":{"name":"square","minAlt":200,"maxAlt":1000}},
+{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[-10,-10],[-10,10],[10,10],[10,-10]]]}},
+{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[` + square + `],[[[170,0],[-170,0],[-170,10],[170,10],[170,0]]]]}},
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-97.7,30.3]},"properties":{"name":"austin","radius":100}}
+]}`
+	)
+
+	rs, err := ParseROIs([]byte(gj))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 4 {
+		t.Fatalf("%d ROIs", len(rs))
+	}
+	if rs[0].Name != "square" || rs[0].MinAlt != 200 || rs[0].MaxAlt != 1000 || rs[1].Name != "roi-1" {
+		t.Fatalf("%#v %#v", rs[0], rs[1])
+	}
+
+	tests := []struct {
+		roi  int
+		p    LatLonAlt
+		want bool
+	}{
+		{0, LatLonAlt{Lat: 5, Lon: 5, Alt: 500}, true},
+		{0, LatLonAlt{Lat: 0, Lon: 0, Alt: 500}, false},    // Hole
+		{0, LatLonAlt{Lat: 5, Lon: 5, Alt: 100}, false},    // Too low
+		{0, LatLonAlt{Lat: 5, Lon: 5, Alt: 2000}, false},   // Too high
+		{0, LatLonAlt{Lat: 20, Lon: 5, Alt: 500}, false},   // Outside
+		{1, LatLonAlt{Lat: 5, Lon: 5, Alt: 2000}, true},    // Clockwise
+		{1, LatLonAlt{Lat: 50, Lon: 50, Alt: 2000}, false}, // Not the complement
+		{2, LatLonAlt{Lat: 5, Lon: 179, Alt: 500}, true},   // Antimeridian
+		{2, LatLonAlt{Lat: 5, Lon: -175, Alt: 500}, true},
+		{2, LatLonAlt{Lat: 5, Lon: 160, Alt: 500}, false},
+		{3, LatLonAlt{Lat: 30.5, Lon: -97.5, Alt: 500}, true},
+		{3, LatLonAlt{Lat: 32, Lon: -97.7, Alt: 500}, false},
+	}
+	for _, test := range tests {
+		if got := rs[test.roi].Contains(test.p); got != test.want {
+			t.Errorf("%s contains %v: %v", rs[test.roi].Name, test.p, got)
+		}
+	}
+
+	bad := []string{
+		`{"type":"Feature"}`,
+		`{"type":"FeatureCollection","features":[{"geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]}}]}`,
+		`{"type":"FeatureCollection","features":[{"geometry":{"type":"Point","coordinates":[0,0]}}]}`,
+		`{"type":"FeatureCollection","features":[{"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,0]]]}}]}`,
+		`{"type":"FeatureCollection","features":[{"geometry":{"type":"Point","coordinates":[0,0]},"properties":{"radius":1,"minAlt":500,"maxAlt":100}}]}`,
+	}
+	for _, gj := range bad {
+		if _, err := ParseROIs([]byte(gj)); err == nil {
+			t.Errorf("expected an error for %s", gj)
+		}
+	}
+}
+
+// testROI returns a cap (with the given radius in km) around the
+// position of the object at the given time.
+func testROI(t *testing.T, sat *PubTLE, at time.Time, radius float64) *ROI {
+	e, err := sat.TLE.Prop(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := ECIToLLA(at, e.ECI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gj := fmt.Sprintf(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[%f,%f]},"properties":{"name":"test","radius":%f}}]}`,
+		l.Lon, l.Lat, radius)
+	rs, err := ParseROIs([]byte(gj))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs[0]
+}
+
+// overflightEvents checks that the reports are overflights, and it
+// returns their events.
+func overflightEvents(t *testing.T, rs []*Report) []string {
+	acc := make([]string, 0, len(rs))
+	for _, r := range rs {
+		if r.Kind != OverflightReport || r.Overflight == nil || len(r.Objs) != 1 {
+			t.Fatalf("report %#v", r)
+		}
+		acc = append(acc, r.Overflight.Event)
+	}
+	return acc
+}
+
+func TestOverflight(t *testing.T) {
+	var (
+		a   = testTLEs(t, "a", 1)
+		roi = testROI(t, a[0], testEpoch.Add(10*time.Second), 30)
+	)
+
+	n := testNode()
+	n.ROIs = []*ROI{roi}
+	rs := testSlices(t, n, testEpoch, a)
+
+	events := overflightEvents(t, rs)
+	if len(events) != 2 {
+		t.Fatalf("events: %v", events)
+	}
+	var enter, exit time.Time
+	for _, r := range rs {
+		if r.Canceled || r.Overflight.ROI != "test" || r.Schema != ReportSchema {
+			t.Fatalf("report %#v", r)
+		}
+		switch r.Overflight.Event {
+		case OverflightEnter:
+			enter = r.At
+		case OverflightExit:
+			exit = r.At
+		}
+		// The object is on the edge of the ROI.
+		if roi.Contains(r.Objs[0].LLA) != (r.Overflight.Event == OverflightEnter) {
+			t.Fatalf("%s at %v", r.Overflight.Event, r.Objs[0].LLA)
+		}
+	}
+	if enter.IsZero() || !enter.Before(exit) {
+		t.Fatalf("enter %s, exit %s", enter, exit)
+	}
+	// About 7 km/s across 60 km.
+	if d := exit.Sub(enter); d < 7*time.Second || 10*time.Second < d {
+		t.Fatalf("overflight took %s", d)
+	}
+
+	t.Run("resubmit", func(t *testing.T) {
+		n := testNode()
+		n.ROIs = []*ROI{roi}
+		b := republish(t, a, "a", "20016.08334000")
+		rs := testSlices(t, n, testEpoch, a, a, b)
+
+		var novs, cans int
+		for _, r := range rs {
+			if r.Canceled {
+				cans++
+			} else {
+				novs++
+			}
+		}
+		if novs != 4 || cans != 2 {
+			t.Fatalf("novel: %d, canceled: %d", novs, cans)
+		}
+		rs = active(rs)
+		if len(rs) != 2 {
+			t.Fatalf("%d active", len(rs))
+		}
+		for _, r := range rs {
+			if r.Objs[0].Obj != b[0].TLE {
+				t.Fatalf("active report for old TLE")
+			}
+		}
+	})
+
+	t.Run("unwatched", func(t *testing.T) {
+		n := testNode()
+		n.ROIs = []*ROI{roi}
+		n.WatchList = &WatchList{CatNums: []string{"25544"}}
+		if rs := testSlices(t, n, testEpoch, a); len(rs) != 0 {
+			t.Fatalf("%d reports", len(rs))
+		}
+	})
+
+	t.Run("unpartnered", func(t *testing.T) {
+		// The pre-filters keep a lone object out of the indexes.
+		n := testNode()
+		n.ROIs = []*ROI{roi}
+		n.FilterApsides = true
+		if events := overflightEvents(t, active(testSlices(t, n, testEpoch, a))); len(events) != 2 {
+			t.Fatalf("events: %v", events)
+		}
+	})
+
+	t.Run("retired", func(t *testing.T) {
+		// A new TLE in a much higher orbit leaves the object
+		// without a partner, so it's retired from the indexes.
+		var (
+			pair  = testTLEs(t, "a", 2)
+			lines = pair[0].TLE.TLE
+			line2 = lines[2][:52] + "13.07452065" + lines[2][63:]
+		)
+		p, err := tle.NewSGP4TLE(lines[0], lines[1], line2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moved := &PubTLE{
+			Publisher: "a",
+			TLE:       p.(*tle.SGP4TLE),
+		}
+
+		// The band excludes the pair's original orbit.
+		high := testROI(t, moved, testEpoch.Add(10*time.Second), 30)
+		high.MinAlt = 1000
+
+		n := testNode()
+		n.ROIs = []*ROI{high}
+		n.FilterApsides = true
+		rs := active(testSlices(t, n, testEpoch, pair, []*PubTLE{moved}))
+		if events := overflightEvents(t, rs); len(events) != 2 {
+			t.Fatalf("events: %v", events)
+		}
+		for _, r := range rs {
+			if r.Objs[0].Obj != moved.TLE {
+				t.Fatalf("report for %s", r.Objs[0].Name)
+			}
+		}
+	})
+
+	t.Run("band", func(t *testing.T) {
+		high := *roi
+		high.MinAlt = 2000
+		n := testNode()
+		n.ROIs = []*ROI{&high}
+		if rs := testSlices(t, n, testEpoch, a); len(rs) != 0 {
+			t.Fatalf("%d reports", len(rs))
+		}
+	})
+}
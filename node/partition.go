@@ -0,0 +1,66 @@
+package node
+
+import (
+	"hash/fnv"
+
+	"github.com/ut-astria/spi/index"
+)
+
+// Catalog partitioning is an alternative to (or complement of) time
+// sharding.  Each Node in a cluster owns a hash partition of catalog
+// numbers, but every Node still ingests and indexes every object so
+// that it can find all of the secondaries for the objects it owns.
+//
+// An object owned by a Node is searched for and reported by that
+// Node.  Other objects are passive: they are indexed, but the index
+// only searches for their neighbors among the owned objects (using
+// the watching mechanism in index.Index), so pairs of passive objects
+// cost only indexing.  A pair is reported only by the Node that owns
+// the pair's lesser catalog number (as a string), so each pair is
+// reported exactly once across the cluster.
+//
+// With a watch list, watched objects rather than owned objects are
+// active in the index, and ownership only filters the resulting
+// pairs.
+
+// Partition returns the partition (in [0,partitions)) for the given
+// catalog number.
+//
+// This hash is the same one that spipipe uses for -sample-mod.
+func Partition(catNum string, partitions int) int {
+	if partitions <= 1 {
+		return 0
+	}
+	hash := fnv.New32()
+	hash.Write([]byte(catNum))
+	return int(hash.Sum32() % uint32(partitions))
+}
+
+// OwnsObject reports whether this Node owns the object with the given
+// catalog number.
+func (n *Node) OwnsObject(catNum string) bool {
+	if n.Partitions <= 1 {
+		return true
+	}
+	return Partition(catNum, n.Partitions) == n.Partition
+}
+
+// reportable reports whether this Node should report the given Conj.
+func (n *Node) reportable(c *index.Conj, ps map[index.Id]*PubTLE) bool {
+	if n.Partitions <= 1 {
+		return true
+	}
+	var (
+		o0, have0 = ps[c.Ats[0].Id]
+		o1, have1 = ps[c.Ats[1].Id]
+	)
+	if !have0 || !have1 {
+		// Let ConjToReport complain.
+		return true
+	}
+	primary := o0.TLE.CatNum
+	if o1.TLE.CatNum < primary {
+		primary = o1.TLE.CatNum
+	}
+	return n.OwnsObject(primary)
+}
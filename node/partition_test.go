@@ -0,0 +1,145 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/index"
+)
+
+func TestPartition(t *testing.T) {
+	partitions := 4
+	for i := 0; i < 100; i++ {
+		var (
+			cat    = fmt.Sprintf("%05d", i)
+			owners = 0
+			n      = testNode()
+		)
+		n.Partitions = partitions
+		for p := 0; p < partitions; p++ {
+			n.Partition = p
+			if n.OwnsObject(cat) {
+				owners++
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("%s has %d owners", cat, owners)
+		}
+	}
+}
+
+func TestPartitions(t *testing.T) {
+	var (
+		t0         = testEpoch
+		sats       = testTLEs(t, "test", 6)
+		partitions = 3
+		whole      = reportKeys(testSlices(t, testNode(), t0, sats))
+		parts      = make([]*Report, 0, len(whole))
+	)
+
+	if len(whole) == 0 {
+		t.Fatal("no reports")
+	}
+
+	for p := 0; p < partitions; p++ {
+		n := testNode()
+		n.Partitions = partitions
+		n.Partition = p
+		rs := testSlices(t, n, t0, sats)
+		for _, r := range rs {
+			primary := r.Objs[0].Obj.CatNum
+			if c := r.Objs[1].Obj.CatNum; c < primary {
+				primary = c
+			}
+			if !n.OwnsObject(primary) {
+				t.Fatalf("partition %d reported %s, which it doesn't own", p, primary)
+			}
+		}
+		parts = append(parts, rs...)
+	}
+
+	union := reportKeys(parts)
+
+	if len(union) != len(whole) {
+		t.Fatalf("union has %d reports; unpartitioned: %d", len(union), len(whole))
+	}
+	for i, k := range whole {
+		if union[i] != k {
+			t.Fatalf("%s != %s", union[i], k)
+		}
+	}
+}
+
+func TestPassive(t *testing.T) {
+	var (
+		sats = testTLEs(t, "test", 6)
+		n    = testNode()
+	)
+
+	// Find a partition that owns none of the objects.
+	n.Partitions = 100
+	for owned := true; owned; n.Partition++ {
+		owned = false
+		for _, sat := range sats {
+			owned = owned || n.OwnsObject(sat.TLE.CatNum)
+		}
+		if !owned {
+			break
+		}
+	}
+
+	var (
+		mu              sync.Mutex
+		searches, conjs int
+	)
+	search := func(ctx context.Context, indexes map[time.Time]*Index) error {
+		n.each(ctx, indexes, func(i *index.Index) {
+			if !i.Watching() {
+				t.Errorf("index isn't watching")
+			}
+			for key, ipps := range i.IPPS {
+				for _, pp := range ipps.PPS {
+					cid, err := i.CellFinder.Find(pp.Pos)
+					if err != nil {
+						t.Error(err)
+						continue
+					}
+					cs := i.Search(cid, index.IdProbPos{
+						Id:         ipps.Id,
+						CatalogNum: key.CatalogNum,
+						ProbPos:    pp,
+					}, i.Dist)
+					// Indexes search concurrently.
+					mu.Lock()
+					searches++
+					conjs += len(cs)
+					mu.Unlock()
+				}
+			}
+		})
+		return nil
+	}
+	process := func(ctx context.Context, indexes map[time.Time]*Index) error {
+		return n.processNew(ctx, sats, indexes)
+	}
+
+	if rs := testSteps(t, n, testEpoch, process, search); len(rs) != 0 {
+		t.Fatalf("%d reports", len(rs))
+	}
+	if searches == 0 || conjs != 0 {
+		t.Fatalf("%d searches found %d conjunctions", searches, conjs)
+	}
+}
+
+func TestPartitionCheck(t *testing.T) {
+	for _, p := range []int{-1, 3} {
+		n := testNode()
+		n.Partitions, n.Partition = 3, p
+		if err := n.Prepare(context.Background()); err == nil {
+			t.Errorf("expected an error for partition %d", p)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/ut-astria/spi/index"
+)
+
+// Publisher policies (see Cfg.PublisherPolicy).
+const (
+	// AllPublishers indexes every publisher's TLE for an object.
+	// Each is reported separately.
+	AllPublishers = "all"
+
+	// FreshestPublisher indexes only the TLE with the latest epoch
+	// for each object.
+	FreshestPublisher = "freshest"
+
+	// PriorityPublisher indexes only the TLE from the publisher with
+	// the highest priority (see Cfg.PublisherPriority) for each
+	// object.  Ties are broken by freshness.
+	PriorityPublisher = "priority"
+)
+
+// selecting reports whether a publisher policy is in effect.
+func (n *Node) selecting() bool {
+	return n.PublisherPolicy != "" && n.PublisherPolicy != AllPublishers
+}
+
+// publishing reports whether we track the publishers for each
+// object.
+func (n *Node) publishing() bool {
+	return n.selecting() || 0 < n.CrossCheck || n.Consistency
+}
+
+// candidates returns the live TLEs that are eligible for indexing
+// (before any pre-filtering).
+func (n *Node) candidates() map[index.Key]*IndexInput {
+	if n.selecting() {
+		return n.selected
+	}
+	return n.live
+}
+
+// rank returns the priority rank of the publisher (lower is better).
+// Unlisted publishers rank last.
+func (n *Node) rank(publisher string) int {
+	for i, p := range n.PublisherPriority {
+		if p == publisher {
+			return i
+		}
+	}
+	return len(n.PublisherPriority)
+}
+
+// prefer reports whether a is strictly preferable to b.
+func (n *Node) prefer(a, b *IndexInput) bool {
+	if n.PublisherPolicy == PriorityPublisher {
+		ra, rb := n.rank(a.Sat.Publisher), n.rank(b.Sat.Publisher)
+		if ra != rb {
+			return ra < rb
+		}
+	}
+	return b.Sat.TLE.Epoch().Before(a.Sat.TLE.Epoch())
+}
+
+// selectPublishers applies the publisher policy to new IndexInputs
+// (which must already be in live).
+//
+// The first returned map has the IndexInputs that should be indexed.
+// Those inputs might include previously received TLEs that are now
+// preferred.  The second map has the IndexInputs that were previously
+// selected but no longer are.
+//
+// When CrossCheck is positive, each new TLE is compared with the
+// other publishers' TLEs for the same object.
+func (n *Node) selectPublishers(ctx context.Context, iis map[index.Key]*IndexInput) (map[index.Key]*IndexInput, map[index.Key]*IndexInput) {
+	var (
+		acc     = make(map[index.Key]*IndexInput, len(iis))
+		retired = make(map[index.Key]*IndexInput)
+		cats    = make(map[index.CatalogNum]bool, len(iis))
+	)
+
+	for k := range iis {
+		keys, have := n.pubs[k.CatalogNum]
+		if !have {
+			keys = make(map[index.Key]bool, 1)
+			n.pubs[k.CatalogNum] = keys
+		}
+		keys[k] = true
+		cats[k.CatalogNum] = true
+	}
+
+	if 0 < n.CrossCheck {
+		for k, ii := range iis {
+			n.crossCheck(ctx, ii, n.pubs[k.CatalogNum])
+		}
+	}
+
+	if !n.selecting() {
+		return iis, retired
+	}
+
+	for cat := range cats {
+		// Start with the current choice for stability.
+		var best *IndexInput
+		prev, have := n.chosen[cat]
+		if have {
+			best = n.live[prev]
+		}
+		for k := range n.pubs[cat] {
+			ii := n.live[k]
+			if best == nil || n.prefer(ii, best) {
+				best = ii
+			}
+		}
+
+		if have && prev != best.Key {
+			if _, current := n.live[prev]; current {
+				old := n.live[prev]
+				delete(n.selected, prev)
+				retired[prev] = &IndexInput{
+					Id:     old.Id,
+					Key:    prev,
+					Sat:    old.Sat,
+					retire: true,
+				}
+			}
+		}
+
+		n.chosen[cat] = best.Key
+		n.selected[best.Key] = best
+
+		if _, isNew := iis[best.Key]; isNew || prev != best.Key {
+			acc[best.Key] = best
+		}
+	}
+
+	n.logf(ctx, "Selected %d of %d new TLEs (%d retired)", len(acc), len(iis), len(retired))
+
+	return acc, retired
+}
+
+// crossCheck compares the positions given by the new TLE and the other
+// publishers' TLEs for the same object at the Node's current time,
+// and it emits a warning if they differ by more than CrossCheck.
+func (n *Node) crossCheck(ctx context.Context, ii *IndexInput, keys map[index.Key]bool) {
+	if len(keys) < 2 {
+		return
+	}
+
+	t := time.Now().UTC().Add(n.TimeOffset)
+
+	e, err := ii.Sat.TLE.Prop(t)
+	if err != nil {
+		return
+	}
+
+	for k := range keys {
+		if k == ii.Key {
+			continue
+		}
+		other := n.live[k]
+		e0, err := other.Sat.TLE.Prop(t)
+		if err != nil {
+			continue
+		}
+		if d := e.ECI.Dist(e0.ECI); n.CrossCheck < d {
+			n.warnf(ctx, "same object %s: positions from %s and %s differ by %.1f km",
+				ii.Sat.TLE.CatNum, ii.Sat.Publisher, other.Sat.Publisher, d)
+		}
+	}
+}
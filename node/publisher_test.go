@@ -0,0 +1,157 @@
+package node
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ut-astria/spi/tle"
+)
+
+// republish returns copies of the TLEs from the given publisher with
+// the given epoch (in TLE format).
+func republish(t *testing.T, sats []*PubTLE, publisher, epoch string) []*PubTLE {
+	acc := make([]*PubTLE, 0, len(sats))
+	for _, sat := range sats {
+		lines := sat.TLE.TLE
+		line1 := lines[1][0:18] + epoch + lines[1][32:]
+		p, err := tle.NewSGP4TLE(lines[0], line1, lines[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		acc = append(acc, &PubTLE{
+			Publisher: publisher,
+			TLE:       p.(*tle.SGP4TLE),
+		})
+	}
+	return acc
+}
+
+// active returns the reports that haven't been canceled.
+func active(rs []*Report) []*Report {
+	var (
+		m     = make(map[string]*Report, len(rs))
+		order = make([]string, 0, len(rs))
+	)
+	for _, r := range rs {
+		if r.Canceled {
+			delete(m, r.Sig)
+			continue
+		}
+		m[r.Sig] = r
+		order = append(order, r.Sig)
+	}
+	acc := make([]*Report, 0, len(m))
+	for _, sig := range order {
+		if r, have := m[sig]; have {
+			acc = append(acc, r)
+			delete(m, sig)
+		}
+	}
+	return acc
+}
+
+func sameKeys(t *testing.T, what string, got, want []string) {
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d reports != %d", what, len(got), len(want))
+	}
+	for i, k := range want {
+		if got[i] != k {
+			t.Fatalf("%s: %s != %s", what, got[i], k)
+		}
+	}
+}
+
+func TestPublisherPolicies(t *testing.T) {
+	var (
+		t0    = testEpoch
+		a     = testTLEs(t, "a", 4)
+		b     = republish(t, a, "b", "20016.08400000")
+		onlyA = reportKeys(active(testSlices(t, testNode(), t0, a)))
+		onlyB = reportKeys(active(testSlices(t, testNode(), t0, b)))
+	)
+
+	if len(onlyA) == 0 || len(onlyB) == 0 {
+		t.Fatal("no reports")
+	}
+
+	t.Run("all", func(t *testing.T) {
+		rs := active(testSlices(t, testNode(), t0, a, b))
+		pubs := make(map[string]bool)
+		for _, r := range rs {
+			for _, s := range r.Objs {
+				pubs[s.Publisher] = true
+			}
+		}
+		if !pubs["a"] || !pubs["b"] {
+			t.Fatalf("publishers: %v", pubs)
+		}
+	})
+
+	t.Run("freshest", func(t *testing.T) {
+		n := testNode()
+		n.PublisherPolicy = FreshestPublisher
+		got := reportKeys(active(testSlices(t, n, t0, a, b)))
+		sameKeys(t, "a then b", got, onlyB)
+
+		n = testNode()
+		n.PublisherPolicy = FreshestPublisher
+		got = reportKeys(active(testSlices(t, n, t0, b, a)))
+		sameKeys(t, "b then a", got, onlyB)
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		n := testNode()
+		n.PublisherPolicy = PriorityPublisher
+		n.PublisherPriority = []string{"a", "b"}
+		rs := active(testSlices(t, n, t0, a, b))
+		for _, r := range rs {
+			for _, s := range r.Objs {
+				if s.Publisher != "a" {
+					t.Fatalf("publisher %s", s.Publisher)
+				}
+			}
+		}
+		sameKeys(t, "a over b", reportKeys(rs), onlyA)
+	})
+}
+
+func TestCrossCheck(t *testing.T) {
+	var (
+		a = testTLEs(t, "a", 3)
+		// The same elements at a later epoch are elsewhere.
+		b = republish(t, a, "b", "20016.09000000")
+		n = testNode()
+	)
+	n.CrossCheck = 1
+	n.Errs = make(chan error, 16)
+
+	testSlices(t, n, testEpoch, a, b)
+
+	close(n.Errs)
+	count := 0
+	for err := range n.Errs {
+		if !strings.Contains(err.Error(), "same object") {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != len(a) {
+		t.Fatalf("%d warnings", count)
+	}
+}
+
+func TestPublisherPolicyCheck(t *testing.T) {
+	for _, policy := range []string{"", AllPublishers, FreshestPublisher, PriorityPublisher} {
+		n := testNode()
+		n.PublisherPolicy = policy
+		if err := n.Prepare(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := testNode()
+	n.PublisherPolicy = "fresh"
+	if err := n.Prepare(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+}
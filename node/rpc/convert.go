@@ -0,0 +1,306 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/node/rpc/pb"
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/sgp4"
+	"github.com/ut-astria/spi/tle"
+)
+
+// PubTLEsFromProto converts in-coming elements to PubTLEs.
+//
+// Elements that cannot be converted (or propagated now) are reported
+// in the returned errors.
+func PubTLEsFromProto(ps []*pb.PubTLE, publisher string) ([]*node.PubTLE, []string) {
+	var (
+		acc  = make([]*node.PubTLE, 0, len(ps))
+		errs []string
+	)
+
+	for i, p := range ps {
+		o, err := SGP4TLEFromProto(p)
+		if err == nil {
+			err = tle.Check(o)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%d: %s", i, err))
+			continue
+		}
+		pub := p.Publisher
+		if pub == "" {
+			pub = publisher
+		}
+		acc = append(acc, &node.PubTLE{
+			Publisher: pub,
+			TLE:       o,
+		})
+	}
+
+	return acc, errs
+}
+
+// SGP4TLEFromProto makes an SGP4TLE from a TLE or an OMM.
+func SGP4TLEFromProto(p *pb.PubTLE) (*tle.SGP4TLE, error) {
+	if o := p.GetTle(); o != nil {
+		return tleFromProto(o)
+	}
+	if o := p.GetOmm(); o != nil {
+		return tle.NewSGP4TLEFromOMM(&tle.OMM{
+			ObjectName:         o.ObjectName,
+			ObjectID:           o.ObjectId,
+			Epoch:              o.Epoch,
+			MeanMotion:         o.MeanMotion,
+			Eccentricity:       o.Eccentricity,
+			Inclination:        o.Inclination,
+			RAOfAscNode:        o.RaOfAscNode,
+			ArgOfPericenter:    o.ArgOfPericenter,
+			MeanAnomaly:        o.MeanAnomaly,
+			EphemerisType:      int(o.EphemerisType),
+			ClassificationType: o.ClassificationType,
+			NoradCatID:         int(o.NoradCatId),
+			ElementSetNo:       int(o.ElementSetNo),
+			RevAtEpoch:         int(o.RevAtEpoch),
+			BStar:              o.Bstar,
+			MeanMotionDot:      o.MeanMotionDot,
+			MeanMotionDDot:     o.MeanMotionDdot,
+		})
+	}
+	return nil, fmt.Errorf("no elements")
+}
+
+func tleFromProto(o *pb.TLE) (*tle.SGP4TLE, error) {
+	if len(o.Lines) != 3 {
+		return nil, fmt.Errorf("TLE has %d lines (not 3)", len(o.Lines))
+	}
+
+	parser := tle.NewSGP4TLE
+	if o.Gravity != "" || o.OpsMode != "" {
+		opts := sgp4.DefaultOptions
+		if o.Gravity != "" {
+			g, err := sgp4.ParseGravity(o.Gravity)
+			if err != nil {
+				return nil, err
+			}
+			opts.Gravity = g
+		}
+		if o.OpsMode != "" {
+			m, err := sgp4.ParseOpsMode(o.OpsMode)
+			if err != nil {
+				return nil, err
+			}
+			opts.OpsMode = m
+		}
+		parser = tle.NewSGP4TLEWith(opts)
+	}
+
+	p, err := parser(o.Lines[0], o.Lines[1], o.Lines[2])
+	if err != nil {
+		return nil, err
+	}
+	return p.(*tle.SGP4TLE), nil
+}
+
+// TLEToProto converts an SGP4TLE.
+func TLEToProto(o *tle.SGP4TLE) *pb.TLE {
+	if o == nil {
+		return nil
+	}
+	p := &pb.TLE{
+		CatNum: o.CatNum,
+		Lines:  o.TLE,
+	}
+	if o.Opts != nil {
+		p.Gravity = o.Opts.Gravity.String()
+		p.OpsMode = o.Opts.OpsMode.String()
+	}
+	return p
+}
+
+func vectToProto(v prop.Vect) *pb.Vect {
+	return &pb.Vect{
+		X: v.X,
+		Y: v.Y,
+		Z: v.Z,
+	}
+}
+
+// ReportToProto converts a Report.
+func ReportToProto(r *node.Report) (*pb.Report, error) {
+	generated, err := ptypes.TimestampProto(r.Generated)
+	if err != nil {
+		return nil, err
+	}
+	at, err := ptypes.TimestampProto(r.At)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pb.Report{
+		Schema:    int32(r.Schema),
+		Kind:      r.Kind,
+		Id:        r.Id,
+		Sig:       r.Sig,
+		Generated: generated,
+		At:        at,
+		Canceled:  r.Canceled,
+		Dist:      r.Dist,
+		Speed:     r.Speed,
+		Objs:      make([]*pb.State, 0, len(r.Objs)),
+		Tags:      r.Tags,
+	}
+
+	if o := r.Overflight; o != nil {
+		p.Overflight = &pb.Overflight{
+			Roi:   o.ROI,
+			Event: o.Event,
+		}
+	}
+
+	for _, s := range r.Objs {
+		p.Objs = append(p.Objs, &pb.State{
+			Name:      s.Name,
+			Obj:       TLEToProto(s.Obj),
+			Digest:    s.Digest,
+			Age:       s.Age,
+			Publisher: s.Publisher,
+			Type:      s.Type,
+			Eci:       vectToProto(s.ECI),
+			Vel:       vectToProto(s.Vel),
+			Lla: &pb.LatLonAlt{
+				Lat: s.LLA.Lat,
+				Lon: s.LLA.Lon,
+				Alt: s.LLA.Alt,
+			},
+			Light: lightingToProto(s.Light),
+		})
+	}
+
+	return p, nil
+}
+
+func lightingToProto(l *node.Lighting) *pb.Lighting {
+	if l == nil {
+		return nil
+	}
+	return &pb.Lighting{
+		Eclipse:  l.Eclipse,
+		Sunlight: l.Sunlight,
+		Beta:     l.Beta,
+		Phase:    l.Phase,
+	}
+}
+
+func lightingFromProto(l *pb.Lighting) *node.Lighting {
+	if l == nil {
+		return nil
+	}
+	return &node.Lighting{
+		Eclipse:  l.Eclipse,
+		Sunlight: l.Sunlight,
+		Beta:     l.Beta,
+		Phase:    l.Phase,
+	}
+}
+
+func vectFromProto(v *pb.Vect) prop.Vect {
+	if v == nil {
+		return prop.Vect{}
+	}
+	return prop.Vect{
+		X: v.X,
+		Y: v.Y,
+		Z: v.Z,
+	}
+}
+
+// ReportFromProto is the inverse of ReportToProto.
+func ReportFromProto(p *pb.Report) (*node.Report, error) {
+	generated, err := ptypes.Timestamp(p.Generated)
+	if err != nil {
+		return nil, err
+	}
+	at, err := ptypes.Timestamp(p.At)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &node.Report{
+		Schema:    int(p.Schema),
+		Kind:      p.Kind,
+		Id:        p.Id,
+		Sig:       p.Sig,
+		Generated: generated,
+		At:        at,
+		Canceled:  p.Canceled,
+		Dist:      p.Dist,
+		Speed:     p.Speed,
+		Objs:      make([]node.State, 0, len(p.Objs)),
+		Tags:      p.Tags,
+	}
+
+	if o := p.Overflight; o != nil {
+		r.Overflight = &node.Overflight{
+			ROI:   o.Roi,
+			Event: o.Event,
+		}
+	}
+
+	for i, s := range p.Objs {
+		var o *tle.SGP4TLE
+		if s.Obj != nil {
+			if o, err = tleFromProto(s.Obj); err != nil {
+				return nil, fmt.Errorf("obj %d: %s", i, err)
+			}
+		}
+		var lla node.LatLonAlt
+		if s.Lla != nil {
+			lla = node.LatLonAlt{
+				Lat: s.Lla.Lat,
+				Lon: s.Lla.Lon,
+				Alt: s.Lla.Alt,
+			}
+		}
+		r.Objs = append(r.Objs, node.State{
+			Name:      s.Name,
+			Obj:       o,
+			Digest:    s.Digest,
+			Age:       s.Age,
+			Publisher: s.Publisher,
+			Type:      s.Type,
+			ECI:       vectFromProto(s.Eci),
+			Vel:       vectFromProto(s.Vel),
+			LLA:       lla,
+			Light:     lightingFromProto(s.Light),
+		})
+	}
+
+	return r, nil
+}
+
+// MetricsToProto converts Metrics.
+func MetricsToProto(m *node.Metrics) (*pb.Metrics, error) {
+	t, err := ptypes.TimestampProto(m.T)
+	if err != nil {
+		return nil, err
+	}
+	t1, err := ptypes.TimestampProto(m.T1)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Metrics{
+		T:          t,
+		T1:         t1,
+		In:         m.In,
+		Live:       int64(m.Live),
+		Indexed:    int64(m.Indexed),
+		Slices:     int64(m.Slices),
+		Goroutines: int64(m.Goroutines),
+		LagNanos:   int64(m.Lag / time.Nanosecond),
+		Strings:    int64(m.Strings),
+	}, nil
+}
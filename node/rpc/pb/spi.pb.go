@@ -0,0 +1,1596 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: spi.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// TLE is a two-line element set.
+type TLE struct {
+	CatNum string `protobuf:"bytes,1,opt,name=cat_num,json=catNum,proto3" json:"cat_num,omitempty"`
+	// Lines are the name line (line 0), line 1, and line 2.
+	Lines []string `protobuf:"bytes,2,rep,name=lines,proto3" json:"lines,omitempty"`
+	// Gravity ("wgs72old", "wgs72", or "wgs84") and ops_mode
+	// ("afspc" or "improved") optionally give SGP4 options.
+	Gravity              string   `protobuf:"bytes,3,opt,name=gravity,proto3" json:"gravity,omitempty"`
+	OpsMode              string   `protobuf:"bytes,4,opt,name=ops_mode,json=opsMode,proto3" json:"ops_mode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TLE) Reset()         { *m = TLE{} }
+func (m *TLE) String() string { return proto.CompactTextString(m) }
+func (*TLE) ProtoMessage()    {}
+func (*TLE) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{0}
+}
+
+func (m *TLE) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TLE.Unmarshal(m, b)
+}
+func (m *TLE) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TLE.Marshal(b, m, deterministic)
+}
+func (m *TLE) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TLE.Merge(m, src)
+}
+func (m *TLE) XXX_Size() int {
+	return xxx_messageInfo_TLE.Size(m)
+}
+func (m *TLE) XXX_DiscardUnknown() {
+	xxx_messageInfo_TLE.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TLE proto.InternalMessageInfo
+
+func (m *TLE) GetCatNum() string {
+	if m != nil {
+		return m.CatNum
+	}
+	return ""
+}
+
+func (m *TLE) GetLines() []string {
+	if m != nil {
+		return m.Lines
+	}
+	return nil
+}
+
+func (m *TLE) GetGravity() string {
+	if m != nil {
+		return m.Gravity
+	}
+	return ""
+}
+
+func (m *TLE) GetOpsMode() string {
+	if m != nil {
+		return m.OpsMode
+	}
+	return ""
+}
+
+// OMM is the subset of a CCSDS Orbit Mean-Elements Message required
+// to construct a TLE.
+type OMM struct {
+	ObjectName string `protobuf:"bytes,1,opt,name=object_name,json=objectName,proto3" json:"object_name,omitempty"`
+	// Object_id is the international designator (example: "1998-067A").
+	ObjectId string `protobuf:"bytes,2,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	// Epoch is in UTC (example: "2020-09-20T12:25:40.104192").
+	Epoch                string   `protobuf:"bytes,3,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	MeanMotion           float64  `protobuf:"fixed64,4,opt,name=mean_motion,json=meanMotion,proto3" json:"mean_motion,omitempty"`
+	Eccentricity         float64  `protobuf:"fixed64,5,opt,name=eccentricity,proto3" json:"eccentricity,omitempty"`
+	Inclination          float64  `protobuf:"fixed64,6,opt,name=inclination,proto3" json:"inclination,omitempty"`
+	RaOfAscNode          float64  `protobuf:"fixed64,7,opt,name=ra_of_asc_node,json=raOfAscNode,proto3" json:"ra_of_asc_node,omitempty"`
+	ArgOfPericenter      float64  `protobuf:"fixed64,8,opt,name=arg_of_pericenter,json=argOfPericenter,proto3" json:"arg_of_pericenter,omitempty"`
+	MeanAnomaly          float64  `protobuf:"fixed64,9,opt,name=mean_anomaly,json=meanAnomaly,proto3" json:"mean_anomaly,omitempty"`
+	EphemerisType        int32    `protobuf:"varint,10,opt,name=ephemeris_type,json=ephemerisType,proto3" json:"ephemeris_type,omitempty"`
+	ClassificationType   string   `protobuf:"bytes,11,opt,name=classification_type,json=classificationType,proto3" json:"classification_type,omitempty"`
+	NoradCatId           int32    `protobuf:"varint,12,opt,name=norad_cat_id,json=noradCatId,proto3" json:"norad_cat_id,omitempty"`
+	ElementSetNo         int32    `protobuf:"varint,13,opt,name=element_set_no,json=elementSetNo,proto3" json:"element_set_no,omitempty"`
+	RevAtEpoch           int32    `protobuf:"varint,14,opt,name=rev_at_epoch,json=revAtEpoch,proto3" json:"rev_at_epoch,omitempty"`
+	Bstar                float64  `protobuf:"fixed64,15,opt,name=bstar,proto3" json:"bstar,omitempty"`
+	MeanMotionDot        float64  `protobuf:"fixed64,16,opt,name=mean_motion_dot,json=meanMotionDot,proto3" json:"mean_motion_dot,omitempty"`
+	MeanMotionDdot       float64  `protobuf:"fixed64,17,opt,name=mean_motion_ddot,json=meanMotionDdot,proto3" json:"mean_motion_ddot,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OMM) Reset()         { *m = OMM{} }
+func (m *OMM) String() string { return proto.CompactTextString(m) }
+func (*OMM) ProtoMessage()    {}
+func (*OMM) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{1}
+}
+
+func (m *OMM) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OMM.Unmarshal(m, b)
+}
+func (m *OMM) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OMM.Marshal(b, m, deterministic)
+}
+func (m *OMM) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OMM.Merge(m, src)
+}
+func (m *OMM) XXX_Size() int {
+	return xxx_messageInfo_OMM.Size(m)
+}
+func (m *OMM) XXX_DiscardUnknown() {
+	xxx_messageInfo_OMM.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OMM proto.InternalMessageInfo
+
+func (m *OMM) GetObjectName() string {
+	if m != nil {
+		return m.ObjectName
+	}
+	return ""
+}
+
+func (m *OMM) GetObjectId() string {
+	if m != nil {
+		return m.ObjectId
+	}
+	return ""
+}
+
+func (m *OMM) GetEpoch() string {
+	if m != nil {
+		return m.Epoch
+	}
+	return ""
+}
+
+func (m *OMM) GetMeanMotion() float64 {
+	if m != nil {
+		return m.MeanMotion
+	}
+	return 0
+}
+
+func (m *OMM) GetEccentricity() float64 {
+	if m != nil {
+		return m.Eccentricity
+	}
+	return 0
+}
+
+func (m *OMM) GetInclination() float64 {
+	if m != nil {
+		return m.Inclination
+	}
+	return 0
+}
+
+func (m *OMM) GetRaOfAscNode() float64 {
+	if m != nil {
+		return m.RaOfAscNode
+	}
+	return 0
+}
+
+func (m *OMM) GetArgOfPericenter() float64 {
+	if m != nil {
+		return m.ArgOfPericenter
+	}
+	return 0
+}
+
+func (m *OMM) GetMeanAnomaly() float64 {
+	if m != nil {
+		return m.MeanAnomaly
+	}
+	return 0
+}
+
+func (m *OMM) GetEphemerisType() int32 {
+	if m != nil {
+		return m.EphemerisType
+	}
+	return 0
+}
+
+func (m *OMM) GetClassificationType() string {
+	if m != nil {
+		return m.ClassificationType
+	}
+	return ""
+}
+
+func (m *OMM) GetNoradCatId() int32 {
+	if m != nil {
+		return m.NoradCatId
+	}
+	return 0
+}
+
+func (m *OMM) GetElementSetNo() int32 {
+	if m != nil {
+		return m.ElementSetNo
+	}
+	return 0
+}
+
+func (m *OMM) GetRevAtEpoch() int32 {
+	if m != nil {
+		return m.RevAtEpoch
+	}
+	return 0
+}
+
+func (m *OMM) GetBstar() float64 {
+	if m != nil {
+		return m.Bstar
+	}
+	return 0
+}
+
+func (m *OMM) GetMeanMotionDot() float64 {
+	if m != nil {
+		return m.MeanMotionDot
+	}
+	return 0
+}
+
+func (m *OMM) GetMeanMotionDdot() float64 {
+	if m != nil {
+		return m.MeanMotionDdot
+	}
+	return 0
+}
+
+// PubTLE associates a publisher with elements.
+type PubTLE struct {
+	Publisher string `protobuf:"bytes,1,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	// Types that are valid to be assigned to Elements:
+	//	*PubTLE_Tle
+	//	*PubTLE_Omm
+	Elements             isPubTLE_Elements `protobuf_oneof:"elements"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PubTLE) Reset()         { *m = PubTLE{} }
+func (m *PubTLE) String() string { return proto.CompactTextString(m) }
+func (*PubTLE) ProtoMessage()    {}
+func (*PubTLE) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{2}
+}
+
+func (m *PubTLE) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PubTLE.Unmarshal(m, b)
+}
+func (m *PubTLE) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PubTLE.Marshal(b, m, deterministic)
+}
+func (m *PubTLE) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PubTLE.Merge(m, src)
+}
+func (m *PubTLE) XXX_Size() int {
+	return xxx_messageInfo_PubTLE.Size(m)
+}
+func (m *PubTLE) XXX_DiscardUnknown() {
+	xxx_messageInfo_PubTLE.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PubTLE proto.InternalMessageInfo
+
+func (m *PubTLE) GetPublisher() string {
+	if m != nil {
+		return m.Publisher
+	}
+	return ""
+}
+
+type isPubTLE_Elements interface {
+	isPubTLE_Elements()
+}
+
+type PubTLE_Tle struct {
+	Tle *TLE `protobuf:"bytes,2,opt,name=tle,proto3,oneof"`
+}
+
+type PubTLE_Omm struct {
+	Omm *OMM `protobuf:"bytes,3,opt,name=omm,proto3,oneof"`
+}
+
+func (*PubTLE_Tle) isPubTLE_Elements() {}
+
+func (*PubTLE_Omm) isPubTLE_Elements() {}
+
+func (m *PubTLE) GetElements() isPubTLE_Elements {
+	if m != nil {
+		return m.Elements
+	}
+	return nil
+}
+
+func (m *PubTLE) GetTle() *TLE {
+	if x, ok := m.GetElements().(*PubTLE_Tle); ok {
+		return x.Tle
+	}
+	return nil
+}
+
+func (m *PubTLE) GetOmm() *OMM {
+	if x, ok := m.GetElements().(*PubTLE_Omm); ok {
+		return x.Omm
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*PubTLE) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*PubTLE_Tle)(nil),
+		(*PubTLE_Omm)(nil),
+	}
+}
+
+// Batch is a batch of in-coming elements.
+type Batch struct {
+	// Seq is an optional client sequence number that is returned in
+	// the Ack for this Batch.
+	Seq                  uint64    `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Tles                 []*PubTLE `protobuf:"bytes,2,rep,name=tles,proto3" json:"tles,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Batch) Reset()         { *m = Batch{} }
+func (m *Batch) String() string { return proto.CompactTextString(m) }
+func (*Batch) ProtoMessage()    {}
+func (*Batch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{3}
+}
+
+func (m *Batch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Batch.Unmarshal(m, b)
+}
+func (m *Batch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Batch.Marshal(b, m, deterministic)
+}
+func (m *Batch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Batch.Merge(m, src)
+}
+func (m *Batch) XXX_Size() int {
+	return xxx_messageInfo_Batch.Size(m)
+}
+func (m *Batch) XXX_DiscardUnknown() {
+	xxx_messageInfo_Batch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Batch proto.InternalMessageInfo
+
+func (m *Batch) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *Batch) GetTles() []*PubTLE {
+	if m != nil {
+		return m.Tles
+	}
+	return nil
+}
+
+// Ack acknowledges that a Batch has been processed.
+type Ack struct {
+	Seq uint64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Accepted is the number of elements given to the node.
+	Accepted int32 `protobuf:"varint,2,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	// Errors are problems with specific elements (which were not
+	// accepted).
+	Errors               []string `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Ack) Reset()         { *m = Ack{} }
+func (m *Ack) String() string { return proto.CompactTextString(m) }
+func (*Ack) ProtoMessage()    {}
+func (*Ack) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{4}
+}
+
+func (m *Ack) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Ack.Unmarshal(m, b)
+}
+func (m *Ack) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Ack.Marshal(b, m, deterministic)
+}
+func (m *Ack) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Ack.Merge(m, src)
+}
+func (m *Ack) XXX_Size() int {
+	return xxx_messageInfo_Ack.Size(m)
+}
+func (m *Ack) XXX_DiscardUnknown() {
+	xxx_messageInfo_Ack.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Ack proto.InternalMessageInfo
+
+func (m *Ack) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *Ack) GetAccepted() int32 {
+	if m != nil {
+		return m.Accepted
+	}
+	return 0
+}
+
+func (m *Ack) GetErrors() []string {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+type Vect struct {
+	X                    float32  `protobuf:"fixed32,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y                    float32  `protobuf:"fixed32,2,opt,name=y,proto3" json:"y,omitempty"`
+	Z                    float32  `protobuf:"fixed32,3,opt,name=z,proto3" json:"z,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Vect) Reset()         { *m = Vect{} }
+func (m *Vect) String() string { return proto.CompactTextString(m) }
+func (*Vect) ProtoMessage()    {}
+func (*Vect) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{5}
+}
+
+func (m *Vect) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Vect.Unmarshal(m, b)
+}
+func (m *Vect) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Vect.Marshal(b, m, deterministic)
+}
+func (m *Vect) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Vect.Merge(m, src)
+}
+func (m *Vect) XXX_Size() int {
+	return xxx_messageInfo_Vect.Size(m)
+}
+func (m *Vect) XXX_DiscardUnknown() {
+	xxx_messageInfo_Vect.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Vect proto.InternalMessageInfo
+
+func (m *Vect) GetX() float32 {
+	if m != nil {
+		return m.X
+	}
+	return 0
+}
+
+func (m *Vect) GetY() float32 {
+	if m != nil {
+		return m.Y
+	}
+	return 0
+}
+
+func (m *Vect) GetZ() float32 {
+	if m != nil {
+		return m.Z
+	}
+	return 0
+}
+
+// LatLonAlt is latitude (deg), longitude (deg), and altitude (km).
+type LatLonAlt struct {
+	Lat                  float32  `protobuf:"fixed32,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon                  float32  `protobuf:"fixed32,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	Alt                  float32  `protobuf:"fixed32,3,opt,name=alt,proto3" json:"alt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LatLonAlt) Reset()         { *m = LatLonAlt{} }
+func (m *LatLonAlt) String() string { return proto.CompactTextString(m) }
+func (*LatLonAlt) ProtoMessage()    {}
+func (*LatLonAlt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{6}
+}
+
+func (m *LatLonAlt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LatLonAlt.Unmarshal(m, b)
+}
+func (m *LatLonAlt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LatLonAlt.Marshal(b, m, deterministic)
+}
+func (m *LatLonAlt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LatLonAlt.Merge(m, src)
+}
+func (m *LatLonAlt) XXX_Size() int {
+	return xxx_messageInfo_LatLonAlt.Size(m)
+}
+func (m *LatLonAlt) XXX_DiscardUnknown() {
+	xxx_messageInfo_LatLonAlt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LatLonAlt proto.InternalMessageInfo
+
+func (m *LatLonAlt) GetLat() float32 {
+	if m != nil {
+		return m.Lat
+	}
+	return 0
+}
+
+func (m *LatLonAlt) GetLon() float32 {
+	if m != nil {
+		return m.Lon
+	}
+	return 0
+}
+
+func (m *LatLonAlt) GetAlt() float32 {
+	if m != nil {
+		return m.Alt
+	}
+	return 0
+}
+
+// Lighting is an object's illumination by the Sun.
+type Lighting struct {
+	// Eclipse is "sunlit", "penumbra", or "umbra".
+	Eclipse string `protobuf:"bytes,1,opt,name=eclipse,proto3" json:"eclipse,omitempty"`
+	// Sunlight is the visible fraction of the Sun's disk.
+	Sunlight float32 `protobuf:"fixed32,2,opt,name=sunlight,proto3" json:"sunlight,omitempty"`
+	// Beta is the solar beta angle (deg).
+	Beta float32 `protobuf:"fixed32,3,opt,name=beta,proto3" json:"beta,omitempty"`
+	// Phase is the angle (deg) at the object between the Sun and the
+	// Earth's center.
+	Phase                float32  `protobuf:"fixed32,4,opt,name=phase,proto3" json:"phase,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Lighting) Reset()         { *m = Lighting{} }
+func (m *Lighting) String() string { return proto.CompactTextString(m) }
+func (*Lighting) ProtoMessage()    {}
+func (*Lighting) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{7}
+}
+
+func (m *Lighting) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Lighting.Unmarshal(m, b)
+}
+func (m *Lighting) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Lighting.Marshal(b, m, deterministic)
+}
+func (m *Lighting) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Lighting.Merge(m, src)
+}
+func (m *Lighting) XXX_Size() int {
+	return xxx_messageInfo_Lighting.Size(m)
+}
+func (m *Lighting) XXX_DiscardUnknown() {
+	xxx_messageInfo_Lighting.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Lighting proto.InternalMessageInfo
+
+func (m *Lighting) GetEclipse() string {
+	if m != nil {
+		return m.Eclipse
+	}
+	return ""
+}
+
+func (m *Lighting) GetSunlight() float32 {
+	if m != nil {
+		return m.Sunlight
+	}
+	return 0
+}
+
+func (m *Lighting) GetBeta() float32 {
+	if m != nil {
+		return m.Beta
+	}
+	return 0
+}
+
+func (m *Lighting) GetPhase() float32 {
+	if m != nil {
+		return m.Phase
+	}
+	return 0
+}
+
+// State is the state of an object at the time of a Report.
+type State struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Obj  *TLE   `protobuf:"bytes,2,opt,name=obj,proto3" json:"obj,omitempty"`
+	// Age is the age of the TLE in seconds.
+	Age  int64  `protobuf:"varint,3,opt,name=age,proto3" json:"age,omitempty"`
+	Type string `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	// Eci is the position (km).
+	Eci *Vect `protobuf:"bytes,5,opt,name=eci,proto3" json:"eci,omitempty"`
+	// Vel is the velocity (km/s).
+	Vel       *Vect      `protobuf:"bytes,6,opt,name=vel,proto3" json:"vel,omitempty"`
+	Lla       *LatLonAlt `protobuf:"bytes,7,opt,name=lla,proto3" json:"lla,omitempty"`
+	Publisher string     `protobuf:"bytes,8,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	// Digest identifies the TLE when obj is absent (compact reports).
+	Digest               string    `protobuf:"bytes,9,opt,name=digest,proto3" json:"digest,omitempty"`
+	Light                *Lighting `protobuf:"bytes,10,opt,name=light,proto3" json:"light,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *State) Reset()         { *m = State{} }
+func (m *State) String() string { return proto.CompactTextString(m) }
+func (*State) ProtoMessage()    {}
+func (*State) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{8}
+}
+
+func (m *State) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_State.Unmarshal(m, b)
+}
+func (m *State) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_State.Marshal(b, m, deterministic)
+}
+func (m *State) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_State.Merge(m, src)
+}
+func (m *State) XXX_Size() int {
+	return xxx_messageInfo_State.Size(m)
+}
+func (m *State) XXX_DiscardUnknown() {
+	xxx_messageInfo_State.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_State proto.InternalMessageInfo
+
+func (m *State) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *State) GetObj() *TLE {
+	if m != nil {
+		return m.Obj
+	}
+	return nil
+}
+
+func (m *State) GetAge() int64 {
+	if m != nil {
+		return m.Age
+	}
+	return 0
+}
+
+func (m *State) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *State) GetEci() *Vect {
+	if m != nil {
+		return m.Eci
+	}
+	return nil
+}
+
+func (m *State) GetVel() *Vect {
+	if m != nil {
+		return m.Vel
+	}
+	return nil
+}
+
+func (m *State) GetLla() *LatLonAlt {
+	if m != nil {
+		return m.Lla
+	}
+	return nil
+}
+
+func (m *State) GetPublisher() string {
+	if m != nil {
+		return m.Publisher
+	}
+	return ""
+}
+
+func (m *State) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *State) GetLight() *Lighting {
+	if m != nil {
+		return m.Light
+	}
+	return nil
+}
+
+// Overflight is an object entering or exiting a region of interest.
+type Overflight struct {
+	Roi string `protobuf:"bytes,1,opt,name=roi,proto3" json:"roi,omitempty"`
+	// Event is "enter" or "exit".
+	Event                string   `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Overflight) Reset()         { *m = Overflight{} }
+func (m *Overflight) String() string { return proto.CompactTextString(m) }
+func (*Overflight) ProtoMessage()    {}
+func (*Overflight) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{9}
+}
+
+func (m *Overflight) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Overflight.Unmarshal(m, b)
+}
+func (m *Overflight) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Overflight.Marshal(b, m, deterministic)
+}
+func (m *Overflight) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Overflight.Merge(m, src)
+}
+func (m *Overflight) XXX_Size() int {
+	return xxx_messageInfo_Overflight.Size(m)
+}
+func (m *Overflight) XXX_DiscardUnknown() {
+	xxx_messageInfo_Overflight.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Overflight proto.InternalMessageInfo
+
+func (m *Overflight) GetRoi() string {
+	if m != nil {
+		return m.Roi
+	}
+	return ""
+}
+
+func (m *Overflight) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+// Report is a conjunction report.
+type Report struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Sig is the signature for this report without consideration of
+	// canceled and generated.
+	Sig       string               `protobuf:"bytes,2,opt,name=sig,proto3" json:"sig,omitempty"`
+	Generated *timestamp.Timestamp `protobuf:"bytes,3,opt,name=generated,proto3" json:"generated,omitempty"`
+	At        *timestamp.Timestamp `protobuf:"bytes,4,opt,name=at,proto3" json:"at,omitempty"`
+	Canceled  bool                 `protobuf:"varint,5,opt,name=canceled,proto3" json:"canceled,omitempty"`
+	// Dist is the distance (km).
+	Dist float32 `protobuf:"fixed32,6,opt,name=dist,proto3" json:"dist,omitempty"`
+	// Speed is the relative speed (km/s).
+	Speed float32  `protobuf:"fixed32,7,opt,name=speed,proto3" json:"speed,omitempty"`
+	Objs  []*State `protobuf:"bytes,8,rep,name=objs,proto3" json:"objs,omitempty"`
+	// Kind is empty for a conjunction, "consistency" for a divergence
+	// between publishers' TLEs for one object, "overflight" for an
+	// object entering or exiting a region of interest, and "what-if" for
+	// a close approach between a hypothetical object and a live object.
+	Kind string   `protobuf:"bytes,9,opt,name=kind,proto3" json:"kind,omitempty"`
+	Tags []string `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Schema is the report schema version.
+	Schema int32 `protobuf:"varint,11,opt,name=schema,proto3" json:"schema,omitempty"`
+	// Overflight is present when kind is "overflight".
+	Overflight           *Overflight `protobuf:"bytes,12,opt,name=overflight,proto3" json:"overflight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Report) Reset()         { *m = Report{} }
+func (m *Report) String() string { return proto.CompactTextString(m) }
+func (*Report) ProtoMessage()    {}
+func (*Report) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{10}
+}
+
+func (m *Report) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Report.Unmarshal(m, b)
+}
+func (m *Report) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Report.Marshal(b, m, deterministic)
+}
+func (m *Report) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Report.Merge(m, src)
+}
+func (m *Report) XXX_Size() int {
+	return xxx_messageInfo_Report.Size(m)
+}
+func (m *Report) XXX_DiscardUnknown() {
+	xxx_messageInfo_Report.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Report proto.InternalMessageInfo
+
+func (m *Report) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Report) GetSig() string {
+	if m != nil {
+		return m.Sig
+	}
+	return ""
+}
+
+func (m *Report) GetGenerated() *timestamp.Timestamp {
+	if m != nil {
+		return m.Generated
+	}
+	return nil
+}
+
+func (m *Report) GetAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.At
+	}
+	return nil
+}
+
+func (m *Report) GetCanceled() bool {
+	if m != nil {
+		return m.Canceled
+	}
+	return false
+}
+
+func (m *Report) GetDist() float32 {
+	if m != nil {
+		return m.Dist
+	}
+	return 0
+}
+
+func (m *Report) GetSpeed() float32 {
+	if m != nil {
+		return m.Speed
+	}
+	return 0
+}
+
+func (m *Report) GetObjs() []*State {
+	if m != nil {
+		return m.Objs
+	}
+	return nil
+}
+
+func (m *Report) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Report) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Report) GetSchema() int32 {
+	if m != nil {
+		return m.Schema
+	}
+	return 0
+}
+
+func (m *Report) GetOverflight() *Overflight {
+	if m != nil {
+		return m.Overflight
+	}
+	return nil
+}
+
+type Metrics struct {
+	T                    *timestamp.Timestamp `protobuf:"bytes,1,opt,name=t,proto3" json:"t,omitempty"`
+	T1                   *timestamp.Timestamp `protobuf:"bytes,2,opt,name=t1,proto3" json:"t1,omitempty"`
+	In                   uint64               `protobuf:"varint,3,opt,name=in,proto3" json:"in,omitempty"`
+	Live                 int64                `protobuf:"varint,4,opt,name=live,proto3" json:"live,omitempty"`
+	Indexed              int64                `protobuf:"varint,5,opt,name=indexed,proto3" json:"indexed,omitempty"`
+	Slices               int64                `protobuf:"varint,6,opt,name=slices,proto3" json:"slices,omitempty"`
+	Goroutines           int64                `protobuf:"varint,7,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	LagNanos             int64                `protobuf:"varint,8,opt,name=lag_nanos,json=lagNanos,proto3" json:"lag_nanos,omitempty"`
+	Strings              int64                `protobuf:"varint,9,opt,name=strings,proto3" json:"strings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Metrics) Reset()         { *m = Metrics{} }
+func (m *Metrics) String() string { return proto.CompactTextString(m) }
+func (*Metrics) ProtoMessage()    {}
+func (*Metrics) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{11}
+}
+
+func (m *Metrics) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Metrics.Unmarshal(m, b)
+}
+func (m *Metrics) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Metrics.Marshal(b, m, deterministic)
+}
+func (m *Metrics) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Metrics.Merge(m, src)
+}
+func (m *Metrics) XXX_Size() int {
+	return xxx_messageInfo_Metrics.Size(m)
+}
+func (m *Metrics) XXX_DiscardUnknown() {
+	xxx_messageInfo_Metrics.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Metrics proto.InternalMessageInfo
+
+func (m *Metrics) GetT() *timestamp.Timestamp {
+	if m != nil {
+		return m.T
+	}
+	return nil
+}
+
+func (m *Metrics) GetT1() *timestamp.Timestamp {
+	if m != nil {
+		return m.T1
+	}
+	return nil
+}
+
+func (m *Metrics) GetIn() uint64 {
+	if m != nil {
+		return m.In
+	}
+	return 0
+}
+
+func (m *Metrics) GetLive() int64 {
+	if m != nil {
+		return m.Live
+	}
+	return 0
+}
+
+func (m *Metrics) GetIndexed() int64 {
+	if m != nil {
+		return m.Indexed
+	}
+	return 0
+}
+
+func (m *Metrics) GetSlices() int64 {
+	if m != nil {
+		return m.Slices
+	}
+	return 0
+}
+
+func (m *Metrics) GetGoroutines() int64 {
+	if m != nil {
+		return m.Goroutines
+	}
+	return 0
+}
+
+func (m *Metrics) GetLagNanos() int64 {
+	if m != nil {
+		return m.LagNanos
+	}
+	return 0
+}
+
+func (m *Metrics) GetStrings() int64 {
+	if m != nil {
+		return m.Strings
+	}
+	return 0
+}
+
+type Error struct {
+	Message              string   `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Warning              bool     `protobuf:"varint,2,opt,name=warning,proto3" json:"warning,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Error) Reset()         { *m = Error{} }
+func (m *Error) String() string { return proto.CompactTextString(m) }
+func (*Error) ProtoMessage()    {}
+func (*Error) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{12}
+}
+
+func (m *Error) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Error.Unmarshal(m, b)
+}
+func (m *Error) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Error.Marshal(b, m, deterministic)
+}
+func (m *Error) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Error.Merge(m, src)
+}
+func (m *Error) XXX_Size() int {
+	return xxx_messageInfo_Error.Size(m)
+}
+func (m *Error) XXX_DiscardUnknown() {
+	xxx_messageInfo_Error.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Error proto.InternalMessageInfo
+
+func (m *Error) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Error) GetWarning() bool {
+	if m != nil {
+		return m.Warning
+	}
+	return false
+}
+
+type Event struct {
+	// Types that are valid to be assigned to Event:
+	//	*Event_Ack
+	//	*Event_Report
+	//	*Event_Metrics
+	//	*Event_Error
+	Event                isEvent_Event `protobuf_oneof:"event"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{13}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+type isEvent_Event interface {
+	isEvent_Event()
+}
+
+type Event_Ack struct {
+	Ack *Ack `protobuf:"bytes,1,opt,name=ack,proto3,oneof"`
+}
+
+type Event_Report struct {
+	Report *Report `protobuf:"bytes,2,opt,name=report,proto3,oneof"`
+}
+
+type Event_Metrics struct {
+	Metrics *Metrics `protobuf:"bytes,3,opt,name=metrics,proto3,oneof"`
+}
+
+type Event_Error struct {
+	Error *Error `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
+}
+
+func (*Event_Ack) isEvent_Event() {}
+
+func (*Event_Report) isEvent_Event() {}
+
+func (*Event_Metrics) isEvent_Event() {}
+
+func (*Event_Error) isEvent_Event() {}
+
+func (m *Event) GetEvent() isEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *Event) GetAck() *Ack {
+	if x, ok := m.GetEvent().(*Event_Ack); ok {
+		return x.Ack
+	}
+	return nil
+}
+
+func (m *Event) GetReport() *Report {
+	if x, ok := m.GetEvent().(*Event_Report); ok {
+		return x.Report
+	}
+	return nil
+}
+
+func (m *Event) GetMetrics() *Metrics {
+	if x, ok := m.GetEvent().(*Event_Metrics); ok {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (m *Event) GetError() *Error {
+	if x, ok := m.GetEvent().(*Event_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Event) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Event_Ack)(nil),
+		(*Event_Report)(nil),
+		(*Event_Metrics)(nil),
+		(*Event_Error)(nil),
+	}
+}
+
+type SubscribeRequest struct {
+	Reports              bool     `protobuf:"varint,1,opt,name=reports,proto3" json:"reports,omitempty"`
+	Metrics              bool     `protobuf:"varint,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	Errors               bool     `protobuf:"varint,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribeRequest) Reset()         { *m = SubscribeRequest{} }
+func (m *SubscribeRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeRequest) ProtoMessage()    {}
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a76ae6831bd925c, []int{14}
+}
+
+func (m *SubscribeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeRequest.Unmarshal(m, b)
+}
+func (m *SubscribeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeRequest.Marshal(b, m, deterministic)
+}
+func (m *SubscribeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeRequest.Merge(m, src)
+}
+func (m *SubscribeRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribeRequest.Size(m)
+}
+func (m *SubscribeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeRequest proto.InternalMessageInfo
+
+func (m *SubscribeRequest) GetReports() bool {
+	if m != nil {
+		return m.Reports
+	}
+	return false
+}
+
+func (m *SubscribeRequest) GetMetrics() bool {
+	if m != nil {
+		return m.Metrics
+	}
+	return false
+}
+
+func (m *SubscribeRequest) GetErrors() bool {
+	if m != nil {
+		return m.Errors
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*TLE)(nil), "spi.TLE")
+	proto.RegisterType((*OMM)(nil), "spi.OMM")
+	proto.RegisterType((*PubTLE)(nil), "spi.PubTLE")
+	proto.RegisterType((*Batch)(nil), "spi.Batch")
+	proto.RegisterType((*Ack)(nil), "spi.Ack")
+	proto.RegisterType((*Vect)(nil), "spi.Vect")
+	proto.RegisterType((*LatLonAlt)(nil), "spi.LatLonAlt")
+	proto.RegisterType((*Lighting)(nil), "spi.Lighting")
+	proto.RegisterType((*State)(nil), "spi.State")
+	proto.RegisterType((*Overflight)(nil), "spi.Overflight")
+	proto.RegisterType((*Report)(nil), "spi.Report")
+	proto.RegisterType((*Metrics)(nil), "spi.Metrics")
+	proto.RegisterType((*Error)(nil), "spi.Error")
+	proto.RegisterType((*Event)(nil), "spi.Event")
+	proto.RegisterType((*SubscribeRequest)(nil), "spi.SubscribeRequest")
+}
+
+func init() {
+	proto.RegisterFile("spi.proto", fileDescriptor_2a76ae6831bd925c)
+}
+
+var fileDescriptor_2a76ae6831bd925c = []byte{
+	// 1363 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x56, 0xcd, 0x72, 0x1b, 0xb9,
+	0x11, 0x16, 0x67, 0x48, 0x8a, 0x6c, 0xea, 0xcf, 0x88, 0xe3, 0x4c, 0x64, 0x97, 0xad, 0x8c, 0x7f,
+	0x4a, 0xe5, 0xaa, 0x88, 0xb2, 0x92, 0x43, 0x2a, 0x39, 0x49, 0x89, 0xaa, 0xe4, 0x8a, 0x7e, 0x5c,
+	0x90, 0x2a, 0x87, 0x1c, 0x96, 0x05, 0xce, 0x40, 0x43, 0x48, 0x33, 0xc0, 0x78, 0x00, 0x72, 0x25,
+	0x5f, 0xf6, 0x05, 0xf6, 0xb8, 0x6f, 0xb0, 0xef, 0xb5, 0xd7, 0x7d, 0x8d, 0xad, 0x6e, 0x80, 0x34,
+	0xe5, 0xdd, 0x5a, 0xdf, 0xfa, 0xfb, 0xba, 0xa7, 0xd1, 0xe8, 0x3f, 0x0c, 0xf4, 0x6d, 0xad, 0xf6,
+	0xea, 0xc6, 0x38, 0xc3, 0x62, 0x5b, 0xab, 0xed, 0x17, 0x85, 0x31, 0x45, 0x29, 0x87, 0x44, 0x8d,
+	0xa7, 0xd7, 0x43, 0xa7, 0x2a, 0x69, 0x9d, 0xa8, 0x6a, 0x6f, 0x95, 0x2a, 0x88, 0xaf, 0x4e, 0x8f,
+	0xd9, 0x9f, 0x60, 0x35, 0x13, 0x6e, 0xa4, 0xa7, 0x55, 0xd2, 0xda, 0x69, 0xed, 0xf6, 0x79, 0x37,
+	0x13, 0xee, 0x7c, 0x5a, 0xb1, 0xc7, 0xd0, 0x29, 0x95, 0x96, 0x36, 0x89, 0x76, 0xe2, 0xdd, 0x3e,
+	0xf7, 0x80, 0x25, 0xb0, 0x5a, 0x34, 0x62, 0xa6, 0xdc, 0x7d, 0x12, 0x93, 0xf9, 0x1c, 0xb2, 0x3f,
+	0x43, 0xcf, 0xd4, 0x76, 0x54, 0x99, 0x5c, 0x26, 0x6d, 0xaf, 0x32, 0xb5, 0x3d, 0x33, 0xb9, 0x4c,
+	0x7f, 0x6a, 0x43, 0x7c, 0x71, 0x76, 0xc6, 0x5e, 0xc0, 0xc0, 0x8c, 0x6f, 0x64, 0xe6, 0x46, 0x5a,
+	0x54, 0x32, 0x9c, 0x07, 0x9e, 0x3a, 0x17, 0x95, 0x64, 0x4f, 0xa1, 0x1f, 0x0c, 0x54, 0x9e, 0x44,
+	0xa4, 0xee, 0x79, 0xe2, 0x7d, 0x8e, 0x01, 0xc9, 0xda, 0x64, 0x93, 0x70, 0xb0, 0x07, 0xe8, 0xb3,
+	0x92, 0x42, 0x8f, 0x2a, 0xe3, 0x94, 0xd1, 0x74, 0x72, 0x8b, 0x03, 0x52, 0x67, 0xc4, 0xb0, 0x14,
+	0xd6, 0x64, 0x96, 0x49, 0xed, 0x1a, 0x95, 0x61, 0xd8, 0x1d, 0xb2, 0x78, 0xc0, 0xb1, 0x1d, 0x18,
+	0x28, 0x9d, 0x95, 0x4a, 0x0b, 0x72, 0xd2, 0x25, 0x93, 0x65, 0x8a, 0xbd, 0x84, 0x8d, 0x46, 0x8c,
+	0xcc, 0xf5, 0x48, 0xd8, 0x6c, 0xa4, 0xf1, 0x8e, 0xab, 0xde, 0xa8, 0x11, 0x17, 0xd7, 0x87, 0x36,
+	0x3b, 0x37, 0xb9, 0x64, 0x6f, 0xe1, 0x91, 0x68, 0x0a, 0xb4, 0xaa, 0x65, 0xa3, 0xd0, 0xbf, 0x6c,
+	0x92, 0x1e, 0xd9, 0x6d, 0x8a, 0xa6, 0xb8, 0xb8, 0xfe, 0xb0, 0xa0, 0xd9, 0x5f, 0x60, 0x8d, 0xe2,
+	0x16, 0xda, 0x54, 0xa2, 0xbc, 0x4f, 0xfa, 0xde, 0x1d, 0x72, 0x87, 0x9e, 0x62, 0xaf, 0x61, 0x43,
+	0xd6, 0x13, 0x59, 0xc9, 0x46, 0xd9, 0x91, 0xbb, 0xaf, 0x65, 0x02, 0x3b, 0xad, 0xdd, 0x0e, 0x5f,
+	0x5f, 0xb0, 0x57, 0xf7, 0xb5, 0x64, 0x43, 0xf8, 0x43, 0x56, 0x0a, 0x6b, 0xd5, 0xb5, 0xca, 0x28,
+	0x58, 0x6f, 0x3b, 0xa0, 0x2c, 0xb1, 0x87, 0x2a, 0xfa, 0x60, 0x07, 0xd6, 0xb4, 0x69, 0x44, 0x3e,
+	0xc2, 0xc2, 0xab, 0x3c, 0x59, 0x23, 0xaf, 0x40, 0xdc, 0xbf, 0x05, 0xa6, 0xfa, 0x15, 0x6c, 0xc8,
+	0x52, 0x56, 0x52, 0xbb, 0x91, 0x95, 0x6e, 0xa4, 0x4d, 0xb2, 0x4e, 0x36, 0x6b, 0x81, 0xbd, 0x94,
+	0xee, 0xdc, 0xa0, 0x9f, 0x46, 0xce, 0x46, 0xc2, 0x8d, 0x7c, 0x5d, 0x36, 0xbc, 0x9f, 0x46, 0xce,
+	0x0e, 0xdd, 0x31, 0x15, 0xe7, 0x31, 0x74, 0xc6, 0xd6, 0x89, 0x26, 0xd9, 0xa4, 0xdb, 0x79, 0xc0,
+	0xde, 0xc0, 0xe6, 0x52, 0xc9, 0x46, 0xb9, 0x71, 0xc9, 0x16, 0xe9, 0xd7, 0x3f, 0x97, 0xed, 0x3f,
+	0xc6, 0xb1, 0x5d, 0xd8, 0x7a, 0x60, 0x87, 0x86, 0x8f, 0xc8, 0x70, 0x63, 0xc9, 0x30, 0x37, 0x2e,
+	0xd5, 0xd0, 0xfd, 0x30, 0x1d, 0x63, 0x3b, 0x3f, 0x83, 0x7e, 0x3d, 0x1d, 0x97, 0xca, 0x4e, 0x64,
+	0x13, 0x1a, 0xec, 0x33, 0xc1, 0x9e, 0x41, 0xec, 0x4a, 0x49, 0x9d, 0x35, 0x38, 0xe8, 0xed, 0xe1,
+	0xc8, 0x5c, 0x9d, 0x1e, 0x9f, 0xac, 0x70, 0xa4, 0x51, 0x6b, 0xaa, 0x2a, 0x89, 0x97, 0xb4, 0x17,
+	0x67, 0x67, 0xa8, 0x35, 0x55, 0x75, 0x04, 0xd0, 0x0b, 0xb7, 0xb7, 0xe9, 0x3f, 0xa1, 0x73, 0x24,
+	0x5c, 0x36, 0x61, 0x5b, 0x10, 0x5b, 0xf9, 0x91, 0x0e, 0x6a, 0x73, 0x14, 0xd9, 0x0b, 0x68, 0xbb,
+	0x32, 0x4c, 0xcd, 0xe0, 0x60, 0x40, 0x5e, 0x7c, 0x6c, 0x9c, 0x14, 0xe9, 0x7f, 0x21, 0x3e, 0xcc,
+	0x6e, 0x7f, 0xe3, 0xcb, 0x6d, 0xe8, 0x89, 0x2c, 0x93, 0xb5, 0x93, 0xbe, 0xf7, 0x3b, 0x7c, 0x81,
+	0xd9, 0x13, 0xe8, 0xca, 0xa6, 0x31, 0x8d, 0x4d, 0x62, 0x9a, 0xc6, 0x80, 0xd2, 0x7d, 0x68, 0xff,
+	0x4f, 0x66, 0x8e, 0xad, 0x41, 0xeb, 0x8e, 0x7c, 0x45, 0xbc, 0x75, 0x87, 0xe8, 0x9e, 0x5c, 0x44,
+	0xbc, 0x75, 0x8f, 0xe8, 0x13, 0x5d, 0x2a, 0xe2, 0xad, 0x4f, 0xe9, 0x21, 0xf4, 0x4f, 0x85, 0x3b,
+	0x35, 0xfa, 0xb0, 0x74, 0x18, 0x44, 0x29, 0x5c, 0xf8, 0x10, 0x45, 0x62, 0x8c, 0x0e, 0x1f, 0xa3,
+	0x88, 0x8c, 0x28, 0x5d, 0x70, 0x80, 0x62, 0x7a, 0x03, 0xbd, 0x53, 0x55, 0x4c, 0x9c, 0xd2, 0x05,
+	0xee, 0x03, 0x99, 0x95, 0xaa, 0xb6, 0xf3, 0x71, 0x9e, 0x43, 0xbc, 0x8e, 0x9d, 0xea, 0x12, 0x0d,
+	0x83, 0xbb, 0x05, 0x66, 0x0c, 0xda, 0x63, 0xe9, 0x44, 0x70, 0x4a, 0x32, 0xf6, 0x4a, 0x3d, 0x11,
+	0xd6, 0x2f, 0x8f, 0x88, 0x7b, 0x90, 0xfe, 0x10, 0x41, 0xe7, 0xd2, 0x09, 0x27, 0xf1, 0x9b, 0xa5,
+	0xad, 0x41, 0x32, 0xdb, 0x86, 0xd8, 0x8c, 0x6f, 0xbe, 0xac, 0x27, 0x47, 0x92, 0xe2, 0x2e, 0x24,
+	0x1d, 0x11, 0x73, 0x14, 0xd1, 0x03, 0x4d, 0x86, 0xdf, 0x4e, 0x24, 0xb3, 0xa7, 0x10, 0xcb, 0x4c,
+	0xd1, 0x52, 0x18, 0x1c, 0xf4, 0xc9, 0x03, 0x26, 0x94, 0x23, 0x8b, 0xca, 0x99, 0x2c, 0x93, 0xee,
+	0xaf, 0x94, 0x33, 0x59, 0xb2, 0x1d, 0x88, 0xcb, 0x52, 0xd0, 0x1a, 0x18, 0x1c, 0x6c, 0x90, 0x72,
+	0x91, 0x58, 0x8e, 0xaa, 0x87, 0xbd, 0xd8, 0xfb, 0xb2, 0x17, 0x9f, 0x40, 0x37, 0x57, 0x85, 0xb4,
+	0x8e, 0x46, 0xbf, 0xcf, 0x03, 0x62, 0x2f, 0xa1, 0xe3, 0x93, 0x06, 0xe4, 0x79, 0xdd, 0x7b, 0x0e,
+	0xf9, 0xe6, 0x5e, 0x97, 0xfe, 0x1d, 0xe0, 0x62, 0x26, 0x9b, 0x6b, 0x42, 0x78, 0xd5, 0xc6, 0xa8,
+	0x90, 0x19, 0x14, 0x69, 0x57, 0xce, 0xa4, 0x76, 0x61, 0x89, 0x7a, 0x90, 0xfe, 0x1c, 0x41, 0x97,
+	0xcb, 0xda, 0x34, 0x8e, 0x6d, 0x40, 0xa4, 0xf2, 0xf0, 0x45, 0xa4, 0x72, 0x6a, 0x47, 0x55, 0x04,
+	0x73, 0x14, 0xd9, 0x3f, 0xa0, 0x5f, 0x48, 0x2d, 0x1b, 0x81, 0xfd, 0xe8, 0x67, 0x62, 0x7b, 0xcf,
+	0x3f, 0x2a, 0x7b, 0xf3, 0x47, 0x65, 0xef, 0x6a, 0xfe, 0xa8, 0xf0, 0xcf, 0xc6, 0xec, 0x2d, 0x44,
+	0xc2, 0x25, 0xed, 0xaf, 0x7e, 0x12, 0x09, 0x87, 0x5d, 0x92, 0x09, 0x9d, 0xc9, 0x52, 0xe6, 0x54,
+	0x84, 0x1e, 0x5f, 0x60, 0xac, 0x57, 0xae, 0xac, 0xa3, 0xfc, 0x47, 0x9c, 0x64, 0xbc, 0x98, 0xad,
+	0xa5, 0xcc, 0x29, 0xef, 0x11, 0xf7, 0x80, 0x3d, 0x87, 0xb6, 0x19, 0xdf, 0xd8, 0xa4, 0x47, 0x43,
+	0x07, 0x94, 0x32, 0xea, 0x1a, 0x4e, 0x3c, 0x7a, 0xba, 0x55, 0x3a, 0x0f, 0x99, 0x26, 0x19, 0x39,
+	0x27, 0x0a, 0x9b, 0x00, 0x0d, 0x14, 0xc9, 0x58, 0x13, 0x9b, 0x4d, 0x64, 0x25, 0x68, 0x7b, 0x76,
+	0x78, 0x40, 0x6c, 0x08, 0x60, 0x16, 0xe9, 0xa6, 0x7d, 0x39, 0x38, 0xd8, 0xf4, 0x0b, 0x62, 0x41,
+	0xf3, 0x25, 0x93, 0xf4, 0xfb, 0x08, 0x56, 0xcf, 0x24, 0x3e, 0x2f, 0x96, 0xed, 0x42, 0xcb, 0x8f,
+	0xd8, 0xef, 0x67, 0xa3, 0xe5, 0x30, 0x71, 0xee, 0x5d, 0x12, 0x7d, 0xd5, 0x34, 0x72, 0xef, 0xa8,
+	0x80, 0x9a, 0xea, 0xd2, 0xe6, 0x91, 0xd2, 0x78, 0x9d, 0x52, 0xcd, 0x7c, 0x73, 0xc7, 0x9c, 0x64,
+	0x1c, 0x4e, 0xa5, 0x73, 0x79, 0x17, 0x72, 0x1b, 0xf3, 0x39, 0xa4, 0x8b, 0x96, 0x2a, 0x93, 0x96,
+	0x92, 0x1b, 0xf3, 0x80, 0xd8, 0x73, 0x80, 0xc2, 0x34, 0x66, 0xea, 0xe8, 0xe5, 0x5f, 0x25, 0xdd,
+	0x12, 0x83, 0x0f, 0x74, 0x29, 0x8a, 0x91, 0x16, 0xda, 0x58, 0x6a, 0xe9, 0x98, 0xf7, 0x4a, 0x51,
+	0x9c, 0x23, 0xc6, 0xe3, 0xac, 0x6b, 0x94, 0x2e, 0x2c, 0x25, 0x3a, 0xe6, 0x73, 0x98, 0xfe, 0x0b,
+	0x3a, 0xc7, 0xb8, 0xb0, 0xd0, 0xa4, 0x92, 0xd6, 0xe2, 0x60, 0x86, 0x75, 0x11, 0x20, 0x6a, 0xbe,
+	0x15, 0x8d, 0x56, 0xda, 0x37, 0x61, 0x8f, 0xcf, 0x61, 0xfa, 0x63, 0x0b, 0x3a, 0xc7, 0xd8, 0xbf,
+	0xb8, 0xa0, 0x45, 0x76, 0x9b, 0xb4, 0x96, 0xc6, 0xfd, 0x30, 0xbb, 0xc5, 0x05, 0x2d, 0xb2, 0x5b,
+	0xf6, 0x1a, 0xba, 0x0d, 0x35, 0x77, 0xc8, 0xa0, 0xdf, 0xbd, 0xbe, 0xdf, 0x4f, 0x56, 0x78, 0x50,
+	0xb2, 0x5d, 0x0c, 0x81, 0x2a, 0x13, 0xba, 0x7a, 0x8d, 0xec, 0x42, 0xb5, 0x4e, 0x56, 0xf8, 0x5c,
+	0xcd, 0x52, 0xe8, 0xd0, 0x9a, 0x0d, 0xad, 0xec, 0xdb, 0x8a, 0xee, 0x71, 0xb2, 0xc2, 0xbd, 0xea,
+	0x68, 0x35, 0x0c, 0x5a, 0xfa, 0x0d, 0x6c, 0x5d, 0x4e, 0xc7, 0x36, 0x6b, 0xd4, 0x58, 0x72, 0xf9,
+	0x71, 0x8a, 0xa3, 0x9c, 0xc0, 0xaa, 0x3f, 0xd4, 0x52, 0xcc, 0x3d, 0x3e, 0x87, 0x3e, 0x0f, 0x3e,
+	0x88, 0x70, 0xdb, 0xf9, 0xa1, 0xcb, 0x9b, 0x1e, 0x15, 0x01, 0x1d, 0x7c, 0x07, 0xf1, 0xe5, 0x87,
+	0xf7, 0xec, 0x0d, 0xf4, 0x8e, 0xef, 0xb2, 0x89, 0xd0, 0x85, 0x64, 0x3e, 0x20, 0x7a, 0x88, 0xb6,
+	0x43, 0x70, 0x18, 0xca, 0x6e, 0x6b, 0xbf, 0xc5, 0x9e, 0x43, 0xf7, 0x72, 0x3a, 0xae, 0x94, 0x7b,
+	0x60, 0xb5, 0xc8, 0x19, 0xdb, 0x87, 0xfe, 0x22, 0x5c, 0xf6, 0x47, 0x3f, 0x30, 0x5f, 0x84, 0xbf,
+	0xec, 0x73, 0xbf, 0x75, 0xf4, 0xe6, 0xff, 0xaf, 0x0a, 0xe5, 0x26, 0xd3, 0xf1, 0x5e, 0x66, 0xaa,
+	0xe1, 0xd4, 0xfd, 0x55, 0x60, 0x75, 0xc5, 0xd0, 0xd6, 0x6a, 0x88, 0x3f, 0x44, 0xc3, 0xa6, 0xce,
+	0x86, 0xf5, 0x78, 0xdc, 0xa5, 0x86, 0xfd, 0xdb, 0x2f, 0x03, 0x00, 0xe9, 0xcd, 0xff, 0x25, 0x91,
+	0x0a, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// SPIClient is the client API for SPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SPIClient interface {
+	// Exchange accepts Batches and emits an Ack for each Batch along
+	// with all reports, metrics, and errors.  A Batch is not read until
+	// the node has processed the previous one, so gRPC flow control
+	// gives clients backpressure.
+	Exchange(ctx context.Context, opts ...grpc.CallOption) (SPI_ExchangeClient, error)
+	// Submit gives one Batch to the node.
+	Submit(ctx context.Context, in *Batch, opts ...grpc.CallOption) (*Ack, error)
+	// Subscribe streams the requested events.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (SPI_SubscribeClient, error)
+}
+
+type sPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSPIClient(cc grpc.ClientConnInterface) SPIClient {
+	return &sPIClient{cc}
+}
+
+func (c *sPIClient) Exchange(ctx context.Context, opts ...grpc.CallOption) (SPI_ExchangeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SPI_serviceDesc.Streams[0], "/spi.SPI/Exchange", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sPIExchangeClient{stream}
+	return x, nil
+}
+
+type SPI_ExchangeClient interface {
+	Send(*Batch) error
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type sPIExchangeClient struct {
+	grpc.ClientStream
+}
+
+func (x *sPIExchangeClient) Send(m *Batch) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *sPIExchangeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sPIClient) Submit(ctx context.Context, in *Batch, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, "/spi.SPI/Submit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sPIClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (SPI_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SPI_serviceDesc.Streams[1], "/spi.SPI/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sPISubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SPI_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type sPISubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *sPISubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SPIServer is the server API for SPI service.
+type SPIServer interface {
+	// Exchange accepts Batches and emits an Ack for each Batch along
+	// with all reports, metrics, and errors.  A Batch is not read until
+	// the node has processed the previous one, so gRPC flow control
+	// gives clients backpressure.
+	Exchange(SPI_ExchangeServer) error
+	// Submit gives one Batch to the node.
+	Submit(context.Context, *Batch) (*Ack, error)
+	// Subscribe streams the requested events.
+	Subscribe(*SubscribeRequest, SPI_SubscribeServer) error
+}
+
+// UnimplementedSPIServer can be embedded to have forward compatible implementations.
+type UnimplementedSPIServer struct {
+}
+
+func (*UnimplementedSPIServer) Exchange(srv SPI_ExchangeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Exchange not implemented")
+}
+func (*UnimplementedSPIServer) Submit(ctx context.Context, req *Batch) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Submit not implemented")
+}
+func (*UnimplementedSPIServer) Subscribe(req *SubscribeRequest, srv SPI_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+
+func RegisterSPIServer(s *grpc.Server, srv SPIServer) {
+	s.RegisterService(&_SPI_serviceDesc, srv)
+}
+
+func _SPI_Exchange_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SPIServer).Exchange(&sPIExchangeServer{stream})
+}
+
+type SPI_ExchangeServer interface {
+	Send(*Event) error
+	Recv() (*Batch, error)
+	grpc.ServerStream
+}
+
+type sPIExchangeServer struct {
+	grpc.ServerStream
+}
+
+func (x *sPIExchangeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *sPIExchangeServer) Recv() (*Batch, error) {
+	m := new(Batch)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _SPI_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Batch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPIServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spi.SPI/Submit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPIServer).Submit(ctx, req.(*Batch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SPI_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SPIServer).Subscribe(m, &sPISubscribeServer{stream})
+}
+
+type SPI_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type sPISubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *sPISubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _SPI_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "spi.SPI",
+	HandlerType: (*SPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Submit",
+			Handler:    _SPI_Submit_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Exchange",
+			Handler:       _SPI_Exchange_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _SPI_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "spi.proto",
+}
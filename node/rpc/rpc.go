@@ -0,0 +1,278 @@
+// Package rpc provides a gRPC service (see pb/spi.proto) for a
+// running node.Node.
+//
+// A Server takes over the Node's Out, Metrics, and Errs channels.
+// Events are delivered to every subscribed stream without dropping
+// any, so a slow subscriber slows consumption of Node.Out, which
+// eventually blocks the Node.  Ingest (Exchange and Submit) blocks
+// until the Node has processed each Batch (see node.Node.Submit).
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/node/rpc/pb"
+)
+
+// Server implements pb.SPIServer.
+type Server struct {
+	Node *node.Node
+
+	// Publisher is the default publisher for in-coming elements
+	// that don't specify one.
+	Publisher string
+
+	// StreamBuffer is the size of each subscriber's buffer.
+	StreamBuffer int
+
+	sync.Mutex
+
+	subs map[*subscriber]bool
+}
+
+// subscriber is a stream's subscription to events.
+type subscriber struct {
+	c    chan *pb.Event
+	done chan bool
+
+	reports, metrics, errors bool
+}
+
+// NewServer makes a Server for the given Node, and it sets the Node's
+// Out, Metrics, and Errs channels.
+//
+// Call Run to start processing the Node's output.
+func NewServer(n *node.Node) *Server {
+	n.Out = make(chan []*node.Report, 32)
+	n.Metrics = make(chan node.Metrics)
+	n.Errs = make(chan error)
+
+	return &Server{
+		Node:         n,
+		Publisher:    "grpc",
+		StreamBuffer: 128,
+		subs:         make(map[*subscriber]bool),
+	}
+}
+
+// Run consumes the Node's output in the current goroutine until the
+// context is done.
+//
+// This method does not run the Node itself.
+func (s *Server) Run(ctx context.Context) {
+	n := s.Node
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rs := <-n.Out:
+			for _, r := range rs {
+				e, err := ReportToProto(r)
+				if err != nil {
+					s.publish(ctx, errorEvent(err))
+					continue
+				}
+				s.publish(ctx, &pb.Event{
+					Event: &pb.Event_Report{
+						Report: e,
+					},
+				})
+			}
+		case m := <-n.Metrics:
+			e, err := MetricsToProto(&m)
+			if err != nil {
+				s.publish(ctx, errorEvent(err))
+				continue
+			}
+			s.publish(ctx, &pb.Event{
+				Event: &pb.Event_Metrics{
+					Metrics: e,
+				},
+			})
+		case err := <-n.Errs:
+			s.publish(ctx, errorEvent(err))
+		}
+	}
+}
+
+func errorEvent(err error) *pb.Event {
+	_, warning := err.(*node.Warning)
+	return &pb.Event{
+		Event: &pb.Event_Error{
+			Error: &pb.Error{
+				Message: err.Error(),
+				Warning: warning,
+			},
+		},
+	}
+}
+
+// wants reports whether the subscriber wants the Event.
+func (sub *subscriber) wants(e *pb.Event) bool {
+	switch e.Event.(type) {
+	case *pb.Event_Report:
+		return sub.reports
+	case *pb.Event_Metrics:
+		return sub.metrics
+	case *pb.Event_Error:
+		return sub.errors
+	}
+	return false
+}
+
+// publish gives the Event to every interested subscriber, and it
+// blocks until each has accepted it (or has gone away).
+func (s *Server) publish(ctx context.Context, e *pb.Event) {
+	s.Lock()
+	subs := make([]*subscriber, 0, len(s.subs))
+	for sub := range s.subs {
+		if sub.wants(e) {
+			subs = append(subs, sub)
+		}
+	}
+	s.Unlock()
+
+	for _, sub := range subs {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.done:
+		case sub.c <- e:
+		}
+	}
+}
+
+func (s *Server) subscribe(req *pb.SubscribeRequest) *subscriber {
+	sub := &subscriber{
+		c:       make(chan *pb.Event, s.StreamBuffer),
+		done:    make(chan bool),
+		reports: req.Reports,
+		metrics: req.Metrics,
+		errors:  req.Errors,
+	}
+	s.Lock()
+	s.subs[sub] = true
+	s.Unlock()
+	return sub
+}
+
+func (s *Server) unsubscribe(sub *subscriber) {
+	s.Lock()
+	delete(s.subs, sub)
+	s.Unlock()
+	close(sub.done)
+}
+
+// submit converts the Batch and gives it to the Node, and it waits
+// until the Node has processed it.
+func (s *Server) submit(ctx context.Context, b *pb.Batch) (*pb.Ack, error) {
+	sats, errs := PubTLEsFromProto(b.Tles, s.Publisher)
+
+	if 0 < len(sats) {
+		if err := s.Node.Submit(ctx, sats); err != nil {
+			return nil, err
+		}
+	}
+
+	return &pb.Ack{
+		Seq:      b.Seq,
+		Accepted: int32(len(sats)),
+		Errors:   errs,
+	}, nil
+}
+
+// Submit gives one Batch to the Node.
+func (s *Server) Submit(ctx context.Context, b *pb.Batch) (*pb.Ack, error) {
+	return s.submit(ctx, b)
+}
+
+// Subscribe streams the requested events until the client goes away.
+func (s *Server) Subscribe(req *pb.SubscribeRequest, stream pb.SPI_SubscribeServer) error {
+	var (
+		ctx = stream.Context()
+		sub = s.subscribe(req)
+	)
+	defer s.unsubscribe(sub)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-sub.c:
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Exchange accepts Batches and emits an Ack for each one along with
+// all reports, metrics, and errors.
+//
+// The exchange ends when the client closes its side of the stream.
+func (s *Server) Exchange(stream pb.SPI_ExchangeServer) error {
+	var (
+		ctx, cancel = context.WithCancel(stream.Context())
+		acks        = make(chan *pb.Ack)
+		recvErr     = make(chan error, 1)
+		sub         = s.subscribe(&pb.SubscribeRequest{
+			Reports: true,
+			Metrics: true,
+			Errors:  true,
+		})
+	)
+	defer cancel()
+	defer s.unsubscribe(sub)
+
+	go func() {
+		defer close(recvErr)
+		for {
+			b, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			ack, err := s.submit(ctx, b)
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case acks <- ack:
+			}
+		}
+	}()
+
+	// Only this goroutine calls stream.Send.
+	for {
+		var e *pb.Event
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, more := <-recvErr:
+			if more {
+				return err
+			}
+			return nil
+		case ack := <-acks:
+			e = &pb.Event{
+				Event: &pb.Event_Ack{
+					Ack: ack,
+				},
+			}
+		case e = <-sub.c:
+		}
+		if err := stream.Send(e); err != nil {
+			return fmt.Errorf("exchange send: %w", err)
+		}
+	}
+}
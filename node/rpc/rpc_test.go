@@ -0,0 +1,175 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/node/rpc/pb"
+)
+
+// testTLEs makes count TLEs for objects that share an orbit but are
+// separated by small differences in mean anomaly.
+func testTLEs(count int) []*pb.PubTLE {
+	acc := make([]*pb.PubTLE, 0, count)
+	for i := 0; i < count; i++ {
+		cat := 50000 + i
+		acc = append(acc, &pb.PubTLE{
+			Publisher: "test",
+			Elements: &pb.PubTLE_Tle{
+				Tle: &pb.TLE{
+					Lines: []string{
+						fmt.Sprintf("TEST %d", i),
+						fmt.Sprintf("1 %05dU 20001A   20016.08333333  .00000000  00000+0  00000+0 0    07", cat),
+						fmt.Sprintf("2 %05d 064.8760 163.6520 0036285 284.0373 %8.4f 15.07452065    00", cat, 175.5769+0.05*float64(i)),
+					},
+				},
+			},
+		})
+	}
+	return acc
+}
+
+// testClient starts a Node and a Server, and it returns a client
+// connected via bufconn.
+func testClient(ctx context.Context, t *testing.T) pb.SPIClient {
+	n := node.NewNode(nil)
+	n.Horizon = 5
+	n.SlowSample = 0
+
+	s := NewServer(n)
+	go s.Run(ctx)
+	go n.Run(ctx)
+
+	var (
+		l   = bufconn.Listen(1 << 20)
+		srv = grpc.NewServer()
+	)
+	pb.RegisterSPIServer(srv, s)
+	go srv.Serve(l)
+	go func() {
+		<-ctx.Done()
+		srv.Stop()
+	}()
+
+	dial := func(context.Context, string) (net.Conn, error) {
+		return l.Dial()
+	}
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(dial), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	return pb.NewSPIClient(conn)
+}
+
+func TestExchange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	c := testClient(ctx, t)
+
+	stream, err := c.Exchange(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bad := &pb.PubTLE{
+		Elements: &pb.PubTLE_Tle{
+			Tle: &pb.TLE{
+				Lines: []string{"BAD"},
+			},
+		},
+	}
+
+	if err := stream.Send(&pb.Batch{
+		Seq:  42,
+		Tles: append(testTLEs(3), bad),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		acked    = false
+		reported = false
+	)
+
+	for !acked || !reported {
+		e, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch x := e.Event.(type) {
+		case *pb.Event_Ack:
+			if x.Ack.Seq != 42 {
+				t.Fatalf("ack seq %d", x.Ack.Seq)
+			}
+			if x.Ack.Accepted != 3 {
+				t.Fatalf("accepted %d", x.Ack.Accepted)
+			}
+			if len(x.Ack.Errors) != 1 {
+				t.Fatalf("errors %v", x.Ack.Errors)
+			}
+			acked = true
+		case *pb.Event_Report:
+			r := x.Report
+			if len(r.Objs) != 2 || r.Sig == "" || r.At == nil {
+				t.Fatalf("bad report %v", r)
+			}
+			reported = true
+		}
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitOMM(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	c := testClient(ctx, t)
+
+	ack, err := c.Submit(ctx, &pb.Batch{
+		Tles: []*pb.PubTLE{
+			{
+				Elements: &pb.PubTLE_Omm{
+					Omm: &pb.OMM{
+						ObjectName:      "ISS (ZARYA)",
+						ObjectId:        "1998-067A",
+						Epoch:           "2020-09-20T12:25:40.104192",
+						MeanMotion:      15.72125391,
+						Eccentricity:    0.0006703,
+						Inclination:     51.6416,
+						RaOfAscNode:     247.4627,
+						ArgOfPericenter: 130.5360,
+						MeanAnomaly:     325.0288,
+						NoradCatId:      25544,
+						ElementSetNo:    292,
+						RevAtEpoch:      56353,
+						Bstar:           -0.11606e-4,
+						MeanMotionDot:   -0.00002182,
+					},
+				},
+			},
+			{},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.Accepted != 1 || len(ack.Errors) != 1 {
+		t.Fatalf("ack %v", ack)
+	}
+}
@@ -0,0 +1,610 @@
+// Package server provides an HTTP/JSON API for a running node.Node.
+//
+// Endpoints:
+//
+//	POST /tles       Submit a batch of TLEs (text or JSON)
+//	GET  /reports    Active (uncanceled, future) reports
+//	GET  /objects    Objects received via POST /tles
+//	GET  /config     The Node's configuration
+//	GET  /metrics    The most recent Metrics
+//	GET  /watch      The current watch list (null watches everything)
+//	PUT  /watch      Replace the watch list
+//	POST /screen     Screen a hypothetical TLE (what-if) against the live set
+//	GET  /stream     Server-Sent Events: reports, metrics, and errors
+//
+// A Server takes over the Node's Out, Metrics, Errs, and Watches
+// channels.
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/tle"
+)
+
+// Event is a message for a stream subscriber.
+type Event struct {
+	// Type is "report", "metrics", or "error".
+	Type string
+
+	// Data is the Event's payload.
+	Data interface{}
+}
+
+// Server wraps a node.Node with an HTTP API.
+type Server struct {
+	Node *node.Node
+
+	// Publisher is the default publisher for submitted TLEs.
+	Publisher string
+
+	// StreamBuffer is the size of each stream subscriber's
+	// buffer.  When a buffer is full, events for that subscriber
+	// are dropped.
+	StreamBuffer int
+
+	// ShutdownTimeout limits how long ListenAndServe waits for
+	// requests to finish after its context is done.
+	ShutdownTimeout time.Duration
+
+	sync.RWMutex
+
+	// reports are the active reports by Sig.
+	reports map[string]*node.Report
+
+	// objects are the objects received via POST by Name.
+	objects map[string]*node.PubTLE
+
+	metrics *node.Metrics
+
+	// watch is the Node's current watch list.
+	watch *node.WatchList
+
+	subs map[chan *Event]bool
+
+	// done is closed when the HTTP server is shutting down so that
+	// streams terminate.
+	done chan bool
+}
+
+// NewServer makes a Server for the given Node, and it sets the Node's
+// Out, Metrics, Errs, and Watches channels.
+//
+// Call Run to start processing the Node's output.
+func NewServer(n *node.Node) *Server {
+	n.Out = make(chan []*node.Report, 32)
+	n.Metrics = make(chan node.Metrics)
+	n.Errs = make(chan error)
+	n.Watches = make(chan *node.WatchList)
+
+	return &Server{
+		Node:            n,
+		Publisher:       "http",
+		StreamBuffer:    1024,
+		ShutdownTimeout: 10 * time.Second,
+		reports:         make(map[string]*node.Report),
+		objects:         make(map[string]*node.PubTLE),
+		watch:           n.WatchList,
+		subs:            make(map[chan *Event]bool),
+		done:            make(chan bool),
+	}
+}
+
+// Run consumes the Node's output in the current goroutine until the
+// context is done.
+//
+// This method does not run the Node itself.
+func (s *Server) Run(ctx context.Context) {
+	n := s.Node
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rs := <-n.Out:
+			s.Lock()
+			for _, r := range rs {
+				if r.Canceled {
+					delete(s.reports, r.Sig)
+				} else {
+					s.reports[r.Sig] = r
+				}
+			}
+			s.Unlock()
+			for _, r := range rs {
+				s.publish(&Event{
+					Type: "report",
+					Data: r,
+				})
+			}
+		case m := <-n.Metrics:
+			s.Lock()
+			s.metrics = &m
+			s.Unlock()
+			s.prune()
+			s.publish(&Event{
+				Type: "metrics",
+				Data: m,
+			})
+		case err := <-n.Errs:
+			s.publish(&Event{
+				Type: "error",
+				Data: map[string]interface{}{
+					"error": err.Error(),
+				},
+			})
+		}
+	}
+}
+
+// prune removes reports for times that have passed.
+func (s *Server) prune() {
+	now := time.Now().Add(s.Node.TimeOffset)
+	s.Lock()
+	for sig, r := range s.reports {
+		if r.At.Before(now) {
+			delete(s.reports, sig)
+		}
+	}
+	s.Unlock()
+}
+
+// publish gives the Event to all subscribers without blocking.
+func (s *Server) publish(e *Event) {
+	s.RLock()
+	for c := range s.subs {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+	s.RUnlock()
+}
+
+func (s *Server) subscribe() chan *Event {
+	c := make(chan *Event, s.StreamBuffer)
+	s.Lock()
+	s.subs[c] = true
+	s.Unlock()
+	return c
+}
+
+func (s *Server) unsubscribe(c chan *Event) {
+	s.Lock()
+	delete(s.subs, c)
+	s.Unlock()
+}
+
+// Handler returns the Server's http.Handler.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tles", s.handleTLEs)
+	mux.HandleFunc("/reports", s.handleReports)
+	mux.HandleFunc("/objects", s.handleObjects)
+	mux.HandleFunc("/config", s.handleConfig)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/watch", s.handleWatch)
+	mux.HandleFunc("/screen", s.handleScreen)
+	mux.HandleFunc("/stream", s.handleStream)
+	return mux
+}
+
+// ListenAndServe serves the Handler at the given address until the
+// context is done, and then it shuts down gracefully.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: s.Handler(),
+	}
+	srv.RegisterOnShutdown(func() {
+		close(s.done)
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Server shutting down")
+
+	sctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(sctx); err != nil {
+		return err
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// SubmitResult is the response to POST /tles.
+type SubmitResult struct {
+	// Accepted is the number of TLEs given to the Node.
+	Accepted int
+
+	// Errors are problems with specific TLEs (which were not
+	// accepted).
+	Errors []string `json:",omitempty"`
+}
+
+// handleTLEs accepts TLEs for the Node.
+//
+// The request body is either TLE text (three lines per TLE) or, when
+// the Content-Type is application/json, an array of PubTLEs.  The
+// query parameter "publisher" overrides the Server's default
+// Publisher for TLE text.
+//
+// The response is delayed until the Node has processed the batch (see
+// node.Node.Submit).
+func (s *Server) handleTLEs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var (
+		sats []*node.PubTLE
+		res  = &SubmitResult{}
+		err  error
+	)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		sats, res.Errors, err = s.readJSON(r)
+	} else {
+		sats, res.Errors, err = s.readText(r)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if 0 < len(sats) {
+		if err := s.Node.Submit(r.Context(), sats); err != nil {
+			http.Error(w, "canceled", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	s.Lock()
+	for _, sat := range sats {
+		s.objects[sat.Name()] = sat
+	}
+	s.Unlock()
+
+	res.Accepted = len(sats)
+	s.writeJSON(w, res)
+}
+
+func (s *Server) readText(r *http.Request) ([]*node.PubTLE, []string, error) {
+	var (
+		acc       = make([]*node.PubTLE, 0, 128)
+		errs      []string
+		publisher = r.URL.Query().Get("publisher")
+		parser    = tle.NewSGP4TLE
+	)
+
+	if publisher == "" {
+		publisher = s.Publisher
+	}
+
+	if s.Node.SGP4 != nil {
+		parser = tle.NewSGP4TLEWith(*s.Node.SGP4)
+	}
+
+	f := func(i int, line0 string, p prop.Propagator) error {
+		if err := tle.Check(p); err != nil {
+			errs = append(errs, fmt.Sprintf("%d %s: %s", i, strings.TrimSpace(line0), err))
+			return nil
+		}
+		acc = append(acc, &node.PubTLE{
+			Publisher: publisher,
+			TLE:       p.(*tle.SGP4TLE),
+		})
+		return nil
+	}
+
+	if err := tle.DoTLEs(bufio.NewReader(r.Body), parser, f); err != nil {
+		return nil, nil, err
+	}
+
+	return acc, errs, nil
+}
+
+func (s *Server) readJSON(r *http.Request) ([]*node.PubTLE, []string, error) {
+	bs, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var in []struct {
+		Publisher string
+		TLE       json.RawMessage
+	}
+	if err := json.Unmarshal(bs, &in); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		acc  = make([]*node.PubTLE, 0, len(in))
+		errs []string
+	)
+
+	for i, x := range in {
+		o, err := tle.ParseSGP4TLE(string(x.TLE))
+		if err == nil {
+			err = tle.Check(o)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%d: %s", i, err))
+			continue
+		}
+		publisher := x.Publisher
+		if publisher == "" {
+			publisher = s.Publisher
+		}
+		acc = append(acc, &node.PubTLE{
+			Publisher: publisher,
+			TLE:       o,
+		})
+	}
+
+	return acc, errs, nil
+}
+
+// Reports returns the active reports ordered by At.
+func (s *Server) Reports() []*node.Report {
+	s.prune()
+
+	s.RLock()
+	acc := make([]*node.Report, 0, len(s.reports))
+	for _, r := range s.reports {
+		acc = append(acc, r)
+	}
+	s.RUnlock()
+
+	sort.Slice(acc, func(i, j int) bool {
+		if acc[i].At.Equal(acc[j].At) {
+			return acc[i].Sig < acc[j].Sig
+		}
+		return acc[i].At.Before(acc[j].At)
+	})
+
+	return acc
+}
+
+// Objects returns the objects received via POST ordered by name.
+func (s *Server) Objects() []*node.PubTLE {
+	s.RLock()
+	acc := make([]*node.PubTLE, 0, len(s.objects))
+	for _, o := range s.objects {
+		acc = append(acc, o)
+	}
+	s.RUnlock()
+
+	sort.Slice(acc, func(i, j int) bool {
+		return acc[i].Name() < acc[j].Name()
+	})
+
+	return acc
+}
+
+func (s *Server) handleReports(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeJSON(w, s.Reports())
+}
+
+func (s *Server) handleObjects(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeJSON(w, s.Objects())
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cfg, err := s.Node.Config(r.Context())
+	if err != nil {
+		http.Error(w, "canceled", http.StatusServiceUnavailable)
+		return
+	}
+	s.writeJSON(w, cfg)
+}
+
+// handleWatch reports or replaces the watch list.
+//
+// A PUT request body is a JSON node.WatchList or null.  The response
+// is delayed until the Node has received the new list.
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.RLock()
+		wl := s.watch
+		s.RUnlock()
+		s.writeJSON(w, wl)
+	case http.MethodPut:
+		var wl *node.WatchList
+		if err := json.NewDecoder(r.Body).Decode(&wl); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			http.Error(w, "canceled", http.StatusServiceUnavailable)
+			return
+		case s.Node.Watches <- wl:
+		}
+		s.Lock()
+		s.watch = wl
+		s.Unlock()
+		s.writeJSON(w, wl)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ScreenRequest is the body of POST /screen.
+type ScreenRequest struct {
+	// Name names the hypothetical object in reports.
+	Name string
+
+	// TLE is the hypothetical object as a JSON tle.SGP4TLE.
+	TLE json.RawMessage
+
+	// From and To bound the window (see node.ScreenRequest).
+	From, To time.Time
+
+	// Dist, if positive, is the maximum distance (km).
+	Dist float32
+}
+
+// handleScreen finds close approaches between a hypothetical object
+// and the Node's live set (see node.Node.Screen).
+//
+// The request body is a JSON ScreenRequest, and the response is a
+// JSON array of what-if reports.  The Node's state doesn't change.
+func (s *Server) handleScreen(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ScreenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	o, err := tle.ParseSGP4TLE(string(req.TLE))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rs, err := s.Node.Screen(r.Context(), &node.ScreenRequest{
+		Name: req.Name,
+		Obj:  o,
+		From: req.From,
+		To:   req.To,
+		Dist: req.Dist,
+	})
+	if err != nil {
+		if r.Context().Err() != nil {
+			http.Error(w, "canceled", http.StatusServiceUnavailable)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	if rs == nil {
+		rs = []*node.Report{}
+	}
+	s.writeJSON(w, rs)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.RLock()
+	m := s.metrics
+	s.RUnlock()
+	if m == nil {
+		http.Error(w, "no metrics yet", http.StatusNotFound)
+		return
+	}
+	s.writeJSON(w, m)
+}
+
+// handleStream emits Server-Sent Events.
+//
+// The optional query parameter "types" is a comma-separated list of
+// Event types to receive.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var types map[string]bool
+	if ts := r.URL.Query().Get("types"); ts != "" {
+		types = make(map[string]bool)
+		for _, t := range strings.Split(ts, ",") {
+			types[strings.TrimSpace(t)] = true
+		}
+	}
+
+	c := s.subscribe()
+	defer s.unsubscribe(c)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case e := <-c:
+			if types != nil && !types[e.Type] {
+				continue
+			}
+			js, err := json.Marshal(e.Data)
+			if err != nil {
+				log.Printf("stream marshal: %s", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, js); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, x interface{}) {
+	js, err := json.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+	w.Write([]byte("\n"))
+}
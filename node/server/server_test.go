@@ -0,0 +1,307 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+)
+
+// testTLEs returns TLE text for count objects that share an orbit but
+// are separated by small differences in mean anomaly.
+func testTLEs(count int) string {
+	var b strings.Builder
+	for i := 0; i < count; i++ {
+		cat := 50000 + i
+		fmt.Fprintf(&b, "TEST %d\n", i)
+		fmt.Fprintf(&b, "1 %05dU 20001A   20016.08333333  .00000000  00000+0  00000+0 0    07\n", cat)
+		fmt.Fprintf(&b, "2 %05d 064.8760 163.6520 0036285 284.0373 %8.4f 15.07452065    00\n", cat, 175.5769+0.05*float64(i))
+	}
+	return b.String()
+}
+
+func getJSON(t *testing.T, url string, x interface{}) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(x); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func post(t *testing.T, url, contentType, body string) *SubmitResult {
+	resp, err := http.Post(url, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST %s: %s", url, resp.Status)
+	}
+	var res SubmitResult
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	return &res
+}
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := node.NewNode(nil)
+	n.Horizon = 5
+	n.SlowSample = 0
+
+	s := NewServer(n)
+	go s.Run(ctx)
+	go n.Run(ctx)
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	// Subscribe before submitting anything.
+	req, err := http.NewRequest("GET", ts.URL+"/stream?types=report", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(ctx)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+
+	if ct := stream.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("stream Content-Type %s", ct)
+	}
+
+	if res := post(t, ts.URL+"/tles?publisher=test", "text/plain", testTLEs(3)); res.Accepted != 3 {
+		t.Fatalf("accepted %d", res.Accepted)
+	}
+
+	events := make(chan string)
+	go func() {
+		s := bufio.NewScanner(stream.Body)
+		for s.Scan() {
+			if line := s.Text(); strings.HasPrefix(line, "event: ") {
+				events <- strings.TrimPrefix(line, "event: ")
+			}
+		}
+	}()
+
+	select {
+	case e := <-events:
+		if e != "report" {
+			t.Fatalf("event %s", e)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("no report")
+	}
+
+	var rs []*node.Report
+	getJSON(t, ts.URL+"/reports", &rs)
+	if len(rs) == 0 {
+		t.Fatal("no active reports")
+	}
+	for _, r := range rs {
+		if r.Canceled {
+			t.Fatalf("canceled report %s is active", r.Id)
+		}
+	}
+
+	var objs []*node.PubTLE
+	getJSON(t, ts.URL+"/objects", &objs)
+	if len(objs) != 3 {
+		t.Fatalf("%d objects", len(objs))
+	}
+	if objs[0].Name() != "50000/test" {
+		t.Fatalf("object name %s", objs[0].Name())
+	}
+
+	var cfg node.Cfg
+	getJSON(t, ts.URL+"/config", &cfg)
+	if cfg.Horizon != 5 {
+		t.Fatalf("config horizon %d", cfg.Horizon)
+	}
+}
+
+func TestSubmitJSON(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := node.NewNode(nil)
+	n.Horizon = 2
+
+	s := NewServer(n)
+	go s.Run(ctx)
+	go n.Run(ctx)
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	lines := strings.Split(strings.TrimSpace(testTLEs(1)), "\n")
+	in := []map[string]interface{}{
+		{
+			"Publisher": "a",
+			"TLE": map[string]interface{}{
+				"CatNum": "50000",
+				"TLE":    lines,
+			},
+		},
+		{
+			"TLE": map[string]interface{}{
+				"TLE": lines[0:2],
+			},
+		},
+	}
+	js, err := json.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := post(t, ts.URL+"/tles", "application/json", string(js))
+	if res.Accepted != 1 {
+		t.Fatalf("accepted %d", res.Accepted)
+	}
+	if len(res.Errors) != 1 {
+		t.Fatalf("errors: %v", res.Errors)
+	}
+
+	if got := s.Objects(); len(got) != 1 || got[0].Name() != "50000/a" {
+		t.Fatalf("objects: %v", got)
+	}
+
+	resp, err := http.Get(ts.URL + "/tles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /tles: %s", resp.Status)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := node.NewNode(nil)
+	n.Horizon = 2
+
+	s := NewServer(n)
+	go s.Run(ctx)
+	go n.Run(ctx)
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	var wl *node.WatchList
+	getJSON(t, ts.URL+"/watch", &wl)
+	if wl != nil {
+		t.Fatalf("watch list %v", wl)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+"/watch", strings.NewReader(`{"CatNums":["50001"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT /watch: %s", resp.Status)
+	}
+
+	getJSON(t, ts.URL+"/watch", &wl)
+	if wl == nil || len(wl.CatNums) != 1 || wl.CatNums[0] != "50001" {
+		t.Fatalf("watch list %v", wl)
+	}
+}
+
+func TestScreen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := node.NewNode(nil)
+	n.Horizon = 2
+
+	s := NewServer(n)
+	go s.Run(ctx)
+	go n.Run(ctx)
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	if res := post(t, ts.URL+"/tles?publisher=test", "text/plain", testTLEs(3)); res.Accepted != 3 {
+		t.Fatalf("accepted %d", res.Accepted)
+	}
+
+	// The hypothetical object replaces the first object, so it's
+	// screened against the other two.
+	var (
+		lines = strings.Split(strings.TrimSpace(testTLEs(1)), "\n")
+		from  = time.Date(2020, 1, 16, 2, 0, 0, 0, time.UTC)
+		in    = map[string]interface{}{
+			"Name": "maneuver",
+			"TLE": map[string]interface{}{
+				"CatNum": "50000",
+				"TLE":    lines,
+			},
+			"From": from,
+			"To":   from.Add(5 * time.Second),
+		}
+	)
+	js, err := json.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(ts.URL+"/screen", "application/json", strings.NewReader(string(js)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /screen: %s", resp.Status)
+	}
+	var rs []*node.Report
+	if err := json.NewDecoder(resp.Body).Decode(&rs); err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 2 {
+		t.Fatalf("%d reports", len(rs))
+	}
+	for _, r := range rs {
+		if r.Kind != node.WhatIfReport || r.Objs[0].Name != "maneuver" || r.Objs[1].Name == "50000/test" {
+			t.Fatalf("report %#v", r)
+		}
+	}
+
+	// An empty window.
+	in["To"] = from.Add(-time.Minute)
+	if js, err = json.Marshal(&in); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.Post(ts.URL+"/screen", "application/json", strings.NewReader(string(js)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("POST /screen: %s", resp.Status)
+	}
+}
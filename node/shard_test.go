@@ -0,0 +1,208 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/tle"
+)
+
+// testEpoch is the epoch of the TLEs from testTLEs.
+var testEpoch = time.Date(2020, 1, 16, 2, 0, 0, 0, time.UTC)
+
+// testTLEs makes count TLEs for objects that share an orbit but are
+// separated by small differences in mean anomaly (about 6 km).
+func testTLEs(t *testing.T, publisher string, count int) []*PubTLE {
+	acc := make([]*PubTLE, 0, count)
+	for i := 0; i < count; i++ {
+		var (
+			cat   = 50000 + i
+			line0 = fmt.Sprintf("TEST %d", i)
+			line1 = fmt.Sprintf("1 %05dU 20001A   20016.08333333  .00000000  00000+0  00000+0 0    07", cat)
+			line2 = fmt.Sprintf("2 %05d 064.8760 163.6520 0036285 284.0373 %8.4f 15.07452065    00", cat, 175.5769+0.05*float64(i))
+		)
+		p, err := tle.NewSGP4TLE(line0, line1, line2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		acc = append(acc, &PubTLE{
+			Publisher: publisher,
+			TLE:       p.(*tle.SGP4TLE),
+		})
+	}
+	return acc
+}
+
+// testNode makes a Node suitable for deterministic tests.
+func testNode() *Node {
+	n := NewNode(nil)
+	n.Horizon = 20
+	n.SlowSample = 0
+	n.Out = make(chan []*Report, 1024)
+	return n
+}
+
+// testSlices submits the given batches of TLEs (via processNew) to
+// the time slices in [t0,t0+Horizon) owned by the Node, and it
+// returns the emitted reports.
+func testSlices(t *testing.T, n *Node, t0 time.Time, batches ...[]*PubTLE) []*Report {
+	steps := make([]testStep, 0, len(batches))
+	for _, sats := range batches {
+		sats := sats
+		steps = append(steps, func(ctx context.Context, indexes map[time.Time]*Index) error {
+			return n.processNew(ctx, sats, indexes)
+		})
+	}
+	return testSteps(t, n, t0, steps...)
+}
+
+// testStep is some work for the time slices in testSteps.
+type testStep func(ctx context.Context, indexes map[time.Time]*Index) error
+
+// testSteps performs the given steps on the time slices in
+// [t0,t0+Horizon) owned by the Node, and it returns the emitted
+// reports.
+func testSteps(t *testing.T, n *Node, t0 time.Time, steps ...testStep) []*Report {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := n.Prepare(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		t1      = t0.Add(time.Duration(n.Horizon) * n.Resolution)
+		indexes = n.NewIndexes(ctx, t0, t1)
+		acc     = make([]*Report, 0, 1024)
+		done    = make(chan bool)
+		stopped = make(chan bool)
+	)
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case rs := <-n.Out:
+				acc = append(acc, rs...)
+			case <-done:
+				for {
+					select {
+					case rs := <-n.Out:
+						acc = append(acc, rs...)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	for _, step := range steps {
+		if err := step(ctx, indexes); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(done)
+	<-stopped
+
+	return acc
+}
+
+// reportKeys returns sorted strings that identify reports without
+// regard to when they were generated or the order of their objects
+// (which depends on interned ids).
+func reportKeys(rs []*Report) []string {
+	acc := make([]string, 0, len(rs))
+	for _, r := range rs {
+		names := []string{r.Objs[0].Name, r.Objs[1].Name}
+		sort.Strings(names)
+		acc = append(acc, fmt.Sprintf("%s %v %f %v",
+			r.At.Format(time.RFC3339Nano), names, r.Dist, r.Canceled))
+	}
+	sort.Strings(acc)
+	return acc
+}
+
+func TestOwns(t *testing.T) {
+	var (
+		n  = testNode()
+		t0 = testEpoch
+	)
+	n.Shards = 3
+
+	for i := 0; i < 30; i++ {
+		at := t0.Add(time.Duration(i) * n.Resolution)
+		owners := 0
+		for shard := 0; shard < n.Shards; shard++ {
+			n.Shard = shard
+			if n.Owns(at) {
+				owners++
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("%v has %d owners", at, owners)
+		}
+	}
+}
+
+func TestShardCheck(t *testing.T) {
+	tests := []struct {
+		shards, shard int
+		ok            bool
+	}{
+		{0, 0, true},
+		{1, 0, true},
+		{3, 2, true},
+		{3, 3, false},
+		{3, -1, false},
+		{1, 1, false},
+		{0, 2, false},
+	}
+	for _, test := range tests {
+		n := testNode()
+		n.Shards, n.Shard = test.shards, test.shard
+		if err := n.Prepare(context.Background()); (err == nil) != test.ok {
+			t.Errorf("shard %d of %d: %v", test.shard, test.shards, err)
+		}
+	}
+}
+
+func TestShards(t *testing.T) {
+	var (
+		t0     = testEpoch
+		sats   = testTLEs(t, "test", 4)
+		shards = 3
+		whole  = reportKeys(testSlices(t, testNode(), t0, sats))
+		parts  = make([]*Report, 0, len(whole))
+	)
+
+	if len(whole) == 0 {
+		t.Fatal("no reports")
+	}
+
+	for shard := 0; shard < shards; shard++ {
+		n := testNode()
+		n.Shards = shards
+		n.Shard = shard
+		rs := testSlices(t, n, t0, sats)
+		if len(rs) == 0 || len(whole) <= len(rs) {
+			t.Fatalf("shard %d: %d reports (unsharded: %d)", shard, len(rs), len(whole))
+		}
+		parts = append(parts, rs...)
+	}
+
+	union := reportKeys(parts)
+
+	if len(union) != len(whole) {
+		t.Fatalf("union has %d reports; unsharded: %d", len(union), len(whole))
+	}
+	for i, k := range whole {
+		if union[i] != k {
+			t.Fatalf("%s != %s", union[i], k)
+		}
+	}
+}
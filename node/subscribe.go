@@ -0,0 +1,381 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// A Hub delivers each report to any number of Subscriptions.  Each
+// Subscription has its own Filter, buffer, and overflow policy, so a
+// slow consumer only stalls the Node if its policy is Block.
+
+// Overflow policies (see Subscription.Policy).
+const (
+	// Block waits for the subscriber, which provides backpressure
+	// to the Node.
+	Block = "block"
+
+	// DropNewest discards a report that doesn't fit in the buffer.
+	DropNewest = "drop-newest"
+
+	// DropOldest discards the oldest buffered report to make room.
+	DropOldest = "drop-oldest"
+
+	// Disconnect closes the Subscription when its buffer is full.
+	Disconnect = "disconnect"
+)
+
+// ErrOverflow is a Subscription's Err after a Disconnect.
+var ErrOverflow = errors.New("subscription buffer overflow")
+
+// Region is a latitude/longitude (degrees) and altitude (km) box.
+//
+// When MinLon is greater than MaxLon, the region crosses the
+// antimeridian.  Zero MinAlt and MaxAlt means any altitude.
+type Region struct {
+	MinLat, MaxLat float32
+	MinLon, MaxLon float32
+	MinAlt, MaxAlt float32 `json:",omitempty"`
+}
+
+// Contains reports whether the Region contains the position.
+func (r *Region) Contains(p LatLonAlt) bool {
+	if p.Lat < r.MinLat || r.MaxLat < p.Lat {
+		return false
+	}
+	lon := float32(math.Remainder(float64(p.Lon), 360))
+	if r.MinLon <= r.MaxLon {
+		if lon < r.MinLon || r.MaxLon < lon {
+			return false
+		}
+	} else if lon < r.MinLon && r.MaxLon < lon {
+		return false
+	}
+	if r.MinAlt != 0 || r.MaxAlt != 0 {
+		if p.Alt < r.MinAlt || r.MaxAlt < p.Alt {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter selects Reports.  Empty criteria match everything.
+//
+// The object criteria (CatNums, Publishers, and Types) match a Report
+// if either object matches any of them.  A Report matches the Filter
+// if it matches every non-empty criterion.
+type Filter struct {
+	// CatNums are catalog numbers.  Leading zeros are ignored.
+	CatNums []string `json:",omitempty"`
+
+	// Publishers are publisher names.
+	Publishers []string `json:",omitempty"`
+
+	// Types are object types (see tle.GetType).
+	Types []string `json:",omitempty"`
+
+	// Kinds are Report Kinds.  Use "" for conjunctions.
+	Kinds []string `json:",omitempty"`
+
+	// MaxDist, if positive, is the maximum Dist (km).
+	MaxDist float32 `json:",omitempty"`
+
+	// MinSpeed and MaxSpeed, if positive, limit Speed.
+	MinSpeed float32 `json:",omitempty"`
+	MaxSpeed float32 `json:",omitempty"`
+
+	// Regions, if not empty, requires either object's position
+	// to be in one of the regions.
+	Regions []Region `json:",omitempty"`
+}
+
+// objects reports whether the filter has object criteria.
+func (f *Filter) objects() bool {
+	return 0 < len(f.CatNums)+len(f.Publishers)+len(f.Types)
+}
+
+// matchesObject reports whether the state matches the object
+// criteria.
+func (f *Filter) matchesObject(s *State) bool {
+	for _, c := range f.CatNums {
+		if normCatNum(c) == normCatNum(s.CatNum()) {
+			return true
+		}
+	}
+	for _, p := range f.Publishers {
+		if p == s.Publisher {
+			return true
+		}
+	}
+	for _, t := range f.Types {
+		if t == s.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the Report passes the Filter.
+func (f *Filter) Matches(r *Report) bool {
+	if f == nil {
+		return true
+	}
+
+	if 0 < len(f.Kinds) {
+		have := false
+		for _, k := range f.Kinds {
+			if k == r.Kind {
+				have = true
+				break
+			}
+		}
+		if !have {
+			return false
+		}
+	}
+
+	if 0 < f.MaxDist && f.MaxDist < r.Dist {
+		return false
+	}
+	if 0 < f.MinSpeed && r.Speed < f.MinSpeed {
+		return false
+	}
+	if 0 < f.MaxSpeed && f.MaxSpeed < r.Speed {
+		return false
+	}
+
+	if f.objects() {
+		have := false
+		for i := range r.Objs {
+			if f.matchesObject(&r.Objs[i]) {
+				have = true
+				break
+			}
+		}
+		if !have {
+			return false
+		}
+	}
+
+	if 0 < len(f.Regions) {
+		have := false
+	REGIONS:
+		for i := range f.Regions {
+			for _, s := range r.Objs {
+				if f.Regions[i].Contains(s.LLA) {
+					have = true
+					break REGIONS
+				}
+			}
+		}
+		if !have {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Subscription is a consumer's filtered stream of Reports.
+type Subscription struct {
+	Filter *Filter
+
+	// Policy is the overflow policy.
+	Policy string
+
+	c       chan *Report
+	hub     *Hub
+	done    chan bool
+	once    sync.Once
+	dropped uint64
+	err     error
+
+	// mu serializes sending on c with closing it.
+	mu     sync.Mutex
+	closed bool
+}
+
+// C returns the channel of Reports, which is closed when the
+// Subscription ends.
+func (s *Subscription) C() <-chan *Report {
+	return s.c
+}
+
+// Dropped returns the number of reports discarded due to overflow.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Err returns ErrOverflow if the Subscription was disconnected.
+//
+// Only call Err after C is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Close ends the Subscription.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	s.mu.Lock()
+	s.hub.remove(s)
+	s.mu.Unlock()
+}
+
+// Hub fans out Reports to Subscriptions.
+//
+// A Node with a Hub publishes its reports to that Hub instead of Out.
+type Hub struct {
+	sync.Mutex
+
+	subs map[*Subscription]bool
+}
+
+// NewHub makes a Hub without any Subscriptions.
+func NewHub() *Hub {
+	return &Hub{
+		subs: make(map[*Subscription]bool),
+	}
+}
+
+// Subscribe registers a Subscription with the given Filter (nil for
+// everything), buffer size, and overflow policy (defaulting to
+// Block).
+func (h *Hub) Subscribe(f *Filter, buffer int, policy string) *Subscription {
+	if policy == "" {
+		policy = Block
+	}
+	s := &Subscription{
+		Filter: f,
+		Policy: policy,
+		c:      make(chan *Report, buffer),
+		hub:    h,
+		done:   make(chan bool),
+	}
+	h.Lock()
+	h.subs[s] = true
+	h.Unlock()
+	return s
+}
+
+// Len returns the number of Subscriptions.
+func (h *Hub) Len() int {
+	h.Lock()
+	defer h.Unlock()
+	return len(h.subs)
+}
+
+// remove unregisters the Subscription and closes its channel.
+//
+// The caller must hold the Subscription's lock but not the Hub's.
+func (h *Hub) remove(s *Subscription) {
+	if !s.closed {
+		s.closed = true
+		close(s.c)
+	}
+	h.Lock()
+	delete(h.subs, s)
+	h.Unlock()
+}
+
+// Publish delivers the Reports to the matching Subscriptions.
+//
+// Publish returns early if the context is done, and it only blocks
+// for Subscriptions with the Block policy.  Publish doesn't hold the
+// Hub's lock while delivering, so a blocked Subscription doesn't
+// block Subscribe, Close, or Len.
+func (h *Hub) Publish(ctx context.Context, rs []*Report) {
+	h.Lock()
+	subs := make([]*Subscription, 0, len(h.subs))
+	for s := range h.subs {
+		subs = append(subs, s)
+	}
+	h.Unlock()
+
+	for _, s := range subs {
+		for _, r := range rs {
+			if !s.Filter.Matches(r) {
+				continue
+			}
+			if !h.deliver(ctx, s, r) {
+				break
+			}
+		}
+	}
+}
+
+// deliver sends the Report to the Subscription according to its
+// policy.  Returns false if the Subscription can't take more reports.
+func (h *Hub) deliver(ctx context.Context, s *Subscription, r *Report) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	select {
+	case s.c <- r:
+		return true
+	case <-s.done:
+		h.remove(s)
+		return false
+	default:
+	}
+
+	switch s.Policy {
+	case DropNewest:
+		atomic.AddUint64(&s.dropped, 1)
+		return true
+	case DropOldest:
+		for {
+			select {
+			case s.c <- r:
+				return true
+			default:
+			}
+			select {
+			case <-s.c:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	case Disconnect:
+		s.err = ErrOverflow
+		s.once.Do(func() {
+			close(s.done)
+		})
+		h.remove(s)
+		return false
+	default: // Block
+		select {
+		case <-ctx.Done():
+			return false
+		case <-s.done:
+			h.remove(s)
+			return false
+		case s.c <- r:
+			return true
+		}
+	}
+}
+
+// Run publishes everything from the given channel (typically a Node's
+// Out) until the context is done or the channel is closed.
+func (h *Hub) Run(ctx context.Context, in <-chan []*Report) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rs, ok := <-in:
+			if !ok {
+				return
+			}
+			h.Publish(ctx, rs)
+		}
+	}
+}
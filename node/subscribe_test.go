@@ -0,0 +1,218 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRegion(t *testing.T) {
+	var (
+		pacific = &Region{MinLat: -10, MaxLat: 10, MinLon: 170, MaxLon: -170}
+		leo     = &Region{MinLat: -90, MaxLat: 90, MinLon: -180, MaxLon: 180, MaxAlt: 2000}
+	)
+	for _, p := range []LatLonAlt{{Lon: 175}, {Lon: -175}, {Lon: 185}} {
+		if !pacific.Contains(p) {
+			t.Fatalf("pacific should contain %v", p)
+		}
+	}
+	for _, p := range []LatLonAlt{{Lon: 0}, {Lat: 20, Lon: 180}} {
+		if pacific.Contains(p) {
+			t.Fatalf("pacific shouldn't contain %v", p)
+		}
+	}
+	if !leo.Contains(LatLonAlt{Alt: 400}) || leo.Contains(LatLonAlt{Alt: 35786}) {
+		t.Fatal("altitude")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var (
+		a  = testTLEs(t, "a", 2)
+		r0 = &Report{
+			Dist:  5,
+			Speed: 0.1,
+			Objs: []State{
+				{Obj: a[0].TLE, Publisher: "a", Type: "payload", LLA: LatLonAlt{Lat: 1, Lon: 2}},
+				{Obj: a[1].TLE, Publisher: "b", Type: "debris", LLA: LatLonAlt{Lat: 1, Lon: 3}},
+			},
+		}
+	)
+
+	for i, f := range []*Filter{
+		nil,
+		{},
+		{CatNums: []string{"050001"}},
+		{Publishers: []string{"x", "b"}},
+		{Types: []string{"debris"}, MaxDist: 5},
+		{Kinds: []string{""}, MinSpeed: 0.01, MaxSpeed: 1},
+		{Regions: []Region{{MinLat: 0, MaxLat: 2, MinLon: 2.5, MaxLon: 4}}},
+	} {
+		if !f.Matches(r0) {
+			t.Fatalf("%d should match", i)
+		}
+	}
+
+	for i, f := range []*Filter{
+		{CatNums: []string{"50002"}},
+		{Publishers: []string{"x"}},
+		{Types: []string{"rocket"}},
+		{Kinds: []string{ConsistencyReport}},
+		{MaxDist: 1},
+		{MinSpeed: 1},
+		{MaxSpeed: 0.01},
+		{CatNums: []string{"50000"}, MaxDist: 1},
+		{Regions: []Region{{MinLat: 10, MaxLat: 20, MinLon: -180, MaxLon: 180}}},
+	} {
+		if f.Matches(r0) {
+			t.Fatalf("%d shouldn't match", i)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		h  = NewHub()
+		rs = []*Report{{Id: "0"}, {Id: "1"}, {Id: "2"}}
+
+		newest     = h.Subscribe(nil, 2, DropNewest)
+		oldest     = h.Subscribe(nil, 2, DropOldest)
+		disconnect = h.Subscribe(nil, 2, Disconnect)
+		none       = h.Subscribe(&Filter{Kinds: []string{ConsistencyReport}}, 0, Block)
+	)
+
+	h.Publish(ctx, rs)
+
+	ids := func(s *Subscription) string {
+		acc := ""
+		for {
+			select {
+			case r, ok := <-s.C():
+				if !ok {
+					return acc + "."
+				}
+				acc += r.Id
+			default:
+				return acc
+			}
+		}
+	}
+
+	if got := ids(newest); got != "01" || newest.Dropped() != 1 {
+		t.Fatalf("newest: %s (%d)", got, newest.Dropped())
+	}
+	if got := ids(oldest); got != "12" || oldest.Dropped() != 1 {
+		t.Fatalf("oldest: %s (%d)", got, oldest.Dropped())
+	}
+	if got := ids(disconnect); got != "01." || disconnect.Err() != ErrOverflow {
+		t.Fatalf("disconnect: %s (%v)", got, disconnect.Err())
+	}
+	if got := ids(none); got != "" {
+		t.Fatalf("none: %s", got)
+	}
+	if n := h.Len(); n != 3 {
+		t.Fatalf("%d subscriptions", n)
+	}
+
+	// A blocked publisher gives up when the Subscription is
+	// closed.
+	block := h.Subscribe(nil, 0, Block)
+	published := make(chan bool)
+	go func() {
+		h.Publish(ctx, rs)
+		close(published)
+	}()
+	if r := <-block.C(); r.Id != "0" {
+		t.Fatal(r.Id)
+	}
+	block.Close()
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publisher blocked")
+	}
+	if n := h.Len(); n != 3 {
+		t.Fatalf("%d subscriptions", n)
+	}
+}
+
+func TestHub(t *testing.T) {
+	var (
+		a   = testTLEs(t, "a", 4)
+		all = reportKeys(testSlices(t, testNode(), testEpoch, a))
+
+		n = testNode()
+		h = NewHub()
+
+		everything = h.Subscribe(nil, 1024, Block)
+		watched    = h.Subscribe(&Filter{CatNums: []string{"50000"}}, 1024, DropNewest)
+	)
+	n.Hub = h
+
+	if rs := testSlices(t, n, testEpoch, a); len(rs) != 0 {
+		t.Fatalf("%d reports on Out", len(rs))
+	}
+	everything.Close()
+	watched.Close()
+
+	keys := func(s *Subscription) []string {
+		var rs []*Report
+		for r := range s.C() {
+			rs = append(rs, r)
+		}
+		return reportKeys(rs)
+	}
+
+	sameKeys(t, "everything", keys(everything), all)
+	sameKeys(t, "watched", keys(watched), involving(all, "50000/a"))
+}
+
+func TestHubBlocked(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		h         = NewHub()
+		block     = h.Subscribe(nil, 0, Block)
+		published = make(chan bool)
+	)
+
+	go func() {
+		h.Publish(ctx, []*Report{{Id: "0"}, {Id: "1"}})
+		close(published)
+	}()
+
+	// The publisher is now blocked on the second report, which
+	// shouldn't keep other Subscriptions from coming and going.
+	if r := <-block.C(); r.Id != "0" {
+		t.Fatal(r.Id)
+	}
+	done := make(chan bool)
+	go func() {
+		other := h.Subscribe(nil, 1, DropNewest)
+		h.Len()
+		other.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("hub blocked")
+	}
+
+	if r := <-block.C(); r.Id != "1" {
+		t.Fatal(r.Id)
+	}
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publisher blocked")
+	}
+	block.Close()
+	if n := h.Len(); n != 0 {
+		t.Fatalf("%d subscriptions", n)
+	}
+}
@@ -0,0 +1,173 @@
+package node
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ut-astria/spi/prop"
+)
+
+// Lighting for an object comes from a low-precision solar ephemeris
+// (good to about 0.01 degrees for decades around 2000) and a shadow
+// model for a spherical Earth.  The Sun's position is mean-of-date,
+// which is close enough to TEME for lighting.
+
+// Shadow models.
+const (
+	// ConicalShadow has an umbra and a penumbra.
+	ConicalShadow = "conical"
+
+	// CylindricalShadow is a cylinder with the Earth's radius
+	// behind the Earth.  There is no penumbra.
+	CylindricalShadow = "cylindrical"
+)
+
+// Eclipse states.
+const (
+	Sunlit   = "sunlit"
+	Penumbra = "penumbra"
+	Umbra    = "umbra"
+)
+
+const (
+	// astronomicalUnit is in km.
+	astronomicalUnit = 149597870.7
+
+	// sunRadius is in km.
+	sunRadius = 696000.0
+)
+
+// Lighting describes an object's illumination.
+type Lighting struct {
+	// Eclipse is Sunlit, Penumbra, or Umbra.
+	Eclipse string
+
+	// Sunlight is the fraction (0 to 1) of the Sun's disk that's
+	// visible from the object.
+	Sunlight float32
+
+	// Beta is the solar beta angle (deg): the angle between the
+	// orbital plane and the direction to the Sun, which is
+	// positive when the Sun is north of the plane.
+	Beta float32 `units:"deg"`
+
+	// Phase is the angle (deg) at the object between the Sun and
+	// the Earth's center.  An object near zero is fully lit as seen
+	// from below.
+	Phase float32 `units:"deg"`
+}
+
+// CheckShadowModel returns an error for an unknown shadow model.  The
+// empty string is ConicalShadow.
+func CheckShadowModel(model string) error {
+	switch model {
+	case "", ConicalShadow, CylindricalShadow:
+		return nil
+	}
+	return fmt.Errorf("unknown shadow model %q", model)
+}
+
+// SunECI returns the Sun's position (km) at t.
+func SunECI(t time.Time) [3]float64 {
+	var (
+		rad = math.Pi / 180
+
+		// Julian centuries since J2000.
+		jd = float64(t.UnixNano())/1e9/86400 + 2440587.5
+		c  = (jd - 2451545.0) / 36525
+
+		meanLon = 280.460 + 36000.771*c
+		anomaly = (357.5291092 + 35999.05034*c) * rad
+
+		lon = (meanLon + 1.914666471*math.Sin(anomaly) + 0.019994643*math.Sin(2*anomaly)) * rad
+		obl = (23.439291 - 0.0130042*c) * rad
+		r   = (1.000140612 - 0.016708617*math.Cos(anomaly) - 0.000139589*math.Cos(2*anomaly)) * astronomicalUnit
+
+		sl, cl = math.Sincos(lon)
+		so, co = math.Sincos(obl)
+	)
+	return [3]float64{r * cl, r * co * sl, r * so * sl}
+}
+
+// Light computes the Lighting for the Ephemeris at t using the given
+// shadow model ("" is ConicalShadow).
+func Light(t time.Time, e prop.Ephemeris, model string) *Lighting {
+	var (
+		deg = 180 / math.Pi
+
+		r   = vec(e.ECI)
+		s   = SunECI(t)
+		rs  = [3]float64{s[0] - r[0], s[1] - r[1], s[2] - r[2]}
+		dr  = norm(r)
+		drs = norm(rs)
+
+		// The angle at the object between the Sun and the
+		// Earth's center.
+		phase = math.Acos(clamp(-dot(r, rs) / (dr * drs)))
+
+		sunlight float64
+	)
+
+	switch model {
+	case CylindricalShadow:
+		sunlight = 1
+		u := unit(s)
+		along := dot(r, u)
+		perp := [3]float64{r[0] - along*u[0], r[1] - along*u[1], r[2] - along*u[2]}
+		if along < 0 && norm(perp) < equatorialRadius {
+			sunlight = 0
+		}
+	default:
+		sunlight = sunFraction(
+			math.Asin(sunRadius/drs),
+			math.Asin(clamp(equatorialRadius/dr)),
+			phase)
+	}
+
+	l := &Lighting{
+		Eclipse:  Penumbra,
+		Sunlight: float32(sunlight),
+		Phase:    float32(phase * deg),
+	}
+	switch {
+	case sunlight <= 0:
+		l.Eclipse = Umbra
+	case 1 <= sunlight:
+		l.Eclipse = Sunlit
+	}
+
+	if h := cross(r, vec(e.V)); 0 < norm(h) {
+		l.Beta = float32(math.Asin(clamp(dot(unit(h), unit(s)))) * deg)
+	}
+
+	return l
+}
+
+// sunFraction returns the visible fraction of the Sun's disk given
+// the apparent radii of the Sun (a) and the Earth (b) and the angle
+// between their centers (c), all in radians.
+//
+// See Montenbruck and Gill, Satellite Orbits, section 3.4.2.
+func sunFraction(a, b, c float64) float64 {
+	switch {
+	case a+b <= c:
+		return 1
+	case c <= b-a:
+		return 0
+	case c <= a-b:
+		// The Earth is entirely inside the Sun's disk.
+		return 1 - (b*b)/(a*a)
+	}
+	var (
+		x    = (c*c + a*a - b*b) / (2 * c)
+		y    = math.Sqrt(math.Max(0, a*a-x*x))
+		area = a*a*math.Acos(clamp(x/a)) + b*b*math.Acos(clamp((c-x)/b)) - c*y
+	)
+	return 1 - area/(math.Pi*a*a)
+}
+
+// clamp limits x to [-1,1] for inverse trigonometric functions.
+func clamp(x float64) float64 {
+	return math.Max(-1, math.Min(1, x))
+}
@@ -0,0 +1,132 @@
+package node
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/prop"
+)
+
+func TestSunECI(t *testing.T) {
+	var (
+		deg = 180 / math.Pi
+
+		// The March equinox and the June solstice in 2020.
+		equinox  = time.Date(2020, 3, 20, 3, 50, 0, 0, time.UTC)
+		solstice = time.Date(2020, 6, 20, 21, 44, 0, 0, time.UTC)
+	)
+
+	s := SunECI(equinox)
+	if d := norm(s) / astronomicalUnit; d < 0.99 || 1.0 < d {
+		t.Fatalf("equinox distance %f AU", d)
+	}
+	if ra := math.Atan2(s[1], s[0]) * deg; 0.05 < math.Abs(ra) {
+		t.Fatalf("equinox right ascension %f", ra)
+	}
+
+	s = SunECI(solstice)
+	if d := norm(s) / astronomicalUnit; d < 1.01 || 1.02 < d {
+		t.Fatalf("solstice distance %f AU", d)
+	}
+	if dec := math.Asin(s[2]/norm(s)) * deg; 0.05 < math.Abs(dec-23.44) {
+		t.Fatalf("solstice declination %f", dec)
+	}
+}
+
+func TestLight(t *testing.T) {
+	var (
+		at = time.Date(2020, 6, 20, 0, 0, 0, 0, time.UTC)
+		s  = unit(SunECI(at))
+		// p is perpendicular to the direction to the Sun.
+		p = unit(cross(s, [3]float64{0, 0, 1}))
+
+		// position returns the position that is along km toward
+		// the Sun and perp km in the direction of p.
+		position = func(along, perp float64) prop.Vect {
+			return prop.Vect{
+				X: float32(along*s[0] + perp*p[0]),
+				Y: float32(along*s[1] + perp*p[1]),
+				Z: float32(along*s[2] + perp*p[2]),
+			}
+		}
+		light = func(along, perp float64, model string) *Lighting {
+			return Light(at, prop.Ephemeris{ECI: position(along, perp)}, model)
+		}
+	)
+
+	for _, model := range []string{ConicalShadow, CylindricalShadow} {
+		if l := light(7000, 0, model); l.Eclipse != Sunlit || l.Sunlight != 1 || l.Phase < 179 {
+			t.Fatalf("%s day side: %#v", model, l)
+		}
+		if l := light(-7000, 0, model); l.Eclipse != Umbra || l.Sunlight != 0 || 1 < l.Phase {
+			t.Fatalf("%s night side: %#v", model, l)
+		}
+		if l := light(-7000, 7000, model); l.Eclipse != Sunlit || math.Abs(float64(l.Phase)-45) > 0.1 {
+			t.Fatalf("%s beside the shadow: %#v", model, l)
+		}
+	}
+
+	// At the edge of the cylinder, a conical shadow has a
+	// penumbra.
+	if l := light(-7000, equatorialRadius, ConicalShadow); l.Eclipse != Penumbra || l.Sunlight <= 0 || 1 <= l.Sunlight {
+		t.Fatalf("edge: %#v", l)
+	}
+	// The penumbra gets lighter farther from the Earth-Sun line.
+	last := float32(0)
+	for perp := 6300.0; perp < 6500; perp += 10 {
+		l := light(-7000, perp, ConicalShadow)
+		if l.Sunlight < last {
+			t.Fatalf("%f km: sunlight %f < %f", perp, l.Sunlight, last)
+		}
+		last = l.Sunlight
+	}
+
+	// An orbit whose plane is perpendicular to the Sun.
+	e := prop.Ephemeris{
+		ECI: position(0, 7000),
+		V:   vect(cross(s, p)),
+	}
+	if l := Light(at, e, ""); math.Abs(float64(l.Beta)-90) > 0.1 {
+		t.Fatalf("beta %f", l.Beta)
+	}
+	e.V = position(1, 0)
+	if l := Light(at, e, ""); math.Abs(float64(l.Beta)) > 0.1 {
+		t.Fatalf("beta %f", l.Beta)
+	}
+}
+
+func vect(v [3]float64) prop.Vect {
+	return prop.Vect{X: float32(v[0]), Y: float32(v[1]), Z: float32(v[2])}
+}
+
+func TestCheckShadowModel(t *testing.T) {
+	for _, model := range []string{"", ConicalShadow, CylindricalShadow} {
+		if err := CheckShadowModel(model); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := CheckShadowModel("spherical"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestReportLighting(t *testing.T) {
+	n := testNode()
+	n.ShadowModel = CylindricalShadow
+	rs := testSlices(t, n, testEpoch, testTLEs(t, "a", 3))
+	if len(rs) == 0 {
+		t.Fatal("no reports")
+	}
+	for _, r := range rs {
+		for _, s := range r.Objs {
+			l := s.Light
+			if l == nil {
+				t.Fatalf("%s has no lighting", s.Name)
+			}
+			if l.Eclipse != Sunlit && l.Eclipse != Umbra {
+				t.Fatalf("%s eclipse %s", s.Name, l.Eclipse)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package node
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/tle"
+)
+
+// Orbital regimes (see Regime).
+const (
+	LEO = "LEO"
+	MEO = "MEO"
+	GEO = "GEO"
+	HEO = "HEO"
+)
+
+// Regime classifies an orbit crudely:
+//
+//	LEO  apogee altitude at most 2,000 km
+//	HEO  otherwise, eccentricity at least 0.25
+//	GEO  otherwise, semi-major axis within 1,500 km of 42,164 km
+//	MEO  everything else
+func Regime(o *Orbit) string {
+	switch {
+	case o.Apogee-earthRadius <= 2000:
+		return LEO
+	case 0.25 <= o.E:
+		return HEO
+	case math.Abs((o.Perigee+o.Apogee)/2-42164) <= 1500:
+		return GEO
+	default:
+		return MEO
+	}
+}
+
+// ScreeningVolume is a box or an ellipsoid in the primary object's
+// RIC (radial, in-track, cross-track) frame.
+//
+// A volume applies to a pair when the primary's regime and the types
+// (see tle.GetType) of the primary and secondary match.  Empty
+// criteria match anything.
+type ScreeningVolume struct {
+	// Regime is the primary's regime (LEO, MEO, GEO, or HEO).
+	Regime string `json:",omitempty"`
+
+	// Primary is the primary's type.
+	Primary string `json:",omitempty"`
+
+	// Secondary is the secondary's type.
+	Secondary string `json:",omitempty"`
+
+	// Ellipsoid selects an ellipsoid instead of a box.
+	Ellipsoid bool `json:",omitempty"`
+
+	// Radial, InTrack, and CrossTrack are the half-extents (km).
+	Radial, InTrack, CrossTrack float32
+}
+
+// Check returns an error for a volume without positive extents or
+// with an unknown Regime.
+func (v *ScreeningVolume) Check() error {
+	switch v.Regime {
+	case "", LEO, MEO, GEO, HEO:
+	default:
+		return fmt.Errorf("unknown regime %q", v.Regime)
+	}
+	if !(0 < v.Radial && 0 < v.InTrack && 0 < v.CrossTrack) {
+		return fmt.Errorf("extents (%v,%v,%v) aren't all positive", v.Radial, v.InTrack, v.CrossTrack)
+	}
+	return nil
+}
+
+// Radius is the radius (km) of the smallest sphere that contains the
+// volume.
+func (v *ScreeningVolume) Radius() float32 {
+	if v.Ellipsoid {
+		return float32(math.Max(float64(v.Radial), math.Max(float64(v.InTrack), float64(v.CrossTrack))))
+	}
+	var (
+		r = float64(v.Radial)
+		i = float64(v.InTrack)
+		c = float64(v.CrossTrack)
+	)
+	return float32(math.Sqrt(r*r + i*i + c*c))
+}
+
+// Contains reports whether the given RIC offset (km) is in the
+// volume.
+func (v *ScreeningVolume) Contains(ric prop.Vect) bool {
+	var (
+		r = float64(ric.X) / float64(v.Radial)
+		i = float64(ric.Y) / float64(v.InTrack)
+		c = float64(ric.Z) / float64(v.CrossTrack)
+	)
+	if v.Ellipsoid {
+		return r*r+i*i+c*c <= 1
+	}
+	return math.Abs(r) <= 1 && math.Abs(i) <= 1 && math.Abs(c) <= 1
+}
+
+// matches reports whether the volume applies to the given primary and
+// secondary.
+func (v *ScreeningVolume) matches(primary, secondary *tle.SGP4TLE) bool {
+	if v.Primary != "" && v.Primary != primary.GetType() {
+		return false
+	}
+	if v.Secondary != "" && v.Secondary != secondary.GetType() {
+		return false
+	}
+	if v.Regime != "" && v.Regime != Regime(OrbitOf(primary)) {
+		return false
+	}
+	return true
+}
+
+// RIC returns the position of the secondary relative to the primary
+// in the primary's RIC frame.
+//
+// X is radial, Y is in-track, and Z is cross-track.
+func RIC(primary, secondary prop.Ephemeris) prop.Vect {
+	var (
+		r = vec(primary.ECI)
+		v = vec(primary.V)
+		d = vec(secondary.ECI)
+
+		R = unit(r)
+		C = unit(cross(r, v))
+		I = cross(C, R)
+	)
+	for k := range d {
+		d[k] -= r[k]
+	}
+	return prop.Vect{
+		X: float32(dot(d, R)),
+		Y: float32(dot(d, I)),
+		Z: float32(dot(d, C)),
+	}
+}
+
+// RICVelocity returns the velocity of the secondary relative to the
+// primary projected onto the primary's RIC frame (see RIC).
+func RICVelocity(primary, secondary prop.Ephemeris) prop.Vect {
+	var (
+		r = vec(primary.ECI)
+		v = vec(primary.V)
+		d = vec(secondary.V)
+
+		R = unit(r)
+		C = unit(cross(r, v))
+		I = cross(C, R)
+	)
+	for k := range d {
+		d[k] -= v[k]
+	}
+	return prop.Vect{
+		X: float32(dot(d, R)),
+		Y: float32(dot(d, I)),
+		Z: float32(dot(d, C)),
+	}
+}
+
+func vec(v prop.Vect) [3]float64 {
+	return [3]float64{float64(v.X), float64(v.Y), float64(v.Z)}
+}
+
+func unit(v [3]float64) [3]float64 {
+	l := norm(v)
+	return [3]float64{v[0] / l, v[1] / l, v[2] / l}
+}
+
+// volume finds the first ScreeningVolume that applies to the pair.
+//
+// Either object can be the primary, and the returned bool is true
+// when the primary is the second object.
+func (n *Node) volume(o0, o1 *tle.SGP4TLE) (*ScreeningVolume, bool) {
+	for i := range n.ScreeningVolumes {
+		v := &n.ScreeningVolumes[i]
+		if v.matches(o0, o1) {
+			return v, false
+		}
+		if v.matches(o1, o0) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// screened reports whether the pair (with the given ephemerides) is
+// within the applicable ScreeningVolume.  When no volume applies, the
+// given distance is compared with dist.
+func (n *Node) screened(o0, o1 *tle.SGP4TLE, es []prop.Ephemeris, d, dist float32) bool {
+	v, swap := n.volume(o0, o1)
+	if v == nil {
+		return d <= dist
+	}
+	if swap {
+		return v.Contains(RIC(es[1], es[0]))
+	}
+	return v.Contains(RIC(es[0], es[1]))
+}
+
+// indexDist is IndexDist enlarged (if necessary) to contain every
+// ScreeningVolume.
+func (n *Node) indexDist() float32 {
+	d := n.IndexDist
+	for i := range n.ScreeningVolumes {
+		if r := n.ScreeningVolumes[i].Radius(); d < r {
+			d = r
+		}
+	}
+	return d
+}
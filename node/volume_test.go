@@ -0,0 +1,141 @@
+package node
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ut-astria/spi/prop"
+)
+
+func TestRegime(t *testing.T) {
+	for want, o := range map[string]*Orbit{
+		LEO: NewOrbit(6778, 0.001, 51.6, 0, 0),
+		MEO: NewOrbit(26560, 0.01, 55, 0, 0),
+		GEO: NewOrbit(42164, 0.0002, 0.1, 0, 0),
+		HEO: NewOrbit(26600, 0.74, 63.4, 0, 270),
+	} {
+		if got := Regime(o); got != want {
+			t.Fatalf("%s != %s", got, want)
+		}
+	}
+}
+
+func TestRIC(t *testing.T) {
+	var (
+		p = prop.Ephemeris{
+			ECI: prop.Vect{X: 7000},
+			V:   prop.Vect{Y: 7.5},
+		}
+		s = prop.Ephemeris{
+			ECI: prop.Vect{X: 7001, Y: 2, Z: 3},
+			V:   prop.Vect{X: 1, Y: 9.5, Z: 3},
+		}
+		ric = RIC(p, s)
+		vel = RICVelocity(p, s)
+	)
+	for i, got := range []float32{ric.X, ric.Y, ric.Z} {
+		if want := float32(i + 1); 1e-3 < math.Abs(float64(got-want)) {
+			t.Fatalf("%v", ric)
+		}
+	}
+	for i, got := range []float32{vel.X, vel.Y, vel.Z} {
+		if want := float32(i + 1); 1e-3 < math.Abs(float64(got-want)) {
+			t.Fatalf("%v", vel)
+		}
+	}
+}
+
+func TestScreeningVolume(t *testing.T) {
+	var (
+		box = &ScreeningVolume{Radial: 1, InTrack: 25, CrossTrack: 25}
+		ell = &ScreeningVolume{Radial: 1, InTrack: 25, CrossTrack: 25, Ellipsoid: true}
+
+		corner = prop.Vect{X: 0.9, Y: 20, Z: 20}
+		radial = prop.Vect{X: 2}
+	)
+
+	if !box.Contains(corner) {
+		t.Fatal("box should contain the corner")
+	}
+	if ell.Contains(corner) {
+		t.Fatal("ellipsoid shouldn't contain the corner")
+	}
+	if box.Contains(radial) || ell.Contains(radial) {
+		t.Fatal("radial offset should be outside")
+	}
+	if r := ell.Radius(); r != 25 {
+		t.Fatalf("ellipsoid radius %f", r)
+	}
+	if r := box.Radius(); r < 35 || 36 < r {
+		t.Fatalf("box radius %f", r)
+	}
+}
+
+func TestScreeningVolumes(t *testing.T) {
+	var (
+		a   = testTLEs(t, "a", 4)
+		all = active(testSlices(t, testNode(), testEpoch, a))
+
+		// Neighbors are about 6 km apart in-track.
+		neighbors = make([]*Report, 0, len(all))
+	)
+	for _, r := range all {
+		if r.Dist < 7 {
+			neighbors = append(neighbors, r)
+		}
+	}
+	if len(neighbors) == 0 || len(neighbors) == len(all) {
+		t.Fatal("need reports for neighbors and others")
+	}
+
+	screen := func(vs ...ScreeningVolume) []string {
+		n := testNode()
+		n.ScreeningVolumes = vs
+		return reportKeys(active(testSlices(t, n, testEpoch, a)))
+	}
+
+	sameKeys(t, "in-track",
+		screen(ScreeningVolume{Regime: LEO, Radial: 1, InTrack: 7, CrossTrack: 1}),
+		reportKeys(neighbors))
+
+	sameKeys(t, "radial",
+		screen(ScreeningVolume{Radial: 7, InTrack: 1, CrossTrack: 7}),
+		nil)
+
+	sameKeys(t, "not applicable",
+		screen(ScreeningVolume{Primary: "debris", Radial: 1, InTrack: 1, CrossTrack: 1}),
+		reportKeys(all))
+
+	sameKeys(t, "first applicable",
+		screen(
+			ScreeningVolume{Regime: GEO, Radial: 1, InTrack: 1, CrossTrack: 1},
+			ScreeningVolume{Secondary: "payload", Radial: 1, InTrack: 7, CrossTrack: 1, Ellipsoid: true}),
+		reportKeys(neighbors))
+}
+
+func TestScreeningVolumeCheck(t *testing.T) {
+	good := []ScreeningVolume{
+		{Radial: 1, InTrack: 25, CrossTrack: 25},
+		{Regime: GEO, Radial: 1, InTrack: 25, CrossTrack: 25, Ellipsoid: true},
+	}
+	bad := []ScreeningVolume{
+		{},
+		{Radial: 1, InTrack: 25},
+		{Radial: -1, InTrack: 25, CrossTrack: 25},
+		{Regime: "lunar", Radial: 1, InTrack: 25, CrossTrack: 25},
+	}
+	for _, v := range good {
+		if err := v.Check(); err != nil {
+			t.Errorf("%#v: %s", v, err)
+		}
+	}
+	for _, v := range bad {
+		if err := v.Check(); err == nil {
+			t.Errorf("expected an error for %#v", v)
+		}
+		c := Cfg{ScreeningVolumes: []ScreeningVolume{v}}
+		if err := c.Check(); err == nil {
+			t.Errorf("expected a Cfg error for %#v", v)
+		}
+	}
+}
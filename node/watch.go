@@ -0,0 +1,218 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ut-astria/spi/index"
+)
+
+// A watch list restricts reports to pairs that involve at least one
+// watched object.  Every live TLE is still indexed, but each Index
+// only searches for the neighbors of an unwatched object among the
+// watched ones (see index.Index.SetWatching).  Debris-on-debris
+// encounters therefore cost little more than indexing.
+//
+// Catalog partitioning (see partition.go) uses the same mechanism
+// when there is no watch list.
+
+// WatchList specifies the objects of interest.
+//
+// An object is watched if it matches any of the criteria.
+type WatchList struct {
+	// CatNums are catalog numbers.  Leading zeros are ignored.
+	CatNums []string `json:",omitempty"`
+
+	// Publishers are publisher names (see PubTLE.Publisher).
+	Publishers []string `json:",omitempty"`
+
+	// Types are object types as given by tle.GetType (for example,
+	// "payload").
+	Types []string `json:",omitempty"`
+}
+
+// ReadWatchList reads a JSON WatchList from the given file.
+func ReadWatchList(filename string) (*WatchList, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var w WatchList
+	if err := json.NewDecoder(f).Decode(&w); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	return &w, nil
+}
+
+// watcher is a compiled WatchList.
+type watcher struct {
+	cats, pubs, types map[string]bool
+}
+
+// normCatNum removes leading zeros from a catalog number.
+func normCatNum(s string) string {
+	s = strings.TrimLeft(strings.TrimSpace(s), "0")
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// compile makes a watcher for the WatchList.
+//
+// A nil WatchList watches everything, and the returned watcher is
+// nil.
+func (w *WatchList) compile() *watcher {
+	if w == nil {
+		return nil
+	}
+	set := func(xs []string, f func(string) string) map[string]bool {
+		acc := make(map[string]bool, len(xs))
+		for _, x := range xs {
+			acc[f(x)] = true
+		}
+		return acc
+	}
+	return &watcher{
+		cats:  set(w.CatNums, normCatNum),
+		pubs:  set(w.Publishers, strings.TrimSpace),
+		types: set(w.Types, strings.TrimSpace),
+	}
+}
+
+// watches reports whether the object is watched.
+func (w *watcher) watches(p *PubTLE) bool {
+	if w == nil {
+		return true
+	}
+	return w.cats[normCatNum(p.TLE.CatNum)] ||
+		w.pubs[p.Publisher] ||
+		w.types[p.TLE.GetType()]
+}
+
+// Watched reports whether the Node's current watch list includes the
+// object.
+func (n *Node) Watched(p *PubTLE) bool {
+	return n.watcher.watches(p)
+}
+
+// watching reports whether the indexes restrict searches to active
+// objects (see active).
+func (n *Node) watching() bool {
+	return n.watcher != nil || 1 < n.Partitions
+}
+
+// active reports whether the object initiates searches in the
+// indexes (see index.Index.SetWatching) given the watcher.  With a
+// watch list, watched objects are active.  Otherwise, the objects
+// this Node owns (see OwnsObject) are active.
+func (n *Node) active(w *watcher, p *PubTLE) bool {
+	if w != nil {
+		return w.watches(p)
+	}
+	return n.OwnsObject(p.TLE.CatNum)
+}
+
+// activeKeys returns the set of keys for the given IndexInputs that
+// are active.  Returns nil when every object is active.
+func (n *Node) activeKeys(iis map[index.Key]*IndexInput) map[index.Key]bool {
+	if !n.watching() {
+		return nil
+	}
+	acc := make(map[index.Key]bool)
+	for k, ii := range iis {
+		if n.active(n.watcher, ii.Sat) {
+			acc[k] = true
+		}
+	}
+	return acc
+}
+
+// rewatch installs a new WatchList.  Cfg.WatchList is not changed.
+//
+// Each indexed object whose status changes is resubmitted to the
+// given indexes, which results in novel reports for newly watched
+// pairs and cancellations for pairs that are no longer watched.
+// Unindexed objects whose status changes are resubmitted as well (see
+// IndexInput.unindexed) for their overflights.
+func (n *Node) rewatch(ctx context.Context, indexes map[time.Time]*Index, wl *WatchList) error {
+	var (
+		old     = n.watcher
+		w       = wl.compile()
+		was     = n.watching()
+		changed = make(map[index.Key]*IndexInput)
+		others  = make(map[index.Key]*IndexInput)
+		indexed = n.indexable()
+	)
+
+	for k, ii := range n.candidates() {
+		if n.active(old, ii.Sat) == n.active(w, ii.Sat) {
+			continue
+		}
+		if _, have := indexed[k]; have {
+			changed[k] = ii
+		} else {
+			others[k] = ii
+		}
+	}
+
+	n.logf(ctx, "rewatch: %d changed", len(changed))
+
+	n.watcher = w
+	for k, ii := range others {
+		changed[k] = &IndexInput{
+			Id:        ii.Id,
+			Key:       k,
+			Sat:       ii.Sat,
+			unindexed: true,
+		}
+	}
+
+	if !was && n.watching() {
+		// Everything was active.
+		n.each(ctx, indexes, func(i *index.Index) {
+			i.SetWatching(true)
+		})
+	}
+
+	err := n.process(ctx, indexes, changed)
+
+	if was && !n.watching() {
+		// Everything is now active.
+		n.each(ctx, indexes, func(i *index.Index) {
+			i.SetWatching(false)
+		})
+	}
+
+	return err
+}
+
+// each calls the given function for each index in its goroutine, and
+// it waits for those calls to complete.
+func (n *Node) each(ctx context.Context, indexes map[time.Time]*Index, f func(*index.Index)) {
+	var (
+		done = ctx.Done()
+		wg   = sync.WaitGroup{}
+	)
+LOOP:
+	for _, i := range indexes {
+		wg.Add(1)
+		select {
+		case <-done:
+			wg.Done()
+			break LOOP
+		case i.in <- func(i *index.Index, t time.Time) {
+			f(i)
+			wg.Done()
+		}:
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,91 @@
+package node
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// involving returns the keys that mention the given name.
+func involving(keys []string, name string) []string {
+	acc := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if strings.Contains(k, name) {
+			acc = append(acc, k)
+		}
+	}
+	return acc
+}
+
+func TestWatchList(t *testing.T) {
+	var (
+		a   = testTLEs(t, "a", 4)
+		all = reportKeys(active(testSlices(t, testNode(), testEpoch, a)))
+	)
+
+	if len(involving(all, "50000/a")) == len(all) {
+		t.Fatal("not enough reports")
+	}
+
+	t.Run("catnum", func(t *testing.T) {
+		n := testNode()
+		n.WatchList = &WatchList{
+			CatNums: []string{"050000"},
+		}
+		got := reportKeys(active(testSlices(t, n, testEpoch, a)))
+		sameKeys(t, "watched", got, involving(all, "50000/a"))
+	})
+
+	t.Run("publisher", func(t *testing.T) {
+		n := testNode()
+		n.WatchList = &WatchList{
+			Publishers: []string{"a"},
+		}
+		got := reportKeys(active(testSlices(t, n, testEpoch, a)))
+		sameKeys(t, "watched", got, all)
+	})
+
+	t.Run("type", func(t *testing.T) {
+		n := testNode()
+		n.WatchList = &WatchList{
+			Types: []string{"debris"},
+		}
+		if rs := testSlices(t, n, testEpoch, a); len(rs) != 0 {
+			t.Fatalf("%d reports", len(rs))
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		var (
+			n     = testNode()
+			input = func(ctx context.Context, indexes map[time.Time]*Index) error {
+				return n.processNew(ctx, a, indexes)
+			}
+			rewatch = func(wl *WatchList) testStep {
+				return func(ctx context.Context, indexes map[time.Time]*Index) error {
+					return n.rewatch(ctx, indexes, wl)
+				}
+			}
+			w0 = &WatchList{CatNums: []string{"50000"}}
+			w1 = &WatchList{CatNums: []string{"50001"}}
+		)
+
+		n.WatchList = w0
+		got := reportKeys(active(testSteps(t, n, testEpoch, input, rewatch(w1))))
+		sameKeys(t, "w0 then w1", got, involving(all, "50001/a"))
+
+		n = testNode()
+		n.WatchList = w0
+		got = reportKeys(active(testSteps(t, n, testEpoch, input, rewatch(nil))))
+		sameKeys(t, "w0 then nil", got, all)
+
+		n = testNode()
+		got = reportKeys(active(testSteps(t, n, testEpoch, input, rewatch(w1))))
+		sameKeys(t, "nil then w1", got, involving(all, "50001/a"))
+
+		n = testNode()
+		got = reportKeys(active(testSteps(t, n, testEpoch, rewatch(w0), input, rewatch(nil), rewatch(w1))))
+		sameKeys(t, "w0, nil, w1", got, involving(all, "50001/a"))
+	})
+}
@@ -0,0 +1,430 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/ut-astria/spi/index"
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/sgp4"
+	"github.com/ut-astria/spi/tle"
+)
+
+// What-if screening finds close approaches between a hypothetical
+// object (say a candidate maneuver or launch) and the live catalog
+// without changing the Node's state: nothing is interned, indexed,
+// announced, or reported.
+//
+// For a time slice this Node owns, the slice's index finds the live
+// objects near the hypothetical object.  Other times, and live
+// objects that the pre-filters kept out of the indexes, require
+// propagating those objects, which is much more expensive.
+//
+// Each step (a multiple of Resolution in the window) is screened like
+// a time slice (including scanning and ScreeningVolumes), and each
+// run of consecutive steps for a live object results in one Report
+// for the closest approach.
+
+// WhatIfReport is the Report Kind for a close approach found by
+// Screen.  The hypothetical object is the first State.
+const WhatIfReport = "what-if"
+
+// MaxScreenSteps limits the number of steps (Resolutions) in a
+// ScreenRequest's window.
+const MaxScreenSteps = 24 * 3600
+
+// ScreenRequest is a hypothetical object and a time window for
+// Screen.
+type ScreenRequest struct {
+	// Name names the hypothetical object in Reports.  The default
+	// is the catalog number for a TLE and WhatIfReport otherwise.
+	Name string
+
+	// Obj is the hypothetical object.  When it's a *tle.SGP4TLE,
+	// Reports include it, and live objects with the same catalog
+	// number (presumably the object's current trajectory) are
+	// ignored.
+	Obj prop.Propagator
+
+	// From and To bound the window.  A zero From is now, and a
+	// zero To is From plus the Node's horizon.
+	From, To time.Time
+
+	// Dist, if positive, is the maximum distance (km) instead of
+	// ScanDist (and ScreeningVolumes).
+	Dist float32
+}
+
+// MaxScreenProps limits the number of propagations (live objects
+// times steps) that a ScreenRequest can require without the help of
+// the indexes.
+const MaxScreenProps = 10 * 1000 * 1000
+
+// screening is a ScreenRequest for Run.
+type screening struct {
+	req   *ScreenRequest
+	reply chan screened
+}
+
+// screened is the result of a screening: a plan for the request.
+type screened struct {
+	p   *screenPlan
+	err error
+}
+
+// screenPlan is what a ScreenRequest needs from the Node's state.
+// Run makes the plan, and the screening itself happens elsewhere.
+type screenPlan struct {
+	req *ScreenRequest
+
+	// t0 and to bound the steps.
+	t0, to time.Time
+
+	// dist is the reporting distance, and qd is the (possibly
+	// larger) distance for finding approaches.
+	dist, qd float32
+
+	// indexes are the slices to query (if any).
+	indexes map[time.Time]*Index
+
+	// byId has the indexed candidates.
+	byId map[index.Id]*IndexInput
+
+	// all are the candidates to propagate without an index, and
+	// others are those that aren't in the indexes.
+	all, others []*IndexInput
+}
+
+// Screen finds close approaches between the hypothetical object and
+// the live catalog (see WhatIfReport).
+//
+// The Node's Run loop takes a snapshot of the live catalog between
+// batches of input.  The screening then happens in the caller's
+// goroutine (until the context is done), and only queries wait for
+// the indexes.
+func (n *Node) Screen(ctx context.Context, req *ScreenRequest) ([]*Report, error) {
+	s := &screening{
+		req:   req,
+		reply: make(chan screened, 1),
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case n.screenings <- s:
+	}
+	var res screened
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-s.reply:
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	return n.execScreen(ctx, res.p)
+}
+
+// approach is a live object near the hypothetical object at a step.
+type approach struct {
+	t  time.Time
+	ii *IndexInput
+}
+
+// whatIf performs a ScreenRequest using the given indexes.
+func (n *Node) whatIf(ctx context.Context, indexes map[time.Time]*Index, req *ScreenRequest) ([]*Report, error) {
+	p, err := n.planScreen(indexes, req)
+	if err != nil {
+		return nil, err
+	}
+	return n.execScreen(ctx, p)
+}
+
+// planScreen checks the ScreenRequest, and it makes a plan from the
+// current candidates and the given indexes.
+func (n *Node) planScreen(indexes map[time.Time]*Index, req *ScreenRequest) (*screenPlan, error) {
+	if req.Obj == nil {
+		return nil, fmt.Errorf("no object to screen")
+	}
+
+	from, to := req.From, req.To
+	if from.IsZero() {
+		from = time.Now().UTC()
+	}
+	if to.IsZero() {
+		to = from.Add(time.Duration(n.Horizon) * n.Resolution)
+	}
+	t0 := RoundTime(from, n.Resolution)
+	if t0.Before(from) {
+		t0 = t0.Add(n.Resolution)
+	}
+	if to.Before(t0) {
+		return nil, fmt.Errorf("empty window [%s,%s]", from.Format(time.RFC3339Nano), to.Format(time.RFC3339Nano))
+	}
+	steps := int64(to.Sub(t0)/n.Resolution + 1)
+	if MaxScreenSteps < steps {
+		return nil, fmt.Errorf("window has %d steps (more than %d)", steps, MaxScreenSteps)
+	}
+
+	var (
+		o, isTLE = req.Obj.(*tle.SGP4TLE)
+
+		p = &screenPlan{
+			req:  req,
+			t0:   t0,
+			to:   to,
+			dist: n.ScanDist,
+			qd:   n.indexDist(),
+		}
+	)
+	if 0 < req.Dist {
+		p.dist = req.Dist
+	}
+	// Indexes can't find anything beyond their distance.
+	useIndexes := p.dist <= p.qd
+	if p.qd < p.dist {
+		p.qd = p.dist
+	}
+
+	indexed := n.indexable()
+	p.byId = make(map[index.Id]*IndexInput, len(indexed))
+	for k, ii := range n.candidates() {
+		if isTLE && ii.Sat.TLE.CatNum == o.CatNum {
+			continue
+		}
+		p.all = append(p.all, ii)
+		if _, have := indexed[k]; have && useIndexes {
+			p.byId[ii.Id] = ii
+		} else {
+			p.others = append(p.others, ii)
+		}
+	}
+
+	// Run changes its map of indexes.
+	var queried int64
+	if useIndexes {
+		p.indexes = make(map[time.Time]*Index)
+		for t := t0; !t.After(to); t = t.Add(n.Resolution) {
+			if i, have := indexes[t]; have {
+				p.indexes[t] = i
+				queried++
+			}
+		}
+	}
+
+	if props := queried*int64(len(p.others)) + (steps-queried)*int64(len(p.all)); MaxScreenProps < props {
+		return nil, fmt.Errorf("screening requires %d propagations (more than %d)", props, MaxScreenProps)
+	}
+
+	return p, nil
+}
+
+// execScreen carries out a screenPlan.
+func (n *Node) execScreen(ctx context.Context, p *screenPlan) ([]*Report, error) {
+	var (
+		req     = p.req
+		workers = n.PropWorkers
+		nears   = make([]approach, 0, 32)
+	)
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+
+	for t := p.t0; !t.After(p.to); t = t.Add(n.Resolution) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		e, err := req.Obj.Prop(t)
+		if err != nil {
+			return nil, err
+		}
+
+		brute := p.all
+		if i, have := p.indexes[t]; have {
+			q := index.Pos{X: e.ECI.X, Y: e.ECI.Y, Z: e.ECI.Z}
+			ipps, err := n.query(ctx, i, q, p.qd)
+			switch err {
+			case nil:
+				for _, ipp := range ipps {
+					if ii, have := p.byId[ipp.Id]; have {
+						nears = append(nears, approach{t, ii})
+					}
+				}
+				brute = p.others
+			case errStopped:
+				// The slice is gone, so propagate.
+			default:
+				return nil, err
+			}
+		}
+
+		if len(brute) == 0 {
+			continue
+		}
+		ps := make([]prop.Propagator, len(brute))
+		for k, ii := range brute {
+			ps[k] = ii.Sat.TLE
+		}
+		b := &tle.Batch{
+			Workers: workers,
+		}
+		m := b.PropBatch(ps, []time.Time{t})
+		for k, ii := range brute {
+			e1, err := m.At(k, 0)
+			if err != nil {
+				continue
+			}
+			if e.ECI.Dist(e1.ECI) <= p.qd {
+				nears = append(nears, approach{t, ii})
+			}
+		}
+	}
+
+	return n.approaches(ctx, req, nears, p.dist)
+}
+
+// errStopped is the error from query when the Index has stopped.
+var errStopped = fmt.Errorf("index stopped")
+
+// query finds the indexed positions near p in the Index's goroutine.
+func (n *Node) query(ctx context.Context, i *Index, p index.Pos, d float32) ([]index.IdProbPos, error) {
+	var (
+		ipps []index.IdProbPos
+		err  error
+		done = make(chan bool)
+	)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-i.stop:
+		return nil, errStopped
+	case i.in <- func(x *index.Index, t time.Time) {
+		ipps, err = x.Query(p, d)
+		close(done)
+	}:
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-i.stop:
+		return nil, errStopped
+	case <-done:
+	}
+	return ipps, err
+}
+
+// approaches makes a Report for the closest approach in each run of
+// consecutive steps for each live object.
+func (n *Node) approaches(ctx context.Context, req *ScreenRequest, nears []approach, dist float32) ([]*Report, error) {
+	var (
+		o, isTLE = req.Obj.(*tle.SGP4TLE)
+
+		// runs is the current run for each key.
+		runs = make(map[index.Key]*Report)
+		last = make(map[index.Key]time.Time)
+		acc  = make([]*Report, 0, len(nears))
+	)
+
+	sort.SliceStable(nears, func(i, j int) bool {
+		return nears[i].t.Before(nears[j].t)
+	})
+
+	for _, a := range nears {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		k := a.ii.Key
+		if prev, have := last[k]; have && a.t.Sub(prev) != n.Resolution {
+			// The run might not have had anything close
+			// enough.
+			if r, have := runs[k]; have {
+				acc = append(acc, r)
+			}
+			delete(runs, k)
+		}
+		last[k] = a.t
+
+		d, es, then, err := ScanPair(n.Scan, a.t, time.Second, 100, req.Obj, a.ii.Sat.TLE, 0)
+		if err != nil {
+			if !sgp4.HasDecayed(err) {
+				n.logf(ctx, "what-if %s: %s", a.ii.Sat.Name(), err)
+			}
+			continue
+		}
+
+		ok := d <= dist
+		if req.Dist <= 0 && isTLE {
+			ok = n.screened(o, a.ii.Sat.TLE, es, d, dist)
+		}
+		if !ok {
+			continue
+		}
+
+		if r, have := runs[k]; have && r.Dist <= d {
+			continue
+		}
+		r, err := n.whatIfReport(a.t, then, d, es, req, a.ii.Sat)
+		if err != nil {
+			return nil, err
+		}
+		runs[k] = r
+	}
+	for _, r := range runs {
+		acc = append(acc, r)
+	}
+
+	sort.Slice(acc, func(i, j int) bool {
+		if acc[i].At.Equal(acc[j].At) {
+			return acc[i].Sig < acc[j].Sig
+		}
+		return acc[i].At.Before(acc[j].At)
+	})
+
+	return acc, nil
+}
+
+// whatIfReport assembles a WhatIfReport.
+func (n *Node) whatIfReport(t, then time.Time, d float32, es []prop.Ephemeris, req *ScreenRequest, o1 *PubTLE) (*Report, error) {
+	l0, err := ECIToLLA(t, es[0].ECI)
+	if err != nil {
+		return nil, err
+	}
+	s0 := State{
+		Name:  req.Name,
+		ECI:   es[0].ECI,
+		Vel:   es[0].V,
+		LLA:   *l0,
+		Light: Light(t, es[0], n.ShadowModel),
+	}
+	if o, is := req.Obj.(*tle.SGP4TLE); is {
+		s0.Obj = o
+		s0.Age = int64(o.ApproxAge(t).Seconds())
+		s0.Type = o.GetType()
+		if s0.Name == "" {
+			s0.Name = o.CatNum
+		}
+	}
+	if s0.Name == "" {
+		s0.Name = WhatIfReport
+	}
+
+	s1, err := n.state(t, es[1], o1)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{
+		Kind:  WhatIfReport,
+		At:    then,
+		Dist:  d,
+		Speed: es[0].V.Dist(es[1].V),
+		Objs:  []State{s0, s1},
+	}
+	if err := sign(r, false); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,155 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/index"
+)
+
+func TestWhatIf(t *testing.T) {
+	var (
+		a = testTLEs(t, "a", 3)
+
+		// The hypothetical object is a new TLE for a[0], which
+		// is about 6 km from a[1] and 12 km from a[2].
+		req = &ScreenRequest{
+			Name: "maneuver",
+			Obj:  republish(t, a[:1], "a", "20016.08334000")[0].TLE,
+			From: testEpoch,
+			To:   testEpoch.Add(9 * time.Second),
+		}
+	)
+
+	screen := func(n *Node, req *ScreenRequest, empty bool, rs *[]*Report) testStep {
+		return func(ctx context.Context, indexes map[time.Time]*Index) error {
+			if empty {
+				indexes = nil
+			}
+			var err error
+			*rs, err = n.whatIf(ctx, indexes, req)
+			return err
+		}
+	}
+	process := func(n *Node) testStep {
+		return func(ctx context.Context, indexes map[time.Time]*Index) error {
+			return n.processNew(ctx, a, indexes)
+		}
+	}
+
+	var (
+		n                  = testNode()
+		indexed, brute, rs []*Report
+	)
+	testSteps(t, n, testEpoch, process(n), screen(n, req, false, &indexed), screen(n, req, true, &brute))
+
+	if len(indexed) != 2 {
+		t.Fatalf("%d reports", len(indexed))
+	}
+	for _, r := range indexed {
+		if r.Kind != WhatIfReport || r.Objs[0].Name != "maneuver" || r.Objs[0].Obj == nil || r.Id == "" {
+			t.Fatalf("report %#v", r)
+		}
+		if name := r.Objs[1].Name; name != "50001/a" && name != "50002/a" {
+			t.Fatalf("screened against %s", name)
+		}
+	}
+	sameKeys(t, "brute force", reportKeys(brute), reportKeys(indexed))
+
+	t.Run("dist", func(t *testing.T) {
+		req := *req
+		req.Dist = 12
+		n := testNode()
+		testSteps(t, n, testEpoch, process(n), screen(n, &req, false, &rs))
+		if len(rs) != 1 || rs[0].Objs[1].Name != "50001/a" || 12 < rs[0].Dist {
+			t.Fatalf("reports %#v", rs)
+		}
+	})
+
+	t.Run("beyond", func(t *testing.T) {
+		// Outside the horizon, so no indexes.
+		req := *req
+		req.From = testEpoch.Add(time.Minute)
+		req.To = req.From.Add(9 * time.Second)
+		n := testNode()
+		testSteps(t, n, testEpoch, process(n), screen(n, &req, false, &rs))
+		if len(rs) != 2 || rs[0].At.Before(req.From) {
+			t.Fatalf("reports %#v", rs)
+		}
+	})
+
+	t.Run("untouched", func(t *testing.T) {
+		want := testSlices(t, testNode(), testEpoch, a)
+
+		n := testNode()
+		ids := 0
+		count := func(ctx context.Context, indexes map[time.Time]*Index) error {
+			ids = n.interns.IdCount()
+			return nil
+		}
+		got := testSteps(t, n, testEpoch, process(n), count, screen(n, req, false, &rs))
+
+		sameKeys(t, "reports", reportKeys(got), reportKeys(want))
+		if len(n.live) != len(a) || n.interns.IdCount() != ids {
+			t.Fatalf("live %d, ids %d (was %d)", len(n.live), n.interns.IdCount(), ids)
+		}
+	})
+
+	t.Run("gap", func(t *testing.T) {
+		// Two runs for a[1] separated by a gap, and nothing is
+		// close enough.
+		n := testNode()
+		ii := &IndexInput{Key: index.Key{CatalogNum: 1}, Sat: a[1]}
+		nears := []approach{
+			{testEpoch, ii},
+			{testEpoch.Add(n.Resolution), ii},
+			{testEpoch.Add(3 * n.Resolution), ii},
+		}
+		rs, err := n.approaches(context.Background(), req, nears, 0.001)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rs) != 0 {
+			t.Fatalf("%d reports", len(rs))
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := testNode().whatIf(ctx, nil, req); err != context.Canceled {
+			t.Fatalf("error %v", err)
+		}
+	})
+
+	t.Run("props", func(t *testing.T) {
+		// Too many propagations without the indexes.  The
+		// hypothetical object replaces one of the live objects.
+		var (
+			n    = testNode()
+			many = testTLEs(t, "a", MaxScreenProps/MaxScreenSteps+2)
+			req  = *req
+		)
+		n.Horizon = 1
+		req.To = req.From.Add((MaxScreenSteps - 1) * time.Second)
+		req.Dist = 2 * n.IndexDist
+		testSlices(t, n, testEpoch, many)
+		if _, err := n.planScreen(nil, &req); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		bad := []*ScreenRequest{
+			{},
+			{Obj: req.Obj, From: testEpoch, To: testEpoch.Add(-time.Second)},
+			{Obj: req.Obj, From: testEpoch, To: testEpoch.Add(2 * MaxScreenSteps * time.Second)},
+		}
+		for _, req := range bad {
+			if _, err := testNode().whatIf(context.Background(), nil, req); err == nil {
+				t.Errorf("expected an error for %#v", req)
+			}
+		}
+	})
+}
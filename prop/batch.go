@@ -0,0 +1,49 @@
+package prop
+
+import (
+	"time"
+)
+
+// BatchPropagator can compute Ephemerides for many Propagators at
+// many times.
+type BatchPropagator interface {
+	PropBatch(ps []Propagator, ts []time.Time) *Matrix
+}
+
+// Matrix is an objects × times matrix of Ephemerides.
+type Matrix struct {
+	// Objs is the number of objects (rows).
+	Objs int
+
+	// Times is the number of times (columns).
+	Times int
+
+	// Es holds the Ephemerides in row-major order.
+	Es []Ephemeris
+
+	// Errs holds propagation errors (if any) in row-major order.
+	Errs []error
+}
+
+// NewMatrix allocates a Matrix for the given number of objects and
+// times.
+func NewMatrix(objs, times int) *Matrix {
+	return &Matrix{
+		Objs:  objs,
+		Times: times,
+		Es:    make([]Ephemeris, objs*times),
+		Errs:  make([]error, objs*times),
+	}
+}
+
+// At returns the Ephemeris for object i at time j.
+func (m *Matrix) At(i, j int) (Ephemeris, error) {
+	k := i*m.Times + j
+	return m.Es[k], m.Errs[k]
+}
+
+// Set stores the Ephemeris for object i at time j.
+func (m *Matrix) Set(i, j int, e Ephemeris, err error) {
+	k := i*m.Times + j
+	m.Es[k], m.Errs[k] = e, err
+}
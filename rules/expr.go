@@ -0,0 +1,668 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ut-astria/spi/node"
+)
+
+// Expr is a compiled expression.
+//
+// An expression is a predicate on a node.Report.  The syntax is
+// similar to jq's:
+//
+//	Speed > 10 and Dist < 5
+//	.Objs[].Type == "debris" and not Canceled
+//	Objs[0].LLA.Alt < 500 || Objs[1].LLA.Alt < 500
+//	Objs[].Name =~ "^STARLINK"
+//
+// A path names fields (with an optional leading '.') and array
+// elements.  "[]" selects every element, and a comparison is true if
+// it holds for any of the values that its paths select.  A path on
+// its own is true if any value it selects is true, a non-zero number,
+// or a non-empty string.
+//
+// Operators: ==, !=, <, <=, >, >=, =~ (regular expression), and (&&),
+// or (||), and not (!).  Literals are numbers, double-quoted strings,
+// true, and false.
+//
+// Times are compared chronologically.  A string literal compared
+// with a time is an RFC3339 time or just a date (2006-01-02).
+//
+// Compile rejects paths that can't select a number, string, bool,
+// or time from a node.Report.
+type Expr struct {
+	src string
+	e   pred
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Compile parses an expression.
+func Compile(src string) (*Expr, error) {
+	ts, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{ts: ts}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.s, t.pos)
+	}
+	return &Expr{
+		src: src,
+		e:   e,
+	}, nil
+}
+
+// MustCompile is Compile that panics on error.
+func MustCompile(src string) *Expr {
+	e, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Eval evaluates the expression for the given value (usually a
+// *node.Report).
+func (e *Expr) Eval(x interface{}) bool {
+	return e.e.eval(reflect.ValueOf(x))
+}
+
+// Lexing
+
+type tokenKind int
+
+const (
+	tEOF tokenKind = iota
+	tPath
+	tNum
+	tStr
+	tBool
+	tOp
+	tAnd
+	tOr
+	tNot
+	tLParen
+	tRParen
+)
+
+type token struct {
+	kind tokenKind
+	s    string
+	pos  int
+}
+
+var pathPattern = regexp.MustCompile(`^\.?[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*|\[[0-9]*\])*`)
+
+func lex(src string) ([]token, error) {
+	var (
+		acc = make([]token, 0, 16)
+		i   = 0
+	)
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			acc = append(acc, token{tLParen, "(", i})
+			i++
+		case c == ')':
+			acc = append(acc, token{tRParen, ")", i})
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+			}
+			if len(src) <= j {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			s, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("bad string at %d: %s", i, err)
+			}
+			acc = append(acc, token{tStr, s, i})
+			i = j + 1
+		case c == '-' || c == '+' || unicode.IsDigit(c):
+			j := i + 1
+			for ; j < len(src) && strings.ContainsRune("0123456789.eE+-", rune(src[j])); j++ {
+			}
+			if _, err := strconv.ParseFloat(src[i:j], 64); err != nil {
+				return nil, fmt.Errorf("bad number %q at %d", src[i:j], i)
+			}
+			acc = append(acc, token{tNum, src[i:j], i})
+			i = j
+		case strings.ContainsRune("=!<>&|", c):
+			var op string
+			for _, o := range []string{"==", "!=", "<=", ">=", "=~", "&&", "||", "<", ">", "!"} {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			switch op {
+			case "":
+				return nil, fmt.Errorf("unknown operator at %d", i)
+			case "&&":
+				acc = append(acc, token{tAnd, op, i})
+			case "||":
+				acc = append(acc, token{tOr, op, i})
+			case "!":
+				acc = append(acc, token{tNot, op, i})
+			default:
+				acc = append(acc, token{tOp, op, i})
+			}
+			i += len(op)
+		default:
+			s := pathPattern.FindString(src[i:])
+			if s == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			switch s {
+			case "and":
+				acc = append(acc, token{tAnd, s, i})
+			case "or":
+				acc = append(acc, token{tOr, s, i})
+			case "not":
+				acc = append(acc, token{tNot, s, i})
+			case "true", "false":
+				acc = append(acc, token{tBool, s, i})
+			default:
+				acc = append(acc, token{tPath, s, i})
+			}
+			i += len(s)
+		}
+	}
+	return append(acc, token{tEOF, "", len(src)}), nil
+}
+
+// Parsing
+
+type parser struct {
+	ts []token
+	i  int
+}
+
+func (p *parser) peek() token {
+	return p.ts[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.ts[p.i]
+	if t.kind != tEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) or() (pred, error) {
+	x, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tOr {
+		p.next()
+		y, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		x = &orNode{x, y}
+	}
+	return x, nil
+}
+
+func (p *parser) and() (pred, error) {
+	x, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tAnd {
+		p.next()
+		y, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		x = &andNode{x, y}
+	}
+	return x, nil
+}
+
+func (p *parser) not() (pred, error) {
+	if p.peek().kind == tNot {
+		p.next()
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{x}, nil
+	}
+	return p.cmp()
+}
+
+func (p *parser) cmp() (pred, error) {
+	if p.peek().kind == tLParen {
+		p.next()
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tRParen {
+			return nil, fmt.Errorf("expected ) at %d", t.pos)
+		}
+		return x, nil
+	}
+
+	x, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tOp {
+		return &truthNode{x}, nil
+	}
+	op := p.next()
+	y, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if x, err = timeLiteral(x, y); err != nil {
+		return nil, fmt.Errorf("%s at %d", err, op.pos)
+	}
+	if y, err = timeLiteral(y, x); err != nil {
+		return nil, fmt.Errorf("%s at %d", err, op.pos)
+	}
+	c := &cmpNode{
+		op: op.s,
+		x:  x,
+		y:  y,
+	}
+	if op.s == "=~" {
+		lit, is := y.(*literal)
+		if !is || lit.v.Kind() != reflect.String {
+			return nil, fmt.Errorf("=~ at %d requires a string literal", op.pos)
+		}
+		if c.re, err = regexp.Compile(lit.v.String()); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (p *parser) operand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tPath:
+		p, err := parsePath(t.s)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.leaf(reportType); err != nil {
+			return nil, fmt.Errorf("%s at %d: %s", t.s, t.pos, err)
+		}
+		return p, nil
+	case tNum:
+		f, _ := strconv.ParseFloat(t.s, 64)
+		return &literal{reflect.ValueOf(f)}, nil
+	case tStr:
+		return &literal{reflect.ValueOf(t.s)}, nil
+	case tBool:
+		return &literal{reflect.ValueOf(t.s == "true")}, nil
+	case tEOF:
+		return nil, fmt.Errorf("unexpected end")
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", t.s, t.pos)
+	}
+}
+
+// Evaluation
+
+type pred interface {
+	eval(x reflect.Value) bool
+}
+
+type operand interface {
+	values(x reflect.Value) []reflect.Value
+}
+
+type orNode struct {
+	x, y pred
+}
+
+func (n *orNode) eval(x reflect.Value) bool {
+	return n.x.eval(x) || n.y.eval(x)
+}
+
+type andNode struct {
+	x, y pred
+}
+
+func (n *andNode) eval(x reflect.Value) bool {
+	return n.x.eval(x) && n.y.eval(x)
+}
+
+type notNode struct {
+	x pred
+}
+
+func (n *notNode) eval(x reflect.Value) bool {
+	return !n.x.eval(x)
+}
+
+type truthNode struct {
+	x operand
+}
+
+func (n *truthNode) eval(x reflect.Value) bool {
+	for _, v := range n.x.values(x) {
+		switch v.Kind() {
+		case reflect.Bool:
+			if v.Bool() {
+				return true
+			}
+		case reflect.String:
+			if v.String() != "" {
+				return true
+			}
+		case reflect.Float64:
+			if v.Float() != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type cmpNode struct {
+	op   string
+	x, y operand
+	re   *regexp.Regexp
+}
+
+func (n *cmpNode) eval(x reflect.Value) bool {
+	ys := n.y.values(x)
+	for _, a := range n.x.values(x) {
+		if n.re != nil {
+			if a.Kind() == reflect.String && n.re.MatchString(a.String()) {
+				return true
+			}
+			continue
+		}
+		for _, b := range ys {
+			if compare(n.op, a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compare compares two normalized values (see normalize).  Values of
+// different kinds are only unequal.
+func compare(op string, a, b reflect.Value) bool {
+	if a.Kind() != b.Kind() {
+		return op == "!="
+	}
+	var c int
+	switch a.Kind() {
+	case reflect.Float64:
+		switch x, y := a.Float(), b.Float(); {
+		case x < y:
+			c = -1
+		case y < x:
+			c = 1
+		}
+	case reflect.String:
+		c = strings.Compare(a.String(), b.String())
+	case reflect.Bool:
+		if a.Bool() != b.Bool() {
+			c = 1
+		}
+		switch op {
+		case "==", "!=":
+		default:
+			return false
+		}
+	}
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return 0 < c
+	case ">=":
+		return 0 <= c
+	}
+	return false
+}
+
+type literal struct {
+	v reflect.Value
+}
+
+func (l *literal) values(x reflect.Value) []reflect.Value {
+	return []reflect.Value{l.v}
+}
+
+// step is a path component: a field name, an index, or (when index
+// is -1) every element.
+type step struct {
+	field string
+	index int
+}
+
+type path []step
+
+func parsePath(s string) (path, error) {
+	var acc path
+	s = strings.TrimPrefix(s, ".")
+	for _, part := range strings.Split(s, ".") {
+		name := part
+		if i := strings.Index(part, "["); 0 <= i {
+			name = part[:i]
+		}
+		if name != "" {
+			acc = append(acc, step{field: name})
+		}
+		rest := part[len(name):]
+		for rest != "" {
+			j := strings.Index(rest, "]")
+			if idx := rest[1:j]; idx == "" {
+				acc = append(acc, step{index: -1})
+			} else {
+				i, err := strconv.Atoi(idx)
+				if err != nil {
+					return nil, err
+				}
+				acc = append(acc, step{index: i})
+			}
+			rest = rest[j+1:]
+		}
+	}
+	return acc, nil
+}
+
+// leaf returns the type of the values that the path selects from a
+// value of the given type, or nil when that type isn't known until
+// evaluation (say behind an interface).  Returns an error for an
+// unknown field or for values that can't be compared.
+func (p path) leaf(t reflect.Type) (reflect.Type, error) {
+	for _, s := range p {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch {
+		case t.Kind() == reflect.Interface:
+			return nil, nil
+		case s.field != "":
+			if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String {
+				t = t.Elem()
+				continue
+			}
+			if t.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("%s has no field %s", t, s.field)
+			}
+			sf, have := t.FieldByName(s.field)
+			if !have || sf.PkgPath != "" {
+				return nil, fmt.Errorf("%s has no field %s", t, s.field)
+			}
+			t = sf.Type
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("%s isn't an array", t)
+		}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return t, nil
+	}
+	switch t.Kind() {
+	case reflect.Interface,
+		reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String, reflect.Bool:
+		return t, nil
+	}
+	return nil, fmt.Errorf("%s isn't a number, string, bool, or time", t)
+}
+
+func (p path) values(x reflect.Value) []reflect.Value {
+	vs := []reflect.Value{x}
+	for _, s := range p {
+		next := make([]reflect.Value, 0, len(vs))
+		for _, v := range vs {
+			v = deref(v)
+			if !v.IsValid() {
+				continue
+			}
+			switch {
+			case s.field != "":
+				if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+					if f := v.MapIndex(reflect.ValueOf(s.field)); f.IsValid() {
+						next = append(next, f)
+					}
+					continue
+				}
+				if v.Kind() != reflect.Struct {
+					continue
+				}
+				if sf, have := v.Type().FieldByName(s.field); have && sf.PkgPath == "" {
+					next = append(next, v.FieldByIndex(sf.Index))
+				}
+			case v.Kind() != reflect.Slice && v.Kind() != reflect.Array:
+			case s.index < 0:
+				for i := 0; i < v.Len(); i++ {
+					next = append(next, v.Index(i))
+				}
+			case s.index < v.Len():
+				next = append(next, v.Index(s.index))
+			}
+		}
+		vs = next
+	}
+
+	acc := make([]reflect.Value, 0, len(vs))
+	for _, v := range vs {
+		if v = normalize(v); v.IsValid() {
+			acc = append(acc, v)
+		}
+	}
+	return acc
+}
+
+// deref follows pointers and interfaces.
+func deref(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	reportType = reflect.TypeOf(node.Report{})
+)
+
+// timeLayouts are the layouts for a string literal compared with a
+// time.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
+// timeLiteral returns the operand x as a time (see normalize) if it's
+// a string literal and y is a path to a time.  Otherwise x is
+// returned.
+func timeLiteral(x, y operand) (operand, error) {
+	lit, is := x.(*literal)
+	if !is || lit.v.Kind() != reflect.String {
+		return x, nil
+	}
+	p, is := y.(path)
+	if !is {
+		return x, nil
+	}
+	if t, _ := p.leaf(reportType); t != timeType {
+		return x, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, lit.v.String()); err == nil {
+			return &literal{normalize(reflect.ValueOf(t))}, nil
+		}
+	}
+	return nil, fmt.Errorf("bad time %q", lit.v.String())
+}
+
+// seconds returns the time in seconds since the Unix epoch.
+func seconds(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
+
+// normalize converts a value to a float64, string, or bool (or an
+// invalid Value for anything else).  A time is seconds since the Unix
+// epoch.
+func normalize(v reflect.Value) reflect.Value {
+	v = deref(v)
+	if !v.IsValid() {
+		return v
+	}
+	if v.Type() == timeType {
+		return reflect.ValueOf(seconds(v.Interface().(time.Time)))
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(v.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(float64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(float64(v.Uint()))
+	case reflect.String:
+		return reflect.ValueOf(v.String())
+	case reflect.Bool:
+		return reflect.ValueOf(v.Bool())
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,192 @@
+// Package rules tags, drops, and routes node.Reports based on
+// predicates (see Expr).
+//
+// A RuleSet is usually read from a JSON file:
+//
+//	{"Rules": [
+//	  {"When": "Canceled", "Routes": ["cancellations"]},
+//	  {"When": "Speed > 10 and Dist < 5", "Tags": ["fast"], "Routes": ["alerts"]},
+//	  {"When": "not (Objs[].Type == \"payload\")", "Drop": true}
+//	]}
+//
+// Rules are evaluated in order.  Every matching rule contributes its
+// tags and routes, and the first matching rule with Drop ends the
+// evaluation.
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/ut-astria/spi/node"
+)
+
+// Rule is a predicate with actions.
+type Rule struct {
+	// Name is optional and just for humans.
+	Name string `json:",omitempty"`
+
+	// When is the Expr source.  An empty expression matches
+	// everything.
+	When string `json:",omitempty"`
+
+	// Tags are added to the Report's Tags.
+	Tags []string `json:",omitempty"`
+
+	// Routes name outputs for the Report.
+	Routes []string `json:",omitempty"`
+
+	// Drop discards the Report.
+	Drop bool `json:",omitempty"`
+
+	expr *Expr
+}
+
+// Matches reports whether the Rule's expression holds for the Report.
+func (r *Rule) Matches(rep *node.Report) bool {
+	return r.expr == nil || r.expr.Eval(rep)
+}
+
+// RuleSet is an ordered list of Rules.
+type RuleSet struct {
+	Rules []*Rule
+}
+
+// Compile compiles each Rule's expression.
+func (rs *RuleSet) Compile() error {
+	for i, r := range rs.Rules {
+		if r.When == "" {
+			r.expr = nil
+			continue
+		}
+		e, err := Compile(r.When)
+		if err != nil {
+			return fmt.Errorf("rule %d (%s): %s", i, r.Name, err)
+		}
+		r.expr = e
+	}
+	return nil
+}
+
+// ParseRuleSet parses and compiles a JSON RuleSet.
+func ParseRuleSet(js []byte) (*RuleSet, error) {
+	var rs RuleSet
+	if err := json.Unmarshal(js, &rs); err != nil {
+		return nil, err
+	}
+	if err := rs.Compile(); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// ReadRuleSet reads a JSON RuleSet from the given file.
+func ReadRuleSet(filename string) (*RuleSet, error) {
+	js, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := ParseRuleSet(js)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	return rs, nil
+}
+
+// Routes returns the distinct route names used by the rules.
+func (rs *RuleSet) Routes() []string {
+	var (
+		acc  = make([]string, 0, len(rs.Rules))
+		seen = make(map[string]bool)
+	)
+	for _, r := range rs.Rules {
+		for _, name := range r.Routes {
+			if !seen[name] {
+				seen[name] = true
+				acc = append(acc, name)
+			}
+		}
+	}
+	return acc
+}
+
+// Apply evaluates the rules for the Report.
+//
+// Apply adds tags to the Report, and it returns the Report's routes
+// (if any) and false if the Report should be dropped.
+func (rs *RuleSet) Apply(rep *node.Report) ([]string, bool) {
+	var routes []string
+	for _, r := range rs.Rules {
+		if !r.Matches(rep) {
+			continue
+		}
+		if r.Drop {
+			return nil, false
+		}
+		for _, tag := range r.Tags {
+			rep.Tags = addString(rep.Tags, tag)
+		}
+		for _, name := range r.Routes {
+			routes = addString(routes, name)
+		}
+	}
+	return routes, true
+}
+
+func addString(xs []string, x string) []string {
+	for _, y := range xs {
+		if x == y {
+			return xs
+		}
+	}
+	return append(xs, x)
+}
+
+// Engine holds a replaceable RuleSet.
+//
+// An Engine is safe for concurrent use.
+type Engine struct {
+	sync.RWMutex
+
+	rules *RuleSet
+}
+
+// NewEngine makes an Engine with the given RuleSet (which can be
+// nil).
+func NewEngine(rs *RuleSet) *Engine {
+	return &Engine{
+		rules: rs,
+	}
+}
+
+// Set replaces the RuleSet.
+func (e *Engine) Set(rs *RuleSet) {
+	e.Lock()
+	e.rules = rs
+	e.Unlock()
+}
+
+// Load replaces the RuleSet with one read from the given file.  If
+// there's an error, the current RuleSet remains.
+func (e *Engine) Load(filename string) error {
+	rs, err := ReadRuleSet(filename)
+	if err != nil {
+		return err
+	}
+	e.Set(rs)
+	return nil
+}
+
+// Apply calls RuleSet.Apply for the current RuleSet.  Without a
+// RuleSet, the Report has no routes and is kept.
+func (e *Engine) Apply(rep *node.Report) ([]string, bool) {
+	e.RLock()
+	rs := e.rules
+	e.RUnlock()
+	if rs == nil {
+		return nil, true
+	}
+	return rs.Apply(rep)
+}
@@ -0,0 +1,182 @@
+package rules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/tle"
+)
+
+func testReport(t *testing.T) *node.Report {
+	o, err := tle.ParseSGP4TLE(`{"CatNum":"25544","TLE":[
+"ISS (ZARYA)",
+"1 25544U 98067A   20016.08333333  .00000000  00000+0  00000+0 0    07",
+"2 25544 051.6443 231.0853 0005022 190.6186 169.4852 15.49165845    00"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &node.Report{
+		At:    time.Date(2020, 1, 16, 2, 0, 0, 0, time.UTC),
+		Dist:  3.5,
+		Speed: 12,
+		Objs: []node.State{
+			{Name: "25544", Obj: o, Type: "payload", Age: 3600, LLA: node.LatLonAlt{Alt: 420}},
+			{Name: "40000/b", Type: "debris", Publisher: "b", LLA: node.LatLonAlt{Alt: 421}},
+		},
+	}
+}
+
+func TestExpr(t *testing.T) {
+	r := testReport(t)
+
+	for _, src := range []string{
+		`Speed > 10 and Dist < 5`,
+		`.Speed > 10 && .Dist < 5`,
+		`Objs[].Type == "debris"`,
+		`Objs[1].Type == "debris" and Objs[0].Type == "payload"`,
+		`Objs[0].LLA.Alt < 500 || Objs[1].LLA.Alt < 500`,
+		`Objs[0].Obj.CatNum == "25544"`,
+		`Objs[].Name =~ "^40"`,
+		`not Canceled`,
+		`!(Canceled)`,
+		`Objs[0].Age >= 3600`,
+		`At < "2020-01-17"`,
+		`At < "2020-01-16T02:00:00.5Z"`,
+		`At == "2020-01-16T02:00:00Z"`,
+		`"2020-01-16T01:59:59.999Z" < At`,
+		`At == At`,
+		`Tags[] == "fast" or not Canceled`,
+		`Objs[0].Publisher != Objs[1].Publisher`,
+		`Objs[1].Publisher`,
+		`10 < Speed`,
+		`Dist == 3.5 and -1 < Dist`,
+		`Canceled == false`,
+	} {
+		e, err := Compile(src)
+		if err != nil {
+			t.Fatalf("%s: %s", src, err)
+		}
+		if !e.Eval(r) {
+			t.Fatalf("%s should be true", src)
+		}
+	}
+
+	for _, src := range []string{
+		`Speed > 10 and Dist > 5`,
+		`Objs[].Type == "rocket"`,
+		`Objs[5].Type == "payload"`,
+		`Canceled`,
+		`Objs[0].Publisher`,
+		`At > "2020-01-16T02:00:00.5Z"`,
+		`Objs[].Name =~ "^STARLINK"`,
+		`Speed == "12"`,
+		`Canceled < true`,
+	} {
+		e, err := Compile(src)
+		if err != nil {
+			t.Fatalf("%s: %s", src, err)
+		}
+		if e.Eval(r) {
+			t.Fatalf("%s should be false", src)
+		}
+	}
+
+	for _, src := range []string{
+		``,
+		`Speed >`,
+		`(Speed > 1`,
+		`Speed = 1`,
+		`Speed > 1 Dist`,
+		`Name =~ Dist`,
+		`Name =~ "("`,
+		`"unterminated`,
+		`Nope == 1`,
+		`not (Objs[].Typ == "payload")`,
+		`Objs.Type == "payload"`,
+		`Objs[0].LLA < 1`,
+		`At < "yesterday"`,
+	} {
+		if _, err := Compile(src); err == nil {
+			t.Fatalf("%q should fail", src)
+		}
+	}
+}
+
+func TestRuleSet(t *testing.T) {
+	rs, err := ParseRuleSet([]byte(`{"Rules":[
+{"When":"Speed > 10","Tags":["fast"],"Routes":["alerts"]},
+{"When":"Objs[].Type == \"debris\"","Tags":["debris","fast"],"Routes":["archive","alerts"]},
+{"When":"Dist > 4","Drop":true},
+{"Tags":["all"]}
+]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rs.Routes(); len(got) != 2 || got[0] != "alerts" || got[1] != "archive" {
+		t.Fatalf("routes: %v", got)
+	}
+
+	r := testReport(t)
+	routes, keep := rs.Apply(r)
+	if !keep {
+		t.Fatal("dropped")
+	}
+	if len(routes) != 2 {
+		t.Fatalf("routes: %v", routes)
+	}
+	if len(r.Tags) != 3 || r.Tags[0] != "fast" || r.Tags[1] != "debris" || r.Tags[2] != "all" {
+		t.Fatalf("tags: %v", r.Tags)
+	}
+
+	r = testReport(t)
+	r.Dist = 5
+	if _, keep := rs.Apply(r); keep {
+		t.Fatal("kept")
+	}
+
+	if _, err := ParseRuleSet([]byte(`{"Rules":[{"When":"Speed >"}]}`)); err == nil {
+		t.Fatal("bad rule accepted")
+	}
+}
+
+func TestEngine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "rules.json")
+
+	e := NewEngine(nil)
+	if _, keep := e.Apply(testReport(t)); !keep {
+		t.Fatal("dropped without rules")
+	}
+
+	write := func(js string) {
+		if err := ioutil.WriteFile(filename, []byte(js), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"Rules":[{"When":"Speed > 10","Drop":true}]}`)
+	if err := e.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+	if _, keep := e.Apply(testReport(t)); keep {
+		t.Fatal("kept")
+	}
+
+	// A bad file doesn't replace the rules.
+	write(`{"Rules":[{"When":"Speed >"}]}`)
+	if err := e.Load(filename); err == nil {
+		t.Fatal("bad rules loaded")
+	}
+	if _, keep := e.Apply(testReport(t)); keep {
+		t.Fatal("kept")
+	}
+}
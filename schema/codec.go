@@ -0,0 +1,282 @@
+package schema
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/golang/protobuf/proto"
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/node/rpc"
+	"github.com/ut-astria/spi/node/rpc/pb"
+	"github.com/ut-astria/spi/tle"
+)
+
+// Encodings.
+const (
+	// JSON is the encoding/json encoding, which is described by
+	// the JSON Schema document.  Streams have one Report per
+	// line.
+	JSON = "json"
+
+	// Protobuf is pb.Report.  Streams prefix each Report with its
+	// length as a uvarint.
+	Protobuf = "protobuf"
+
+	// CBOR is the JSON encoding's data model in CBOR (RFC 8949),
+	// so the JSON Schema document applies to it, too.  Times are
+	// RFC 3339 strings.  Streams are CBOR sequences (RFC 8742).
+	CBOR = "cbor"
+)
+
+// Encodings are the supported encodings.
+var Encodings = []string{JSON, Protobuf, CBOR}
+
+// CheckEncoding returns an error if the encoding isn't supported.
+func CheckEncoding(encoding string) error {
+	for _, e := range Encodings {
+		if e == encoding {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown encoding '%s' (not one of %v)", encoding, Encodings)
+}
+
+var (
+	cborEnc cbor.EncMode
+	cborDec cbor.DecMode
+)
+
+func init() {
+	var err error
+	if cborEnc, err = cbor.CoreDetEncOptions().EncMode(); err != nil {
+		panic(err)
+	}
+	cborDec, err = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Marshal encodes the Report (without any framing).
+func Marshal(encoding string, r *node.Report) ([]byte, error) {
+	switch encoding {
+	case JSON:
+		return json.Marshal(r)
+	case Protobuf:
+		p, err := rpc.ReportToProto(r)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(p)
+	case CBOR:
+		x, err := jsonData(r)
+		if err != nil {
+			return nil, err
+		}
+		return cborEnc.Marshal(x)
+	default:
+		return nil, CheckEncoding(encoding)
+	}
+}
+
+// Unmarshal decodes a Report encoded by Marshal.
+func Unmarshal(encoding string, bs []byte) (*node.Report, error) {
+	switch encoding {
+	case JSON:
+		return unmarshalJSON(bs)
+	case Protobuf:
+		var p pb.Report
+		if err := proto.Unmarshal(bs, &p); err != nil {
+			return nil, err
+		}
+		return rpc.ReportFromProto(&p)
+	case CBOR:
+		var x interface{}
+		if err := cborDec.Unmarshal(bs, &x); err != nil {
+			return nil, err
+		}
+		js, err := json.Marshal(x)
+		if err != nil {
+			return nil, err
+		}
+		return unmarshalJSON(js)
+	default:
+		return nil, CheckEncoding(encoding)
+	}
+}
+
+func unmarshalJSON(js []byte) (*node.Report, error) {
+	var r node.Report
+	if err := json.Unmarshal(js, &r); err != nil {
+		return nil, err
+	}
+
+	// Re-parse the TLEs so that they can be propagated.
+	for i, s := range r.Objs {
+		if s.Obj == nil {
+			continue
+		}
+		js, err := json.Marshal(s.Obj)
+		if err != nil {
+			return nil, err
+		}
+		if r.Objs[i].Obj, err = tle.ParseSGP4TLE(string(js)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &r, nil
+}
+
+// jsonData returns the Report's JSON encoding as generic data with
+// integers as int64s.
+func jsonData(r *node.Report) (interface{}, error) {
+	js, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(js))
+	d.UseNumber()
+	var x interface{}
+	if err := d.Decode(&x); err != nil {
+		return nil, err
+	}
+	return numbers(x), nil
+}
+
+// numbers replaces json.Numbers with int64s or float64s.
+func numbers(x interface{}) interface{} {
+	switch vv := x.(type) {
+	case json.Number:
+		if i, err := vv.Int64(); err == nil {
+			return i
+		}
+		f, _ := vv.Float64()
+		return f
+	case []interface{}:
+		for i, y := range vv {
+			vv[i] = numbers(y)
+		}
+	case map[string]interface{}:
+		for k, y := range vv {
+			vv[k] = numbers(y)
+		}
+	}
+	return x
+}
+
+// Encoder writes a stream of Reports.
+//
+// An Encoder is safe for concurrent use.
+type Encoder struct {
+	sync.Mutex
+
+	encoding string
+	w        io.Writer
+}
+
+// NewEncoder makes an Encoder for the given encoding.
+func NewEncoder(w io.Writer, encoding string) (*Encoder, error) {
+	if err := CheckEncoding(encoding); err != nil {
+		return nil, err
+	}
+	return &Encoder{
+		encoding: encoding,
+		w:        w,
+	}, nil
+}
+
+// Encode writes the Report with the encoding's framing.
+func (e *Encoder) Encode(r *node.Report) error {
+	bs, err := Marshal(e.encoding, r)
+	if err != nil {
+		return err
+	}
+
+	switch e.encoding {
+	case JSON:
+		bs = append(bs, '\n')
+	case Protobuf:
+		prefix := make([]byte, binary.MaxVarintLen64)
+		prefix = prefix[:binary.PutUvarint(prefix, uint64(len(bs)))]
+		bs = append(prefix, bs...)
+	}
+
+	e.Lock()
+	_, err = e.w.Write(bs)
+	e.Unlock()
+	return err
+}
+
+// Decoder reads a stream of Reports written by an Encoder.
+type Decoder struct {
+	encoding string
+	r        *bufio.Reader
+	lines    *bufio.Scanner
+	cbor     *cbor.Decoder
+}
+
+// NewDecoder makes a Decoder for the given encoding.
+func NewDecoder(r io.Reader, encoding string) (*Decoder, error) {
+	d := &Decoder{
+		encoding: encoding,
+	}
+	switch encoding {
+	case JSON:
+		d.lines = bufio.NewScanner(r)
+		d.lines.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	case Protobuf:
+		d.r = bufio.NewReader(r)
+	case CBOR:
+		d.cbor = cborDec.NewDecoder(r)
+	default:
+		return nil, CheckEncoding(encoding)
+	}
+	return d, nil
+}
+
+// Decode returns the next Report or io.EOF.
+func (d *Decoder) Decode() (*node.Report, error) {
+	switch d.encoding {
+	case JSON:
+		for d.lines.Scan() {
+			line := d.lines.Bytes()
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			return unmarshalJSON(line)
+		}
+		if err := d.lines.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	case Protobuf:
+		n, err := binary.ReadUvarint(d.r)
+		if err != nil {
+			return nil, err
+		}
+		bs := make([]byte, n)
+		if _, err := io.ReadFull(d.r, bs); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return Unmarshal(Protobuf, bs)
+	default: // CBOR
+		var x cbor.RawMessage
+		if err := d.cbor.Decode(&x); err != nil {
+			return nil, err
+		}
+		return Unmarshal(CBOR, x)
+	}
+}
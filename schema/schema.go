@@ -0,0 +1,200 @@
+// Package schema defines the wire formats for node.Reports.
+//
+// A Report's Schema field gives the version (node.ReportSchema) of
+// its schema.  The JSON encoding is described by a JSON Schema
+// document (report.schema.json in this directory, generated by
+// JSONSchema), which includes the units of each quantity.
+//
+// Reports can also be encoded with protobuf (pb.Report in
+// node/rpc/pb) or CBOR (see Encodings).
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+)
+
+// descriptions documents the fields of the types in a Report.
+//
+// Keys are Type.Field.
+var descriptions = map[string]string{
+	"Report":            "A conjunction (or consistency or overflight) report.",
+	"Report.Schema":     "The version of this schema.",
+	"Report.Kind":       "Absent for a conjunction, \"consistency\" for a divergence between publishers' TLEs for one object, \"overflight\" for an object entering or exiting a region of interest, and \"what-if\" for a close approach between a hypothetical object (the first state) and a live object.",
+	"Report.Id":         "A logical identifier for this report.",
+	"Report.Sig":        "The signature for this report without consideration of Schema, Canceled, and Generated.  A cancellation has the same Sig as the report it cancels.",
+	"Report.Generated":  "The real time that this report was generated.",
+	"Report.At":         "The logical time of the event.",
+	"Report.Canceled":   "Indicates that this report cancels a previous report with the same Sig.",
+	"Report.Dist":       "The estimated distance between the two objects.",
+	"Report.Speed":      "The estimated relative speed of the two objects.",
+	"Report.Objs":       "The states of the two objects (or the one object for an overflight) at the time of the event.",
+	"Report.Overflight": "The region of interest and the event for an overflight.",
+	"Report.Tags":       "Labels added by report rules.",
+
+	"Overflight":       "An object entering or exiting a region of interest.",
+	"Overflight.ROI":   "The name of the region of interest.",
+	"Overflight.Event": "\"enter\" or \"exit\".",
+
+	"State":           "The state of an object at the time of a report.",
+	"State.Name":      "The object's name.",
+	"State.Obj":       "The TLE, which is absent in compact reports.",
+	"State.Digest":    "Identifies the TLE in compact reports.",
+	"State.Age":       "The age of the TLE.",
+	"State.Publisher": "The TLE's publisher.",
+	"State.Type":      "A crude classification of the object (for example, \"payload\" or \"debris\").",
+	"State.ECI":       "The ECI (TEME) position.",
+	"State.Vel":       "The ECI (TEME) velocity.",
+	"State.LLA":       "The geodetic position.",
+	"State.Light":     "The object's illumination by the Sun.",
+
+	"Lighting":          "An object's illumination by the Sun.",
+	"Lighting.Eclipse":  "\"sunlit\", \"penumbra\", or \"umbra\".",
+	"Lighting.Sunlight": "The visible fraction (0 to 1) of the Sun's disk.",
+	"Lighting.Beta":     "The solar beta angle: the angle between the orbital plane and the direction to the Sun.",
+	"Lighting.Phase":    "The angle at the object between the Sun and the Earth's center.",
+
+	"LatLonAlt":     "A geodetic position.",
+	"LatLonAlt.Lat": "Latitude.",
+	"LatLonAlt.Lon": "Longitude.",
+	"LatLonAlt.Alt": "Altitude.",
+
+	"SGP4TLE":         "A two-line element set.",
+	"SGP4TLE.CatNum":  "The catalog number.",
+	"SGP4TLE.TLE":     "The name line (line 0), line 1, and line 2.",
+	"SGP4TLE.Opts":    "SGP4 options, which are absent when the defaults were used.",
+	"Options.Gravity": "The SGP4 gravity model (\"wgs72old\", \"wgs72\", or \"wgs84\").",
+	"Options.OpsMode": "The SGP4 operation mode (\"afspc\" or \"improved\").",
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// object is a JSON Schema (sub)document.
+type object map[string]interface{}
+
+// JSONSchema generates the JSON Schema document for the JSON encoding
+// of a node.Report.
+//
+// Each quantity's "units" annotation gives its units.
+func JSONSchema() ([]byte, error) {
+	s, err := typeSchema(reflect.TypeOf(node.Report{}))
+	if err != nil {
+		return nil, err
+	}
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = fmt.Sprintf("spi report (schema %d)", node.ReportSchema)
+
+	props := s["properties"].(object)
+	props["Schema"].(object)["const"] = node.ReportSchema
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// typeSchema generates the schema for the given type, which must be
+// something that encoding/json can marshal.
+func typeSchema(t reflect.Type) (object, error) {
+	switch {
+	case t == timeType:
+		return object{"type": "string", "format": "date-time"}, nil
+	case t.Implements(textMarshalerType):
+		return object{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return object{"type": "string"}, nil
+	case reflect.Bool:
+		return object{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return object{"type": "array", "items": items}, nil
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+func structSchema(t reflect.Type) (object, error) {
+	var (
+		props    = object{}
+		required = []string{}
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // Unexported
+		}
+		name, opts := f.Name, ""
+		if tag, have := f.Tag.Lookup("json"); have {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if 1 < len(parts) {
+				opts = parts[1]
+			}
+		}
+
+		p, err := typeSchema(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %s", t.Name(), f.Name, err)
+		}
+		if d, have := descriptions[t.Name()+"."+f.Name]; have {
+			p["description"] = d
+		}
+		if units := f.Tag.Get("units"); units != "" {
+			setUnits(p, units)
+		}
+		props[name] = p
+
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	s := object{
+		"type":       "object",
+		"properties": props,
+		"required":   required,
+	}
+	if d, have := descriptions[t.Name()]; have {
+		s["description"] = d
+	}
+	return s, nil
+}
+
+// setUnits annotates the schema with the given units.  For an object
+// (such as a prop.Vect), the units apply to each property.
+func setUnits(s object, units string) {
+	if props, is := s["properties"].(object); is {
+		for _, p := range props {
+			setUnits(p.(object), units)
+		}
+		return
+	}
+	s["units"] = units
+}
@@ -0,0 +1,216 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/tle"
+)
+
+var update = flag.Bool("update", false, "update report.schema.json")
+
+const schemaFile = "report.schema.json"
+
+func testReport(t *testing.T) *node.Report {
+	p, err := tle.NewSGP4TLE("0 ISS (ZARYA)",
+		"1 25544U 98067A   20264.51782528 -.00002182  00000-0 -11606-4 0  2927",
+		"2 25544  51.6416 247.4627 0006703 130.5360 325.0288 15.72125391563537")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		at = time.Date(2020, 9, 20, 13, 0, 0, 0, time.UTC)
+		s0 = node.State{
+			Name:      "25544/test",
+			Obj:       p.(*tle.SGP4TLE),
+			Age:       2060,
+			Publisher: "test",
+			Type:      "payload",
+			ECI:       prop.Vect{X: 1, Y: 2, Z: 3},
+			Vel:       prop.Vect{X: 4, Y: 5.5, Z: -6},
+			LLA:       node.LatLonAlt{Lat: 10, Lon: 20, Alt: 400.125},
+			Light:     &node.Lighting{Eclipse: node.Penumbra, Sunlight: 0.5, Beta: -12.5, Phase: 90.25},
+		}
+		s1 = node.State{
+			Name:   "25544/compact",
+			Digest: "digest",
+			Type:   "payload",
+			ECI:    prop.Vect{X: 1.5, Y: 2, Z: 3},
+			Vel:    prop.Vect{X: 4, Y: 5, Z: 6},
+			LLA:    node.LatLonAlt{Lat: -10, Lon: 179.9, Alt: 401},
+		}
+	)
+
+	return &node.Report{
+		Schema:    node.ReportSchema,
+		Kind:      node.ConsistencyReport,
+		Id:        "id",
+		Sig:       "sig",
+		Generated: at.Add(-time.Minute),
+		At:        at,
+		Canceled:  true,
+		Dist:      1.5,
+		Speed:     0.1,
+		Objs:      []node.State{s0, s1},
+		Tags:      []string{"a", "b"},
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	got, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err := ioutil.WriteFile(schemaFile, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s is stale (run go test -update)", schemaFile)
+	}
+
+	var s struct {
+		Properties map[string]struct {
+			Units string `json:"units"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(got, &s); err != nil {
+		t.Fatal(err)
+	}
+	if u := s.Properties["Speed"].Units; u != "km/s" {
+		t.Fatalf("Speed units %q", u)
+	}
+}
+
+// TestRequired checks that a Report's JSON encoding has every
+// required property.
+func TestRequired(t *testing.T) {
+	js, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s interface{}
+	if err := json.Unmarshal(js, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	js, err = Marshal(JSON, testReport(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var x interface{}
+	if err := json.Unmarshal(js, &x); err != nil {
+		t.Fatal(err)
+	}
+
+	var check func(path string, s, x interface{})
+	check = func(path string, s, x interface{}) {
+		sm := s.(map[string]interface{})
+		switch sm["type"] {
+		case "object":
+			props := sm["properties"].(map[string]interface{})
+			xm := x.(map[string]interface{})
+			for _, name := range sm["required"].([]interface{}) {
+				if _, have := xm[name.(string)]; !have {
+					t.Fatalf("%s: missing %s", path, name)
+				}
+			}
+			for name, y := range xm {
+				p, have := props[name]
+				if !have {
+					t.Fatalf("%s: unexpected %s", path, name)
+				}
+				check(path+"."+name, p, y)
+			}
+		case "array":
+			for _, y := range x.([]interface{}) {
+				check(path+"[]", sm["items"], y)
+			}
+		}
+	}
+	check("Report", s, x)
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := testReport(t)
+
+	for _, encoding := range Encodings {
+		t.Run(encoding, func(t *testing.T) {
+			bs, err := Marshal(encoding, want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Unmarshal(encoding, bs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got\n%#v\nwant\n%#v", got, want)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	var (
+		r0 = testReport(t)
+		r1 = testReport(t)
+	)
+	r1.Id = "other"
+	r1.Objs[0].Obj = nil
+
+	for _, encoding := range Encodings {
+		t.Run(encoding, func(t *testing.T) {
+			var buf bytes.Buffer
+			e, err := NewEncoder(&buf, encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range []*node.Report{r0, r1} {
+				if err := e.Encode(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			d, err := NewDecoder(&buf, encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []*node.Report{r0, r1} {
+				got, err := d.Decode()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("got\n%#v\nwant\n%#v", got, want)
+				}
+			}
+			if _, err := d.Decode(); err != io.EOF {
+				t.Fatalf("got %v instead of EOF", err)
+			}
+		})
+	}
+}
+
+func TestUnknownEncoding(t *testing.T) {
+	if _, err := NewEncoder(ioutil.Discard, "xml"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,109 @@
+package sgp4
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Batch propagates a set of TLEs at a set of times.
+//
+// Results use a structure-of-arrays layout: X, Y, Z, VX, VY, VZ, and
+// Errs each have one entry per (TLE, time) pair, and the entry for
+// TLEs[i] at Millis[j] is at index i*len(Millis)+j.
+//
+// Propagation iterates over times for one TLE before moving to the
+// next TLE, so each TLE's (large) ElsetRec stays hot in the cache
+// and its lock is acquired only once per batch.
+type Batch struct {
+	// TLEs are the TLEs to propagate.
+	TLEs []*TLE
+
+	// Millis are the times (in Unix milliseconds) for propagation.
+	Millis []int64
+
+	// X, Y, Z are the position components (km).
+	X, Y, Z []float64
+
+	// VX, VY, VZ are the velocity components (km/s).
+	VX, VY, VZ []float64
+
+	// Errs has a non-nil entry for each failed propagation.
+	Errs []error
+
+	// Workers is the number of goroutines to use.  Values less
+	// than two result in propagation in the calling goroutine.
+	Workers int
+}
+
+// NewBatch allocates a Batch for the given TLEs and times.
+func NewBatch(tles []*TLE, millis []int64) *Batch {
+	n := len(tles) * len(millis)
+	return &Batch{
+		TLEs:   tles,
+		Millis: millis,
+		X:      make([]float64, n),
+		Y:      make([]float64, n),
+		Z:      make([]float64, n),
+		VX:     make([]float64, n),
+		VY:     make([]float64, n),
+		VZ:     make([]float64, n),
+		Errs:   make([]error, n),
+	}
+}
+
+// Index returns the position in the result arrays for TLEs[i] at
+// Millis[j].
+func (b *Batch) Index(i, j int) int {
+	return i*len(b.Millis) + j
+}
+
+// Prop performs all of the propagations.
+func (b *Batch) Prop() {
+	workers := b.Workers
+	if len(b.TLEs) < workers {
+		workers = len(b.TLEs)
+	}
+	if workers < 2 {
+		b.propRange(0, len(b.TLEs))
+		return
+	}
+
+	var (
+		wg    = sync.WaitGroup{}
+		chunk = (len(b.TLEs) + workers - 1) / workers
+	)
+	for i0 := 0; i0 < len(b.TLEs); i0 += chunk {
+		i1 := i0 + chunk
+		if len(b.TLEs) < i1 {
+			i1 = len(b.TLEs)
+		}
+		wg.Add(1)
+		go func(i0, i1 int) {
+			b.propRange(i0, i1)
+			wg.Done()
+		}(i0, i1)
+	}
+	wg.Wait()
+}
+
+// propRange propagates TLEs[i0:i1] at all times.
+func (b *Batch) propRange(i0, i1 int) {
+	var r, v [3]float64
+	for i := i0; i < i1; i++ {
+		tle := b.TLEs[i]
+		tle.Lock()
+		for j, ms := range b.Millis {
+			k := b.Index(i, j)
+			tle.Rec.error = 0
+			getRVForDate(tle, ms, &r[0], &v[0])
+			if e := tle.sgp4Error; e != 0 {
+				tle.sgp4Error = 0
+				b.Errs[k] = fmt.Errorf("SGP4 error at ms=%d: %w", ms, Error(e))
+				continue
+			}
+			b.X[k], b.Y[k], b.Z[k] = r[0], r[1], r[2]
+			b.VX[k], b.VY[k], b.VZ[k] = v[0], v[1], v[2]
+		}
+		tle.Unlock()
+	}
+}
@@ -0,0 +1,88 @@
+package sgp4
+
+import (
+	"math"
+	"time"
+)
+
+// Accessors for the mean elements parsed from the TLE lines and a few
+// values derived by sgp4init.
+//
+// Angles are in degrees, distances in kilometers.
+
+// ObjectNum returns the catalog number.
+func (tle *TLE) ObjectNum() int64 {
+	return tle.objectNum
+}
+
+// Inclination returns the inclination (deg).
+func (tle *TLE) Inclination() float64 {
+	return tle.incDeg
+}
+
+// RAAN returns the right ascension of the ascending node (deg).
+func (tle *TLE) RAAN() float64 {
+	return tle.raanDeg
+}
+
+// Eccentricity returns the eccentricity.
+func (tle *TLE) Eccentricity() float64 {
+	return tle.ecc
+}
+
+// ArgPerigee returns the argument of perigee (deg).
+func (tle *TLE) ArgPerigee() float64 {
+	return tle.argpDeg
+}
+
+// MeanAnomaly returns the mean anomaly (deg).
+func (tle *TLE) MeanAnomaly() float64 {
+	return tle.maDeg
+}
+
+// MeanMotion returns the (Kozai) mean motion (revs/day).
+func (tle *TLE) MeanMotion() float64 {
+	return tle.n
+}
+
+// BStar returns the B* drag term (1/earth radii).
+func (tle *TLE) BStar() float64 {
+	return tle.bstar
+}
+
+// EpochMillis returns the epoch in Unix milliseconds.
+func (tle *TLE) EpochMillis() int64 {
+	return tle.epoch
+}
+
+// RadiusEarth returns the equatorial radius (km) of the gravity
+// model used by this TLE.
+func (tle *TLE) RadiusEarth() float64 {
+	return tle.Rec.radiusearthkm
+}
+
+// SemiMajorAxis returns the semi-major axis (km) derived from the
+// (un-Kozai'd) mean motion.
+func (tle *TLE) SemiMajorAxis() float64 {
+	return tle.Rec.a * tle.Rec.radiusearthkm
+}
+
+// PerigeeAlt returns the perigee altitude (km) above the gravity
+// model's equatorial radius.
+func (tle *TLE) PerigeeAlt() float64 {
+	return tle.Rec.altp * tle.Rec.radiusearthkm
+}
+
+// ApogeeAlt returns the apogee altitude (km) above the gravity
+// model's equatorial radius.
+func (tle *TLE) ApogeeAlt() float64 {
+	return tle.Rec.alta * tle.Rec.radiusearthkm
+}
+
+// Period returns the orbital period based on the (un-Kozai'd) mean
+// motion.
+func (tle *TLE) Period() time.Duration {
+	// no_unkozai is in radians per minute.
+	mins := 2 * math.Pi / tle.Rec.no_unkozai
+	return time.Duration(mins * float64(time.Minute))
+}
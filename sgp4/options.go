@@ -0,0 +1,147 @@
+package sgp4
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gravity selects a set of gravitational constants (see
+// getgravconst).
+type Gravity int64
+
+const (
+	// WGS72Old is the low-precision WGS-72 set from Spacetrack
+	// Report #3.
+	WGS72Old Gravity = 1
+
+	// WGS72 is the standard WGS-72 set.
+	WGS72 Gravity = 2
+
+	// WGS84 is the WGS-84 set.
+	WGS84 Gravity = 3
+)
+
+var gravityNames = map[Gravity]string{
+	WGS72Old: "wgs72old",
+	WGS72:    "wgs72",
+	WGS84:    "wgs84",
+}
+
+func (g Gravity) String() string {
+	if s, have := gravityNames[g]; have {
+		return s
+	}
+	return fmt.Sprintf("Gravity(%d)", int64(g))
+}
+
+// ParseGravity parses a Gravity name ("wgs72old", "wgs72", or
+// "wgs84").
+func ParseGravity(s string) (Gravity, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for g, name := range gravityNames {
+		if s == name {
+			return g, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown gravity model '%s'", s)
+}
+
+func (g Gravity) MarshalText() ([]byte, error) {
+	if _, have := gravityNames[g]; !have {
+		return nil, fmt.Errorf("unknown gravity model %d", int64(g))
+	}
+	return []byte(g.String()), nil
+}
+
+func (g *Gravity) UnmarshalText(bs []byte) error {
+	x, err := ParseGravity(string(bs))
+	if err != nil {
+		return err
+	}
+	*g = x
+	return nil
+}
+
+// OpsMode is the SGP4 operation mode.
+type OpsMode byte
+
+const (
+	// AFSPC mode matches the original AFSPC code.
+	AFSPC OpsMode = 'a'
+
+	// Improved mode uses Vallado's improved operations.
+	Improved OpsMode = 'i'
+)
+
+var opsModeNames = map[OpsMode]string{
+	AFSPC:    "afspc",
+	Improved: "improved",
+}
+
+func (m OpsMode) String() string {
+	if s, have := opsModeNames[m]; have {
+		return s
+	}
+	return fmt.Sprintf("OpsMode(%q)", byte(m))
+}
+
+// ParseOpsMode parses an OpsMode: "a" or "afspc" for AFSPC and "i"
+// or "improved" for Improved.
+func ParseOpsMode(s string) (OpsMode, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for m, name := range opsModeNames {
+		if s == name || s == string(byte(m)) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown opsmode '%s'", s)
+}
+
+func (m OpsMode) MarshalText() ([]byte, error) {
+	if _, have := opsModeNames[m]; !have {
+		return nil, fmt.Errorf("unknown opsmode %q", byte(m))
+	}
+	return []byte(m.String()), nil
+}
+
+func (m *OpsMode) UnmarshalText(bs []byte) error {
+	x, err := ParseOpsMode(string(bs))
+	if err != nil {
+		return err
+	}
+	*m = x
+	return nil
+}
+
+// Options control how a TLE is initialized for propagation.
+type Options struct {
+	// Gravity selects the gravitational constants.
+	Gravity Gravity
+
+	// OpsMode is the operation mode.
+	OpsMode OpsMode
+}
+
+// DefaultOptions are used by ParseLines.
+//
+// These values (WGS-72 and AFSPC) were previously hard-coded.
+var DefaultOptions = Options{
+	Gravity: WGS72,
+	OpsMode: AFSPC,
+}
+
+// Check returns an error if the Options are not valid.
+func (o Options) Check() error {
+	if _, have := gravityNames[o.Gravity]; !have {
+		return fmt.Errorf("unknown gravity model %d", int64(o.Gravity))
+	}
+	if _, have := opsModeNames[o.OpsMode]; !have {
+		return fmt.Errorf("unknown opsmode %q", byte(o.OpsMode))
+	}
+	return nil
+}
+
+// Options returns the Options used to parse this TLE.
+func (tle *TLE) Options() Options {
+	return tle.opts
+}
@@ -318,6 +318,8 @@ type ElsetRec struct {
 type TLE struct {
 	sync.Mutex
 
+	opts Options
+
 	Rec       ElsetRec
 	line1     [70]byte
 	line2     [70]byte
@@ -349,7 +351,7 @@ type TLE struct {
 // intlid
 //
 func parseLines(tle *TLE, line1 *byte, line2 *byte) {
-	(*tle).Rec.whichconst = int64(2)
+	(*tle).Rec.whichconst = int64((*tle).opts.Gravity)
 	Strncpy64(&(*tle).line1[0], line1, int64(uint64(int64(69))))
 	Strncpy64(&(*tle).line2[0], line2, int64(uint64(int64(69))))
 	*((*byte)(func() unsafe.Pointer {
@@ -585,7 +587,7 @@ func setValsToRec(tle *TLE, rec *ElsetRec) {
 	(*rec).no_kozai = (*tle).n / xpdotp
 	(*rec).ndot = (*tle).ndot / (xpdotp * 1440.0)
 	(*rec).nddot = (*tle).nddot / (xpdotp * 1440.0 * 1440.0)
-	sgp4init('a', rec)
+	sgp4init(byte((*tle).opts.OpsMode), rec)
 }
 
 // dpper - transpiled function from  /home/somebody/aholinch/sgp4/src/c/all.c:348
@@ -2725,8 +2727,19 @@ func (tle *TLE) PropForMins(mins float64) ([]float64, []float64, error) {
 	return r, v, nil
 }
 
+// ParseLines parses a TLE using DefaultOptions.
 func ParseLines(line1, line2 string) (*TLE, error) {
-	tle := &TLE{}
+	return ParseLinesWith(line1, line2, DefaultOptions)
+}
+
+// ParseLinesWith parses a TLE using the given Options.
+func ParseLinesWith(line1, line2 string, opts Options) (*TLE, error) {
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+	tle := &TLE{
+		opts: opts,
+	}
 	bs1 := []byte(line1)
 	bs2 := []byte(line2)
 	parseLines(tle, (*byte)(&bs1[0]), (*byte)(&bs2[0]))
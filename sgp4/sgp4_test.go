@@ -1,6 +1,7 @@
 package sgp4
 
 import (
+	"encoding/json"
 	"log"
 	"testing"
 	"time"
@@ -72,3 +73,160 @@ func TestError1(t *testing.T) {
 		}
 	}
 }
+
+func TestBatch(t *testing.T) {
+	var (
+		lines = [][2]string{
+			{
+				"1 39132U PLANET   20016.08334491  .00000000  00000+0 -47542-3 0    07",
+				"2 39132 064.8760 163.6520 0036285 284.0373 175.5769 15.07452065    00",
+			},
+			{
+				"1 44246U 19029M   19348.91667824  .00396868  00000-0  14121-2 0  9990",
+				"2 44246  52.9936 253.6898 0006659 333.1525 350.4837 15.87356006 31853",
+			},
+		}
+		tles   = make([]*TLE, 0, len(lines))
+		millis = make([]int64, 0, 16)
+		t0     = time.Date(2020, 1, 16, 0, 0, 0, 0, time.UTC)
+	)
+
+	for _, ls := range lines {
+		tle, err := ParseLines(ls[0], ls[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		tles = append(tles, tle)
+	}
+
+	for i := 0; i < 16; i++ {
+		ms := t0.Add(time.Duration(i)*time.Hour).UnixNano() / 1000 / 1000
+		millis = append(millis, ms)
+	}
+
+	for _, workers := range []int{0, 1, 2, 8} {
+		b := NewBatch(tles, millis)
+		b.Workers = workers
+		b.Prop()
+
+		for i, tle := range tles {
+			for j, ms := range millis {
+				k := b.Index(i, j)
+				r, v, err := tle.PropUnixMillis(ms)
+				if (err == nil) != (b.Errs[k] == nil) {
+					t.Fatalf("workers=%d %d,%d: %v vs %v", workers, i, j, err, b.Errs[k])
+				}
+				if err != nil {
+					continue
+				}
+				if r[0] != b.X[k] || r[1] != b.Y[k] || r[2] != b.Z[k] {
+					t.Fatalf("workers=%d %d,%d: position %v", workers, i, j, r)
+				}
+				if v[0] != b.VX[k] || v[1] != b.VY[k] || v[2] != b.VZ[k] {
+					t.Fatalf("workers=%d %d,%d: velocity %v", workers, i, j, v)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkBatch(b *testing.B) {
+	var (
+		line1  = "1 39132U PLANET   20016.08334491  .00000000  00000+0 -47542-3 0    07"
+		line2  = "2 39132 064.8760 163.6520 0036285 284.0373 175.5769 15.07452065    00"
+		tles   = make([]*TLE, 1024)
+		millis = []int64{1579132800000}
+	)
+
+	for i := range tles {
+		tle, err := ParseLines(line1, line2)
+		if err != nil {
+			b.Fatal(err)
+		}
+		tles[i] = tle
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		batch := NewBatch(tles, millis)
+		batch.Workers = 4
+		batch.Prop()
+	}
+}
+
+func TestOptions(t *testing.T) {
+	var (
+		line1 = "1 39132U PLANET   20016.08334491  .00000000  00000+0 -47542-3 0    07"
+		line2 = "2 39132 064.8760 163.6520 0036285 284.0373 175.5769 15.07452065    00"
+		mins  = float64(60)
+	)
+
+	def, err := ParseLines(line1, line2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Options() != DefaultOptions {
+		t.Fatal(def.Options())
+	}
+
+	wgs72, err := ParseLinesWith(line1, line2, Options{Gravity: WGS72, OpsMode: AFSPC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wgs84, err := ParseLinesWith(line1, line2, Options{Gravity: WGS84, OpsMode: AFSPC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r0, _, err := def.PropForMins(mins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1, _, err := wgs72.PropForMins(mins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, _, err := wgs84.PropForMins(mins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r0[0] != r1[0] || r0[1] != r1[1] || r0[2] != r1[2] {
+		t.Fatalf("default %v != wgs72 %v", r0, r1)
+	}
+	if r0[0] == r2[0] && r0[1] == r2[1] && r0[2] == r2[2] {
+		t.Fatalf("default %v == wgs84 %v", r0, r2)
+	}
+	if wgs84.RadiusEarth() != 6378.137 {
+		t.Fatal(wgs84.RadiusEarth())
+	}
+
+	if _, err = ParseLinesWith(line1, line2, Options{Gravity: 7, OpsMode: AFSPC}); err == nil {
+		t.Fatal("should have complained about gravity")
+	}
+	if _, err = ParseLinesWith(line1, line2, Options{Gravity: WGS84, OpsMode: 'x'}); err == nil {
+		t.Fatal("should have complained about opsmode")
+	}
+
+	var opts Options
+	js := `{"Gravity":"wgs72old","OpsMode":"improved"}`
+	if err = json.Unmarshal([]byte(js), &opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Gravity != WGS72Old || opts.OpsMode != Improved {
+		t.Fatal(opts)
+	}
+	bs, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != js {
+		t.Fatal(string(bs))
+	}
+
+	if m, err := ParseOpsMode("a"); err != nil || m != AFSPC {
+		t.Fatal(m, err)
+	}
+}
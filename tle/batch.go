@@ -0,0 +1,71 @@
+package tle
+
+import (
+	"time"
+
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/sgp4"
+)
+
+// Batch is a prop.BatchPropagator that uses sgp4.Batch for SGP4TLEs.
+//
+// Propagators that aren't SGP4TLEs are propagated individually.
+type Batch struct {
+	// Workers is the number of goroutines for sgp4.Batch.
+	Workers int
+}
+
+func (b *Batch) PropBatch(ps []prop.Propagator, ts []time.Time) *prop.Matrix {
+	var (
+		m      = prop.NewMatrix(len(ps), len(ts))
+		tles   = make([]*sgp4.TLE, 0, len(ps))
+		rows   = make([]int, 0, len(ps))
+		millis = make([]int64, len(ts))
+	)
+
+	for j, t := range ts {
+		millis[j] = t.UnixNano() / 1000 / 1000
+	}
+
+	for i, p := range ps {
+		o, is := p.(*SGP4TLE)
+		if !is {
+			for j, t := range ts {
+				e, err := p.Prop(t)
+				m.Set(i, j, e, err)
+			}
+			continue
+		}
+		tles = append(tles, o.tle)
+		rows = append(rows, i)
+	}
+
+	sb := sgp4.NewBatch(tles, millis)
+	sb.Workers = b.Workers
+	sb.Prop()
+
+	for k, i := range rows {
+		for j := range ts {
+			x := sb.Index(k, j)
+			if err := sb.Errs[x]; err != nil {
+				m.Set(i, j, prop.Ephemeris{}, err)
+				continue
+			}
+			e := prop.Ephemeris{
+				ECI: prop.Vect{
+					X: float32(sb.X[x]),
+					Y: float32(sb.Y[x]),
+					Z: float32(sb.Z[x]),
+				},
+				V: prop.Vect{
+					X: float32(sb.VX[x]),
+					Y: float32(sb.VY[x]),
+					Z: float32(sb.VZ[x]),
+				},
+			}
+			m.Set(i, j, e, nil)
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,128 @@
+package tle
+
+import (
+	"time"
+)
+
+// Elements are the mean elements of an SGP4TLE along with some
+// derived values.
+//
+// Angles are in degrees, and distances are in kilometers.
+type Elements struct {
+	// Epoch is the TLE's epoch.
+	Epoch time.Time
+
+	// Inclination (deg).
+	Inclination float64
+
+	// RAAN is the right ascension of the ascending node (deg).
+	RAAN float64
+
+	// Eccentricity is dimensionless.
+	Eccentricity float64
+
+	// ArgPerigee is the argument of perigee (deg).
+	ArgPerigee float64
+
+	// MeanAnomaly (deg).
+	MeanAnomaly float64
+
+	// MeanMotion (revs/day).
+	MeanMotion float64
+
+	// BStar is the B* drag term (1/earth radii).
+	BStar float64
+
+	// SemiMajorAxis (km).
+	SemiMajorAxis float64
+
+	// PerigeeAlt is the perigee altitude (km).
+	PerigeeAlt float64
+
+	// ApogeeAlt is the apogee altitude (km).
+	ApogeeAlt float64
+
+	// Period is the orbital period in seconds.
+	Period float64
+}
+
+// Epoch returns the epoch of the TLE as parsed by SGP4.
+//
+// Also see ApproxEpoch.
+func (o *SGP4TLE) Epoch() time.Time {
+	ms := o.tle.EpochMillis()
+	return time.Unix(0, ms*1000*1000).UTC()
+}
+
+// Inclination returns the inclination (deg).
+func (o *SGP4TLE) Inclination() float64 {
+	return o.tle.Inclination()
+}
+
+// RAAN returns the right ascension of the ascending node (deg).
+func (o *SGP4TLE) RAAN() float64 {
+	return o.tle.RAAN()
+}
+
+// Eccentricity returns the eccentricity.
+func (o *SGP4TLE) Eccentricity() float64 {
+	return o.tle.Eccentricity()
+}
+
+// ArgPerigee returns the argument of perigee (deg).
+func (o *SGP4TLE) ArgPerigee() float64 {
+	return o.tle.ArgPerigee()
+}
+
+// MeanAnomaly returns the mean anomaly (deg).
+func (o *SGP4TLE) MeanAnomaly() float64 {
+	return o.tle.MeanAnomaly()
+}
+
+// MeanMotion returns the mean motion (revs/day).
+func (o *SGP4TLE) MeanMotion() float64 {
+	return o.tle.MeanMotion()
+}
+
+// BStar returns the B* drag term.
+func (o *SGP4TLE) BStar() float64 {
+	return o.tle.BStar()
+}
+
+// SemiMajorAxis returns the semi-major axis (km).
+func (o *SGP4TLE) SemiMajorAxis() float64 {
+	return o.tle.SemiMajorAxis()
+}
+
+// PerigeeAlt returns the perigee altitude (km).
+func (o *SGP4TLE) PerigeeAlt() float64 {
+	return o.tle.PerigeeAlt()
+}
+
+// ApogeeAlt returns the apogee altitude (km).
+func (o *SGP4TLE) ApogeeAlt() float64 {
+	return o.tle.ApogeeAlt()
+}
+
+// Period returns the orbital period.
+func (o *SGP4TLE) Period() time.Duration {
+	return o.tle.Period()
+}
+
+// Elements gathers all of the elements.
+func (o *SGP4TLE) Elements() *Elements {
+	return &Elements{
+		Epoch:         o.Epoch(),
+		Inclination:   o.Inclination(),
+		RAAN:          o.RAAN(),
+		Eccentricity:  o.Eccentricity(),
+		ArgPerigee:    o.ArgPerigee(),
+		MeanAnomaly:   o.MeanAnomaly(),
+		MeanMotion:    o.MeanMotion(),
+		BStar:         o.BStar(),
+		SemiMajorAxis: o.SemiMajorAxis(),
+		PerigeeAlt:    o.PerigeeAlt(),
+		ApogeeAlt:     o.ApogeeAlt(),
+		Period:        o.Period().Seconds(),
+	}
+}
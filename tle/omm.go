@@ -0,0 +1,183 @@
+package tle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// OMM is the subset of a CCSDS Orbit Mean-Elements Message required
+// to construct a TLE.
+//
+// The JSON field names match those used by CelesTrak and Space-Track.
+type OMM struct {
+	ObjectName string `json:"OBJECT_NAME"`
+
+	// ObjectID is the international designator (example:
+	// "1998-067A").
+	ObjectID string `json:"OBJECT_ID"`
+
+	// Epoch is in UTC (example: "2020-09-20T12:25:40.104192").
+	Epoch string `json:"EPOCH"`
+
+	// MeanMotion (revs/day).
+	MeanMotion float64 `json:"MEAN_MOTION"`
+
+	Eccentricity float64 `json:"ECCENTRICITY"`
+
+	// Inclination (deg).
+	Inclination float64 `json:"INCLINATION"`
+
+	// RAOfAscNode (deg).
+	RAOfAscNode float64 `json:"RA_OF_ASC_NODE"`
+
+	// ArgOfPericenter (deg).
+	ArgOfPericenter float64 `json:"ARG_OF_PERICENTER"`
+
+	// MeanAnomaly (deg).
+	MeanAnomaly float64 `json:"MEAN_ANOMALY"`
+
+	EphemerisType      int    `json:"EPHEMERIS_TYPE"`
+	ClassificationType string `json:"CLASSIFICATION_TYPE"`
+	NoradCatID         int    `json:"NORAD_CAT_ID"`
+	ElementSetNo       int    `json:"ELEMENT_SET_NO"`
+	RevAtEpoch         int    `json:"REV_AT_EPOCH"`
+
+	// BStar (1/earth radii).
+	BStar float64 `json:"BSTAR"`
+
+	// MeanMotionDot (revs/day^2) is the first derivative of mean
+	// motion divided by two as in a TLE.
+	MeanMotionDot float64 `json:"MEAN_MOTION_DOT"`
+
+	// MeanMotionDDot (revs/day^3) is the second derivative of mean
+	// motion divided by six as in a TLE.
+	MeanMotionDDot float64 `json:"MEAN_MOTION_DDOT"`
+}
+
+// ommEpochLayouts are the accepted formats for OMM.Epoch.
+var ommEpochLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+// ParseEpoch parses the OMM's Epoch.
+func (o *OMM) ParseEpoch() (time.Time, error) {
+	for _, layout := range ommEpochLayouts {
+		if t, err := time.Parse(layout, o.Epoch); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad OMM epoch '%s'", o.Epoch)
+}
+
+// Lines renders the OMM as the three lines of a TLE.
+func (o *OMM) Lines() (string, string, string, error) {
+	t, err := o.ParseEpoch()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if o.NoradCatID < 0 || 99999 < o.NoradCatID {
+		return "", "", "", fmt.Errorf("NORAD_CAT_ID %d doesn't fit in a TLE", o.NoradCatID)
+	}
+
+	class := "U"
+	if o.ClassificationType != "" {
+		class = o.ClassificationType[0:1]
+	}
+
+	var (
+		y0  = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		doy = 1 + t.Sub(y0).Hours()/24
+
+		line1 = fmt.Sprintf("1 %05d%s %-8s %02d%012.8f %s %s %s %d %4d",
+			o.NoradCatID, class, intlDesignator(o.ObjectID),
+			t.Year()%100, doy,
+			tleDecimal(o.MeanMotionDot), tleExp(o.MeanMotionDDot), tleExp(o.BStar),
+			o.EphemerisType, o.ElementSetNo%10000)
+
+		line2 = fmt.Sprintf("2 %05d %8.4f %8.4f %07d %8.4f %8.4f %11.8f%5d",
+			o.NoradCatID, o.Inclination, o.RAOfAscNode,
+			int(math.Round(o.Eccentricity*1e7)),
+			o.ArgOfPericenter, o.MeanAnomaly, o.MeanMotion,
+			o.RevAtEpoch%100000)
+	)
+
+	return o.ObjectName, line1 + checksum(line1), line2 + checksum(line2), nil
+}
+
+// NewSGP4TLEFromOMM makes an SGP4TLE from an OMM.
+func NewSGP4TLEFromOMM(o *OMM) (*SGP4TLE, error) {
+	line0, line1, line2, err := o.Lines()
+	if err != nil {
+		return nil, err
+	}
+	return newSGP4TLE(line0, line1, line2, nil)
+}
+
+// intlDesignator converts "1998-067A" to "98067A".
+func intlDesignator(id string) string {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 || len(parts[0]) != 4 {
+		return ""
+	}
+	return parts[0][2:] + parts[1]
+}
+
+// tleDecimal renders x as " .00002182" or "-.00002182".
+func tleDecimal(x float64) string {
+	sign := " "
+	if x < 0 {
+		sign = "-"
+	}
+	s := fmt.Sprintf("%.8f", math.Abs(x))
+	return sign + strings.TrimPrefix(s, "0")
+}
+
+// tleExp renders x in the TLE's implied-decimal exponential format:
+// -0.11606e-4 is "-11606-4".
+func tleExp(x float64) string {
+	if x == 0 {
+		return " 00000-0"
+	}
+
+	sign := " "
+	if x < 0 {
+		sign = "-"
+	}
+
+	var (
+		a = math.Abs(x)
+		e = int(math.Floor(math.Log10(a))) + 1
+		m = int(math.Round(a / math.Pow(10, float64(e)) * 1e5))
+	)
+
+	if m == 100000 {
+		m = 10000
+		e++
+	}
+
+	esign := "+"
+	if e < 0 {
+		esign = "-"
+		e = -e
+	}
+
+	return fmt.Sprintf("%s%05d%s%d", sign, m, esign, e)
+}
+
+// checksum computes a TLE line's checksum digit.
+func checksum(line string) string {
+	sum := 0
+	for _, c := range line {
+		switch {
+		case '0' <= c && c <= '9':
+			sum += int(c - '0')
+		case c == '-':
+			sum++
+		}
+	}
+	return fmt.Sprintf("%d", sum%10)
+}
@@ -2,6 +2,7 @@ package tle
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -15,13 +16,37 @@ type SGP4TLE struct {
 
 	TLE []string
 
+	// Opts, if not nil, gives the SGP4 options used for this TLE.
+	// Otherwise sgp4.DefaultOptions were used.
+	Opts *sgp4.Options `json:",omitempty"`
+
 	// ToDo: Deleted flag?
 
 	tle *sgp4.TLE
 }
 
 func NewSGP4TLE(line0, line1, line2 string) (prop.Propagator, error) {
-	o, err := sgp4.ParseLines(line1, line2)
+	o, err := newSGP4TLE(line0, line1, line2, nil)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// NewSGP4TLEWith returns a parser (for DoTLEs) that uses the given
+// SGP4 options.
+func NewSGP4TLEWith(opts sgp4.Options) func(line0, line1, line2 string) (prop.Propagator, error) {
+	return func(line0, line1, line2 string) (prop.Propagator, error) {
+		o, err := newSGP4TLE(line0, line1, line2, &opts)
+		if err != nil {
+			return nil, err
+		}
+		return o, nil
+	}
+}
+
+func newSGP4TLE(line0, line1, line2 string, opts *sgp4.Options) (*SGP4TLE, error) {
+	o, err := parseLines(line1, line2, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -33,10 +58,29 @@ func NewSGP4TLE(line0, line1, line2 string) (prop.Propagator, error) {
 	return &SGP4TLE{
 		CatNum: cat,
 		TLE:    []string{line0, line1, line2},
+		Opts:   opts,
 		tle:    o,
 	}, nil
 }
 
+func parseLines(line1, line2 string, opts *sgp4.Options) (*sgp4.TLE, error) {
+	if opts == nil {
+		return sgp4.ParseLines(line1, line2)
+	}
+	return sgp4.ParseLinesWith(line1, line2, *opts)
+}
+
+// WithOptions returns a new SGP4TLE for the same TLE using the given
+// SGP4 options.
+func (o *SGP4TLE) WithOptions(opts sgp4.Options) (*SGP4TLE, error) {
+	return newSGP4TLE(o.TLE[0], o.TLE[1], o.TLE[2], &opts)
+}
+
+// Options returns the SGP4 options used for this TLE.
+func (o *SGP4TLE) Options() sgp4.Options {
+	return o.tle.Options()
+}
+
 // Epoch attempts to determine the approximate epoch.  Returns zero on
 // failure.
 //
@@ -126,7 +170,10 @@ func ParseSGP4TLE(js string) (*SGP4TLE, error) {
 	if err := json.Unmarshal([]byte(js), &o); err != nil {
 		return nil, err
 	}
-	tle, err := sgp4.ParseLines(o.TLE[1], o.TLE[2])
+	if len(o.TLE) != 3 {
+		return nil, fmt.Errorf("TLE has %d lines (not 3)", len(o.TLE))
+	}
+	tle, err := parseLines(o.TLE[1], o.TLE[2], o.Opts)
 	if err != nil {
 		return nil, err
 	}
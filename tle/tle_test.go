@@ -2,9 +2,11 @@ package tle
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -12,6 +14,7 @@ import (
 	"time"
 
 	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/sgp4"
 )
 
 func TestDoTLEsBasic(t *testing.T) {
@@ -156,3 +159,141 @@ func TestDoTLEsConcurrent(t *testing.T) {
 	}
 
 }
+
+func TestElements(t *testing.T) {
+	p, err := NewSGP4TLE("0 ISS (ZARYA)",
+		"1 25544U 98067A   20264.51782528 -.00002182  00000-0 -11606-4 0  2927",
+		"2 25544  51.6416 247.4627 0006703 130.5360 325.0288 15.72125391563537")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		o  = p.(*SGP4TLE)
+		es = o.Elements()
+
+		near = func(what string, x, want, tol float64) {
+			if math.Abs(x-want) > tol {
+				t.Fatalf("%s: %f not near %f", what, x, want)
+			}
+		}
+	)
+
+	near("Inclination", es.Inclination, 51.6416, 1e-9)
+	near("RAAN", es.RAAN, 247.4627, 1e-9)
+	near("Eccentricity", es.Eccentricity, 0.0006703, 1e-12)
+	near("ArgPerigee", es.ArgPerigee, 130.5360, 1e-9)
+	near("MeanAnomaly", es.MeanAnomaly, 325.0288, 1e-9)
+	near("MeanMotion", es.MeanMotion, 15.72125391, 1e-8)
+	near("BStar", es.BStar, -0.11606e-4, 1e-12)
+	near("SemiMajorAxis", es.SemiMajorAxis, 6731, 5)
+	near("PerigeeAlt", es.PerigeeAlt, 349, 5)
+	near("ApogeeAlt", es.ApogeeAlt, 358, 5)
+	near("Period", es.Period, 5496, 5)
+
+	if es.PerigeeAlt > es.ApogeeAlt {
+		t.Fatalf("perigee %f > apogee %f", es.PerigeeAlt, es.ApogeeAlt)
+	}
+
+	if d := es.Epoch.Sub(o.ApproxEpoch()); time.Second < d || d < -time.Second {
+		t.Fatalf("epoch %v vs approx %v", es.Epoch, o.ApproxEpoch())
+	}
+}
+
+func TestOptions(t *testing.T) {
+	var (
+		line0 = "0 DOVE 2 0505"
+		line1 = "1 39132U PLANET   20053.08335648  .00000000  00000+0  26885-3 0    09"
+		line2 = "2 39132 064.8781 046.1432 0037470 277.8993 081.9081 15.07480396    00"
+		opts  = sgp4.Options{
+			Gravity: sgp4.WGS84,
+			OpsMode: sgp4.Improved,
+		}
+	)
+
+	p, err := NewSGP4TLEWith(opts)(line0, line1, line2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := p.(*SGP4TLE)
+	if o.Options() != opts {
+		t.Fatal(o.Options())
+	}
+
+	js, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o1, err := ParseSGP4TLE(string(js))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o1.Options() != opts {
+		t.Fatalf("%s: %v", js, o1.Options())
+	}
+
+	p, err = NewSGP4TLE(line0, line1, line2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o2, err := p.(*SGP4TLE).WithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o2.Options() != opts || p.(*SGP4TLE).Options() != sgp4.DefaultOptions {
+		t.Fatal(o2.Options())
+	}
+
+	// An error returns a nil Propagator (not a typed nil).
+	bad := sgp4.Options{Gravity: 99, OpsMode: sgp4.Improved}
+	if p, err := NewSGP4TLEWith(bad)(line0, line1, line2); err == nil || p != nil {
+		t.Fatalf("%#v, %v", p, err)
+	}
+}
+
+func TestOMM(t *testing.T) {
+	o := &OMM{
+		ObjectName:      "0 ISS (ZARYA)",
+		ObjectID:        "1998-067A",
+		Epoch:           "2020-09-20T12:25:40.104192",
+		MeanMotion:      15.72125391,
+		Eccentricity:    0.0006703,
+		Inclination:     51.6416,
+		RAOfAscNode:     247.4627,
+		ArgOfPericenter: 130.5360,
+		MeanAnomaly:     325.0288,
+		NoradCatID:      25544,
+		ElementSetNo:    292,
+		RevAtEpoch:      56353,
+		BStar:           -0.11606e-4,
+		MeanMotionDot:   -0.00002182,
+	}
+
+	line0, line1, line2, err := o.Lines()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		// The TLE in TestElements has a bad line 1 checksum.
+		want1 = "1 25544U 98067A   20264.51782528 -.00002182  00000-0 -11606-4 0  2921"
+		want2 = "2 25544  51.6416 247.4627 0006703 130.5360 325.0288 15.72125391563537"
+	)
+
+	if line0 != o.ObjectName {
+		t.Fatal(line0)
+	}
+	if line1 != want1 {
+		t.Fatalf("\n%s\n%s", line1, want1)
+	}
+	if line2 != want2 {
+		t.Fatalf("\n%s\n%s", line2, want2)
+	}
+
+	p, err := NewSGP4TLEFromOMM(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.CatNum != "25544" {
+		t.Fatal(p.CatNum)
+	}
+}
@@ -0,0 +1,197 @@
+package viz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// CZML packets (see
+// https://github.com/AnalyticalGraphicsInc/czml-writer/wiki/Packet).
+type (
+	packet struct {
+		Id           string    `json:"id"`
+		Name         string    `json:"name,omitempty"`
+		Version      string    `json:"version,omitempty"`
+		Description  string    `json:"description,omitempty"`
+		Clock        *clock    `json:"clock,omitempty"`
+		Availability string    `json:"availability,omitempty"`
+		Position     *position `json:"position,omitempty"`
+		Point        *point    `json:"point,omitempty"`
+		Path         *path     `json:"path,omitempty"`
+		Label        *label    `json:"label,omitempty"`
+		Polyline     *polyline `json:"polyline,omitempty"`
+	}
+
+	clock struct {
+		Interval    string  `json:"interval"`
+		CurrentTime string  `json:"currentTime"`
+		Multiplier  float64 `json:"multiplier"`
+	}
+
+	position struct {
+		Epoch                  string    `json:"epoch,omitempty"`
+		InterpolationAlgorithm string    `json:"interpolationAlgorithm,omitempty"`
+		InterpolationDegree    int       `json:"interpolationDegree,omitempty"`
+		Cartesian              []float64 `json:"cartesian"`
+	}
+
+	color struct {
+		RGBA [4]int `json:"rgba"`
+	}
+
+	point struct {
+		Color     color `json:"color"`
+		PixelSize int   `json:"pixelSize"`
+	}
+
+	path struct {
+		Material  material `json:"material"`
+		Width     int      `json:"width"`
+		LeadTime  float64  `json:"leadTime"`
+		TrailTime float64  `json:"trailTime"`
+	}
+
+	material struct {
+		SolidColor struct {
+			Color color `json:"color"`
+		} `json:"solidColor"`
+	}
+
+	label struct {
+		Text  string `json:"text"`
+		Scale int    `json:"scale,omitempty"`
+	}
+
+	polyline struct {
+		Positions position `json:"positions"`
+		Material  material `json:"material"`
+		Width     int      `json:"width"`
+	}
+)
+
+var (
+	objColors = [2]color{
+		{RGBA: [4]int{255, 255, 0, 255}},
+		{RGBA: [4]int{0, 255, 255, 255}},
+	}
+	tcaColor = color{RGBA: [4]int{255, 0, 0, 255}}
+)
+
+func solid(c color) material {
+	var m material
+	m.SolidColor.Color = c
+	return m
+}
+
+func czmlTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func interval(t0, t1 time.Time) string {
+	return czmlTime(t0) + "/" + czmlTime(t1)
+}
+
+// WriteCZML writes a CZML document for the Encounters.
+//
+// Each object has a time-dynamic position over its Encounter's
+// Window, and a polyline connects the objects at the time of
+// closest approach.  Positions are Earth-fixed Cartesian coordinates
+// (meters), which interpolate smoothly across the antimeridian.
+func WriteCZML(w io.Writer, es []*Encounter) error {
+	doc := &packet{
+		Id:      "document",
+		Name:    "SPI conjunctions",
+		Version: "1.0",
+	}
+	packets := []*packet{doc}
+
+	var t0, t1 time.Time
+	for i, e := range es {
+		var (
+			r     = e.Report
+			start = e.Window.Start(r.At)
+			end   = e.Window.End(r.At)
+			avail = interval(start, end)
+			id    = fmt.Sprintf("%d/%s", i, r.Id)
+		)
+		if t0.IsZero() || start.Before(t0) {
+			t0 = start
+		}
+		if end.After(t1) {
+			t1 = end
+		}
+
+		for k, track := range e.Tracks {
+			xyz := make([]float64, 0, 4*len(track))
+			for _, p := range track {
+				xyz = append(xyz,
+					p.T.Sub(start).Seconds(),
+					1000*float64(p.ECEF.X),
+					1000*float64(p.ECEF.Y),
+					1000*float64(p.ECEF.Z))
+			}
+			packets = append(packets, &packet{
+				Id:           fmt.Sprintf("%s/%d", id, k),
+				Name:         r.Objs[k].Name,
+				Description:  e.description(),
+				Availability: avail,
+				Position: &position{
+					Epoch:                  czmlTime(start),
+					InterpolationAlgorithm: "LAGRANGE",
+					InterpolationDegree:    5,
+					Cartesian:              xyz,
+				},
+				Point: &point{
+					Color:     objColors[k],
+					PixelSize: 8,
+				},
+				Path: &path{
+					Material:  solid(objColors[k]),
+					Width:     1,
+					LeadTime:  0,
+					TrailTime: e.Window.Before.Seconds(),
+				},
+				Label: &label{
+					Text: r.Objs[k].Name,
+				},
+			})
+		}
+
+		var xyz []float64
+		for _, s := range r.Objs {
+			pe, err := s.Obj.Prop(r.At)
+			if err != nil {
+				return fmt.Errorf("%s: %s", s.Name, err)
+			}
+			p := ECEF(r.At, pe.ECI)
+			xyz = append(xyz, 1000*float64(p.X), 1000*float64(p.Y), 1000*float64(p.Z))
+		}
+		packets = append(packets, &packet{
+			Id:           id + "/tca",
+			Name:         e.name(),
+			Description:  e.description(),
+			Availability: avail,
+			Polyline: &polyline{
+				Positions: position{
+					Cartesian: xyz,
+				},
+				Material: solid(tcaColor),
+				Width:    3,
+			},
+		})
+	}
+
+	if 0 < len(es) {
+		doc.Clock = &clock{
+			Interval:    interval(t0, t1),
+			CurrentTime: czmlTime(t0),
+			Multiplier:  10,
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(packets)
+}
@@ -0,0 +1,192 @@
+package viz
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/tle"
+)
+
+// ObjectTrack is the track of a TLE.
+type ObjectTrack struct {
+	Name   string
+	TLE    *tle.SGP4TLE
+	Points []Point
+}
+
+// age returns the age (seconds) of the TLE at the given time.
+func (o *ObjectTrack) age(t time.Time) float64 {
+	return o.TLE.ApproxAge(t).Seconds()
+}
+
+// GeoJSON (RFC 7946) objects.
+type (
+	featureCollection struct {
+		Type     string     `json:"type"`
+		Features []*feature `json:"features"`
+	}
+
+	feature struct {
+		Type       string                 `json:"type"`
+		Geometry   *geometry              `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+
+	geometry struct {
+		Type        string      `json:"type"`
+		Coordinates interface{} `json:"coordinates"`
+	}
+)
+
+// SplitAntimeridian splits a ground track into [lon, lat] lines that
+// don't cross the antimeridian.
+//
+// When consecutive positions are more than 180 degrees of longitude
+// apart, the track is assumed to cross the antimeridian, and the
+// lines end and start at the interpolated crossing.
+func SplitAntimeridian(ps []node.LatLonAlt) [][][2]float64 {
+	var (
+		acc  [][][2]float64
+		line [][2]float64
+	)
+	for i, p := range ps {
+		lon, lat := float64(p.Lon), float64(p.Lat)
+		if 0 < i {
+			prev := line[len(line)-1]
+			if d := lon - prev[0]; 180 < math.Abs(d) {
+				// Unwrap the longitude and find the
+				// latitude at +/-180.
+				edge, unwrapped := 180.0, lon+360
+				if 0 < d {
+					edge, unwrapped = -180, lon-360
+				}
+				f := (edge - prev[0]) / (unwrapped - prev[0])
+				crossing := prev[1] + f*(lat-prev[1])
+				line = append(line, [2]float64{edge, crossing})
+				acc = append(acc, line)
+				line = [][2]float64{{-edge, crossing}}
+			}
+		}
+		line = append(line, [2]float64{lon, lat})
+	}
+	if 0 < len(line) {
+		acc = append(acc, line)
+	}
+	return acc
+}
+
+// WriteGeoJSON writes a FeatureCollection with a ground track feature
+// (a LineString, or a MultiLineString if split at the antimeridian)
+// for each ObjectTrack and a Point feature for each sample.
+//
+// Point properties are name, time, altitude (km), age (seconds), and
+// (if known) eclipse, sunlight, beta (deg), and phase (deg).
+func WriteGeoJSON(w io.Writer, ts []*ObjectTrack) error {
+	fc := &featureCollection{
+		Type:     "FeatureCollection",
+		Features: []*feature{},
+	}
+
+	for _, o := range ts {
+		if len(o.Points) == 0 {
+			continue
+		}
+
+		lla := make([]node.LatLonAlt, len(o.Points))
+		for i, p := range o.Points {
+			lla[i] = p.LLA
+		}
+		g := &geometry{
+			Type: "MultiLineString",
+		}
+		if lines := SplitAntimeridian(lla); len(lines) == 1 {
+			g.Type, g.Coordinates = "LineString", lines[0]
+		} else {
+			g.Coordinates = lines
+		}
+		fc.Features = append(fc.Features, &feature{
+			Type:     "Feature",
+			Geometry: g,
+			Properties: map[string]interface{}{
+				"name":   o.Name,
+				"catnum": o.TLE.CatNum,
+				"start":  o.Points[0].T,
+				"end":    o.Points[len(o.Points)-1].T,
+			},
+		})
+
+		for _, p := range o.Points {
+			props := map[string]interface{}{
+				"name":     o.Name,
+				"time":     p.T,
+				"altitude": p.LLA.Alt,
+				"age":      o.age(p.T),
+			}
+			if l := p.Light; l != nil {
+				props["eclipse"] = l.Eclipse
+				props["sunlight"] = l.Sunlight
+				props["beta"] = l.Beta
+				props["phase"] = l.Phase
+			}
+			fc.Features = append(fc.Features, &feature{
+				Type: "Feature",
+				Geometry: &geometry{
+					Type:        "Point",
+					Coordinates: [2]float64{float64(p.LLA.Lon), float64(p.LLA.Lat)},
+				},
+				Properties: props,
+			})
+		}
+	}
+
+	return json.NewEncoder(w).Encode(fc)
+}
+
+// CSVHeader is the first row written by WriteCSV.
+var CSVHeader = []string{"name", "catnum", "time", "lat", "lon", "alt", "age",
+	"eclipse", "sunlight", "beta", "phase"}
+
+// WriteCSV writes a row for each sample.  Altitude is in km, age is in
+// seconds, and angles are in degrees.  The lighting columns are empty
+// when a Point's Light is nil.
+func WriteCSV(w io.Writer, ts []*ObjectTrack, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(CSVHeader); err != nil {
+			return err
+		}
+	}
+	f := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', -1, 32)
+	}
+	for _, o := range ts {
+		for _, p := range o.Points {
+			row := []string{
+				o.Name,
+				o.TLE.CatNum,
+				p.T.UTC().Format(time.RFC3339Nano),
+				f(float64(p.LLA.Lat)),
+				f(float64(p.LLA.Lon)),
+				f(float64(p.LLA.Alt)),
+				strconv.FormatFloat(o.age(p.T), 'f', 0, 64),
+				"", "", "", "",
+			}
+			if l := p.Light; l != nil {
+				row[7] = l.Eclipse
+				row[8] = f(float64(l.Sunlight))
+				row[9] = f(float64(l.Beta))
+				row[10] = f(float64(l.Phase))
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
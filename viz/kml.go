@@ -0,0 +1,184 @@
+package viz
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+)
+
+// KML elements (see https://developers.google.com/kml/documentation).
+type (
+	kml struct {
+		XMLName  xml.Name `xml:"kml"`
+		Xmlns    string   `xml:"xmlns,attr"`
+		Document document `xml:"Document"`
+	}
+
+	document struct {
+		Name    string   `xml:"name"`
+		Styles  []style  `xml:"Style"`
+		Folders []folder `xml:"Folder"`
+	}
+
+	style struct {
+		Id        string     `xml:"id,attr"`
+		IconStyle *iconStyle `xml:"IconStyle,omitempty"`
+		LineStyle *lineStyle `xml:"LineStyle,omitempty"`
+	}
+
+	iconStyle struct {
+		Color string `xml:"color"`
+	}
+
+	lineStyle struct {
+		Color string `xml:"color"`
+		Width int    `xml:"width"`
+	}
+
+	folder struct {
+		Name        string      `xml:"name"`
+		Description string      `xml:"description,omitempty"`
+		Placemarks  []placemark `xml:"Placemark"`
+	}
+
+	placemark struct {
+		Name        string      `xml:"name"`
+		Description string      `xml:"description,omitempty"`
+		TimeStamp   *timeStamp  `xml:"TimeStamp,omitempty"`
+		TimeSpan    *timeSpan   `xml:"TimeSpan,omitempty"`
+		StyleURL    string      `xml:"styleUrl,omitempty"`
+		Point       *kmlPoint   `xml:"Point,omitempty"`
+		LineString  *lineString `xml:"LineString,omitempty"`
+	}
+
+	timeStamp struct {
+		When string `xml:"when"`
+	}
+
+	timeSpan struct {
+		Begin string `xml:"begin"`
+		End   string `xml:"end"`
+	}
+
+	kmlPoint struct {
+		AltitudeMode string `xml:"altitudeMode"`
+		Coordinates  string `xml:"coordinates"`
+	}
+
+	lineString struct {
+		Tessellate   int    `xml:"tessellate,omitempty"`
+		AltitudeMode string `xml:"altitudeMode"`
+		Coordinates  string `xml:"coordinates"`
+	}
+)
+
+// KML colors are aabbggrr.
+var kmlStyles = []style{
+	{Id: "obj0", IconStyle: &iconStyle{Color: "ff00ffff"}, LineStyle: &lineStyle{Color: "ff00ffff", Width: 2}},
+	{Id: "obj1", IconStyle: &iconStyle{Color: "ffffff00"}, LineStyle: &lineStyle{Color: "ffffff00", Width: 2}},
+	{Id: "tca", LineStyle: &lineStyle{Color: "ff0000ff", Width: 3}},
+}
+
+func kmlTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// coordinates formats positions as lon,lat,alt (m) tuples.  If
+// ground, altitudes are zero.
+func coordinates(ps []node.LatLonAlt, ground bool) string {
+	acc := make([]string, len(ps))
+	for i, p := range ps {
+		alt := 1000 * p.Alt
+		if ground {
+			alt = 0
+		}
+		acc[i] = fmt.Sprintf("%f,%f,%.1f", p.Lon, p.Lat, alt)
+	}
+	return strings.Join(acc, " ")
+}
+
+// WriteKML writes a KML document for the Encounters.
+//
+// Each Encounter is a folder with placemarks for the objects at the
+// time of closest approach, a line connecting them, and ground tracks
+// over the Encounter's Window.
+func WriteKML(w io.Writer, es []*Encounter) error {
+	doc := kml{
+		Xmlns: "http://www.opengis.net/kml/2.2",
+		Document: document{
+			Name:   "SPI conjunctions",
+			Styles: kmlStyles,
+		},
+	}
+
+	for _, e := range es {
+		var (
+			r    = e.Report
+			at   = &timeStamp{When: kmlTime(r.At)}
+			span = &timeSpan{
+				Begin: kmlTime(e.Window.Start(r.At)),
+				End:   kmlTime(e.Window.End(r.At)),
+			}
+			f = folder{
+				Name:        e.name(),
+				Description: e.description(),
+			}
+		)
+
+		for k, s := range r.Objs {
+			styleURL := fmt.Sprintf("#obj%d", k)
+			f.Placemarks = append(f.Placemarks, placemark{
+				Name:      s.Name,
+				TimeStamp: at,
+				StyleURL:  styleURL,
+				Point: &kmlPoint{
+					AltitudeMode: "absolute",
+					Coordinates:  coordinates([]node.LatLonAlt{s.LLA}, false),
+				},
+			})
+
+			track := make([]node.LatLonAlt, len(e.Tracks[k]))
+			for i, p := range e.Tracks[k] {
+				track[i] = p.LLA
+			}
+			f.Placemarks = append(f.Placemarks, placemark{
+				Name:     s.Name + " ground track",
+				TimeSpan: span,
+				StyleURL: styleURL,
+				LineString: &lineString{
+					Tessellate:   1,
+					AltitudeMode: "clampToGround",
+					Coordinates:  coordinates(track, true),
+				},
+			})
+		}
+
+		f.Placemarks = append(f.Placemarks, placemark{
+			Name:        "TCA",
+			Description: e.description(),
+			TimeStamp:   at,
+			StyleURL:    "#tca",
+			LineString: &lineString{
+				AltitudeMode: "absolute",
+				Coordinates:  coordinates([]node.LatLonAlt{r.Objs[0].LLA, r.Objs[1].LLA}, false),
+			},
+		})
+
+		doc.Document.Folders = append(doc.Document.Folders, f)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,144 @@
+// Package viz renders node.Reports for 3D visualization as CZML (for
+// Cesium) and KML (for Google Earth).
+//
+// Each report becomes an Encounter with the tracks of both objects
+// over a Window around the report's At.  The tracks come from the
+// reports' TLEs, so compact reports (without TLEs) can't be rendered.
+//
+// ObjectTracks (ground tracks of individual TLEs) can be written as
+// GeoJSON or CSV for GIS tools.
+package viz
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/prop"
+	"github.com/ut-astria/spi/tle"
+)
+
+// ErrNoTLE is returned for a Report that doesn't have both TLEs.
+var ErrNoTLE = errors.New("report doesn't have TLEs")
+
+// Window is the time span of the tracks around a Report's At.
+type Window struct {
+	Before, After time.Duration
+
+	// Step is the sampling interval.
+	Step time.Duration
+}
+
+// DefaultWindow is ten minutes on either side of At with a sample
+// every ten seconds.
+var DefaultWindow = Window{
+	Before: 10 * time.Minute,
+	After:  10 * time.Minute,
+	Step:   10 * time.Second,
+}
+
+// Start returns the start of the window around the given time.
+func (w Window) Start(at time.Time) time.Time {
+	return at.Add(-w.Before)
+}
+
+// End returns the end of the window around the given time.
+func (w Window) End(at time.Time) time.Time {
+	return at.Add(w.After)
+}
+
+// Point is a position on a track.
+type Point struct {
+	T   time.Time
+	LLA node.LatLonAlt
+
+	// ECEF is the Earth-fixed position (km) in the frame of LLA.
+	ECEF prop.Vect
+
+	// Light, if not nil, is the object's illumination.
+	Light *node.Lighting
+}
+
+// Track propagates the TLE over the window around the given time.
+func Track(o *tle.SGP4TLE, at time.Time, w Window) ([]Point, error) {
+	if w.Step <= 0 {
+		return nil, fmt.Errorf("bad step %v", w.Step)
+	}
+	var (
+		end = w.End(at)
+		acc = make([]Point, 0, int((w.Before+w.After)/w.Step)+1)
+	)
+	for t := w.Start(at); !t.After(end); t = t.Add(w.Step) {
+		e, err := o.Prop(t)
+		if err != nil {
+			return nil, err
+		}
+		lla, err := node.ECIToLLA(t, e.ECI)
+		if err != nil {
+			return nil, err
+		}
+		acc = append(acc, Point{
+			T:     t,
+			LLA:   *lla,
+			ECEF:  ECEF(t, e.ECI),
+			Light: node.Light(t, e, node.ConicalShadow),
+		})
+	}
+	return acc, nil
+}
+
+// ECEF rotates an ECI (TEME) position at the given time into the
+// Earth-fixed frame that node.ECIToLLA uses.
+func ECEF(t time.Time, p prop.Vect) prop.Vect {
+	var (
+		gmst, _ = node.TimeToGST(t)
+		s, c    = math.Sincos(gmst)
+		x, y    = float64(p.X), float64(p.Y)
+	)
+	return prop.Vect{
+		X: float32(c*x + s*y),
+		Y: float32(-s*x + c*y),
+		Z: p.Z,
+	}
+}
+
+// Encounter is a Report with the tracks of its objects.
+type Encounter struct {
+	Report *node.Report
+	Window Window
+	Tracks [2][]Point
+}
+
+// NewEncounter computes the tracks for the Report.
+func NewEncounter(r *node.Report, w Window) (*Encounter, error) {
+	if len(r.Objs) != 2 || r.Objs[0].Obj == nil || r.Objs[1].Obj == nil {
+		return nil, ErrNoTLE
+	}
+	e := &Encounter{
+		Report: r,
+		Window: w,
+	}
+	for i := range r.Objs {
+		ps, err := Track(r.Objs[i].Obj, r.At, w)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", r.Objs[i].Name, err)
+		}
+		e.Tracks[i] = ps
+	}
+	return e, nil
+}
+
+// name returns a short name for the Encounter.
+func (e *Encounter) name() string {
+	r := e.Report
+	return fmt.Sprintf("%s / %s at %s", r.Objs[0].Name, r.Objs[1].Name, r.At.Format(time.RFC3339))
+}
+
+// description summarizes the Report.
+func (e *Encounter) description() string {
+	r := e.Report
+	return fmt.Sprintf("Dist %.3f km, speed %.3f km/s at %s (report %s)",
+		r.Dist, r.Speed, r.At.Format(time.RFC3339Nano), r.Id)
+}
@@ -0,0 +1,296 @@
+package viz
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ut-astria/spi/node"
+	"github.com/ut-astria/spi/tle"
+)
+
+var testWindow = Window{
+	Before: time.Minute,
+	After:  2 * time.Minute,
+	Step:   10 * time.Second,
+}
+
+func testEncounter(t *testing.T) *Encounter {
+	return testEncounterAt(t, time.Date(2020, 9, 20, 13, 0, 0, 0, time.UTC))
+}
+
+func testEncounterAt(t *testing.T, at time.Time) *Encounter {
+	var (
+		lines = [][3]string{
+			{"0 ISS (ZARYA)",
+				"1 25544U 98067A   20264.51782528 -.00002182  00000-0 -11606-4 0  2927",
+				"2 25544  51.6416 247.4627 0006703 130.5360 325.0288 15.72125391563537"},
+			{"0 FOO DEB",
+				"1 25545U 98067B   20264.51782528 -.00002182  00000-0 -11606-4 0  2927",
+				"2 25545  51.6416 247.4627 0006703 130.5360 325.0588 15.72125391563537"},
+		}
+		r = &node.Report{
+			Id: "id",
+			At: at,
+		}
+	)
+
+	for _, ls := range lines {
+		p, err := tle.NewSGP4TLE(ls[0], ls[1], ls[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := p.(*tle.SGP4TLE)
+		e, err := o.Prop(at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lla, err := node.ECIToLLA(at, e.ECI)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Objs = append(r.Objs, node.State{
+			Name: o.CatNum,
+			Obj:  o,
+			ECI:  e.ECI,
+			Vel:  e.V,
+			LLA:  *lla,
+		})
+	}
+
+	e, err := NewEncounter(r, testWindow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestTrack(t *testing.T) {
+	e := testEncounter(t)
+	for _, track := range e.Tracks {
+		if len(track) != 19 {
+			t.Fatalf("%d points", len(track))
+		}
+		if p := track[6]; !p.T.Equal(e.Report.At) {
+			t.Fatalf("point 6 at %s", p.T)
+		}
+	}
+
+	if p, s := e.Tracks[0][6].LLA, e.Report.Objs[0].LLA; p != s {
+		t.Fatalf("%v != %v", p, s)
+	}
+}
+
+func TestNoTLE(t *testing.T) {
+	r := testEncounter(t).Report
+	r.Objs[1].Obj = nil
+	if _, err := NewEncounter(r, testWindow); err != ErrNoTLE {
+		t.Fatal(err)
+	}
+}
+
+func TestCZML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCZML(&buf, []*Encounter{testEncounter(t)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var ps []packet
+	if err := json.Unmarshal(buf.Bytes(), &ps); err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 4 {
+		t.Fatalf("%d packets", len(ps))
+	}
+	if ps[0].Id != "document" || ps[0].Clock == nil {
+		t.Fatalf("document %#v", ps[0])
+	}
+	if want := "2020-09-20T12:59:00Z/2020-09-20T13:02:00Z"; ps[0].Clock.Interval != want {
+		t.Fatalf("clock interval %s", ps[0].Clock.Interval)
+	}
+	for _, p := range ps[1:3] {
+		if p.Position == nil || len(p.Position.Cartesian) != 4*19 {
+			t.Fatalf("object %#v", p)
+		}
+	}
+	if p := ps[3]; p.Polyline == nil || len(p.Polyline.Positions.Cartesian) != 6 {
+		t.Fatalf("polyline %#v", p)
+	}
+
+	// The ECEF positions agree with the geodetic ones.
+	for _, p := range testEncounter(t).Tracks[0] {
+		var (
+			x, y, z = float64(p.ECEF.X), float64(p.ECEF.Y), float64(p.ECEF.Z)
+			lon     = math.Atan2(y, x) * 180 / math.Pi
+			lat     = math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi
+		)
+		if d := math.Abs(math.Remainder(lon-float64(p.LLA.Lon), 360)); 0.01 < d {
+			t.Fatalf("lon %f != %f", lon, p.LLA.Lon)
+		}
+		// Geocentric and geodetic latitudes differ a little.
+		if d := math.Abs(lat - float64(p.LLA.Lat)); 0.5 < d {
+			t.Fatalf("lat %f != %f", lat, p.LLA.Lat)
+		}
+	}
+}
+
+func TestCZMLAntimeridian(t *testing.T) {
+	// Find an encounter whose tracks cross the antimeridian.
+	var (
+		e       *Encounter
+		t0      = time.Date(2020, 9, 20, 13, 0, 0, 0, time.UTC)
+		crosses = func(ps []Point) bool {
+			for i := 1; i < len(ps); i++ {
+				if 180 < math.Abs(float64(ps[i].LLA.Lon-ps[i-1].LLA.Lon)) {
+					return true
+				}
+			}
+			return false
+		}
+	)
+	for at := t0; at.Before(t0.Add(2 * time.Hour)); at = at.Add(time.Minute) {
+		if e = testEncounterAt(t, at); crosses(e.Tracks[0]) {
+			break
+		}
+		e = nil
+	}
+	if e == nil {
+		t.Fatal("no crossing")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCZML(&buf, []*Encounter{e}); err != nil {
+		t.Fatal(err)
+	}
+	var ps []packet
+	if err := json.Unmarshal(buf.Bytes(), &ps); err != nil {
+		t.Fatal(err)
+	}
+
+	// Successive samples (10 s apart) are close even across the
+	// antimeridian: about 8 km/s.
+	xyz := ps[1].Position.Cartesian
+	for i := 4; i < len(xyz); i += 4 {
+		var (
+			dx = xyz[i+1] - xyz[i-3]
+			dy = xyz[i+2] - xyz[i-2]
+			dz = xyz[i+3] - xyz[i-1]
+		)
+		if d := math.Sqrt(dx*dx + dy*dy + dz*dz); 100e3 < d {
+			t.Fatalf("sample %d jumps %f m", i/4, d)
+		}
+	}
+}
+
+func TestKML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteKML(&buf, []*Encounter{testEncounter(t)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc kml
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Document.Folders) != 1 {
+		t.Fatalf("%d folders", len(doc.Document.Folders))
+	}
+	ps := doc.Document.Folders[0].Placemarks
+	if len(ps) != 5 {
+		t.Fatalf("%d placemarks", len(ps))
+	}
+	track := ps[1]
+	if track.LineString == nil || len(strings.Fields(track.LineString.Coordinates)) != 19 {
+		t.Fatalf("ground track %#v", track)
+	}
+	if tca := ps[4]; tca.LineString == nil || len(strings.Fields(tca.LineString.Coordinates)) != 2 {
+		t.Fatalf("TCA %#v", tca)
+	}
+}
+
+func TestSplitAntimeridian(t *testing.T) {
+	lines := SplitAntimeridian([]node.LatLonAlt{
+		{Lat: 0, Lon: 160},
+		{Lat: 10, Lon: 170},
+		{Lat: 20, Lon: -170},
+		{Lat: 30, Lon: -160},
+		{Lat: 20, Lon: 160},
+	})
+	want := [][][2]float64{
+		{{160, 0}, {170, 10}, {180, 15}},
+		{{-180, 15}, {-170, 20}, {-160, 30}, {-180, 25}},
+		{{180, 25}, {160, 20}},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("%v", lines)
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	var (
+		e  = testEncounter(t)
+		ts = []*ObjectTrack{
+			{Name: "a", TLE: e.Report.Objs[0].Obj, Points: e.Tracks[0]},
+			{Name: "b", TLE: e.Report.Objs[1].Obj, Points: e.Tracks[1]},
+		}
+		buf bytes.Buffer
+	)
+	if err := WriteGeoJSON(&buf, ts); err != nil {
+		t.Fatal(err)
+	}
+
+	var fc struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type string
+			}
+			Properties map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 2*(1+19) {
+		t.Fatalf("%s with %d features", fc.Type, len(fc.Features))
+	}
+	if g := fc.Features[0].Geometry.Type; g != "LineString" && g != "MultiLineString" {
+		t.Fatalf("track geometry %s", g)
+	}
+	p := fc.Features[1]
+	if p.Geometry.Type != "Point" {
+		t.Fatalf("sample geometry %s", p.Geometry.Type)
+	}
+	for _, k := range []string{"time", "altitude", "age", "eclipse", "beta"} {
+		if _, have := p.Properties[k]; !have {
+			t.Fatalf("no %s", k)
+		}
+	}
+}
+
+func TestCSV(t *testing.T) {
+	var (
+		e   = testEncounter(t)
+		ts  = []*ObjectTrack{{Name: "a", TLE: e.Report.Objs[0].Obj, Points: e.Tracks[0]}}
+		buf bytes.Buffer
+	)
+	if err := WriteCSV(&buf, ts, true); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1+19 || !reflect.DeepEqual(rows[0], CSVHeader) {
+		t.Fatalf("%d rows starting with %v", len(rows), rows[0])
+	}
+	if row := rows[7]; row[2] != "2020-09-20T13:00:00Z" || row[6] != "2060" || row[7] == "" {
+		t.Fatalf("row %v", row)
+	}
+}